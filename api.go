@@ -0,0 +1,152 @@
+package bookstack
+
+import (
+	"context"
+	"iter"
+)
+
+// The interfaces below describe the operations of each service. Code that
+// depends on the library can accept these instead of the concrete service
+// types to allow substituting fakes in tests; see the bookstackmock package.
+
+// BooksAPI is implemented by *BooksService.
+type BooksAPI interface {
+	List(ctx context.Context, opts *ListOptions) ([]Book, error)
+	ListAll(ctx context.Context) iter.Seq2[Book, error]
+	ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Book, error]
+	Get(ctx context.Context, id int) (*Book, error)
+	GetBySlug(ctx context.Context, slug string) (*Book, error)
+	Create(ctx context.Context, req *BookCreateRequest) (*Book, error)
+	Update(ctx context.Context, id int, req *BookUpdateRequest) (*Book, error)
+	Delete(ctx context.Context, id int) error
+	PlanReorder(ctx context.Context, bookID int, layout []LayoutItem) ([]ReorderChange, error)
+	Reorder(ctx context.Context, bookID int, layout []LayoutItem) ([]ReorderChange, error)
+}
+
+// ChaptersAPI is implemented by *ChaptersService.
+type ChaptersAPI interface {
+	List(ctx context.Context, opts *ListOptions) ([]Chapter, error)
+	ListAll(ctx context.Context) iter.Seq2[Chapter, error]
+	ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Chapter, error]
+	Get(ctx context.Context, id int) (*Chapter, error)
+	GetBySlug(ctx context.Context, bookSlug, slug string) (*Chapter, error)
+	Create(ctx context.Context, req *ChapterCreateRequest) (*Chapter, error)
+	Update(ctx context.Context, id int, req *ChapterUpdateRequest) (*Chapter, error)
+	Delete(ctx context.Context, id int) error
+	Move(ctx context.Context, id int, target MoveTarget) (*Chapter, error)
+	Copy(ctx context.Context, id int, target MoveTarget) (*Chapter, error)
+}
+
+// PagesAPI is implemented by *PagesService.
+type PagesAPI interface {
+	List(ctx context.Context, opts *ListOptions) ([]Page, error)
+	ListAll(ctx context.Context) iter.Seq2[Page, error]
+	ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Page, error]
+	Get(ctx context.Context, id int) (*Page, error)
+	GetBySlug(ctx context.Context, bookSlug, slug string) (*Page, error)
+	Create(ctx context.Context, req *PageCreateRequest) (*Page, error)
+	Update(ctx context.Context, id int, req *PageUpdateRequest) (*Page, error)
+	UpdateIfUnchanged(ctx context.Context, seen *Page, req *PageUpdateRequest) (*Page, error)
+	Delete(ctx context.Context, id int) error
+	Move(ctx context.Context, id int, target MoveTarget) (*Page, error)
+	Copy(ctx context.Context, id int, target MoveTarget) (*Page, error)
+	ExportMarkdown(ctx context.Context, id int) ([]byte, error)
+	ExportPDF(ctx context.Context, id int) ([]byte, error)
+}
+
+// ShelvesAPI is implemented by *ShelvesService.
+type ShelvesAPI interface {
+	List(ctx context.Context, opts *ListOptions) ([]Shelf, error)
+	ListAll(ctx context.Context) iter.Seq2[Shelf, error]
+	ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Shelf, error]
+	Get(ctx context.Context, id int) (*Shelf, error)
+	GetBySlug(ctx context.Context, slug string) (*Shelf, error)
+	Create(ctx context.Context, req *ShelfCreateRequest) (*Shelf, error)
+	Update(ctx context.Context, id int, req *ShelfUpdateRequest) (*Shelf, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// RevisionsAPI is implemented by *RevisionsService.
+type RevisionsAPI interface {
+	List(ctx context.Context, pageID int, opts *ListOptions) ([]Revision, error)
+	ListAll(ctx context.Context, pageID int) iter.Seq2[Revision, error]
+	Get(ctx context.Context, pageID, revisionID int) (*Revision, error)
+	Diff(ctx context.Context, pageID, fromID, toID int, format DiffFormat) (*Diff, error)
+}
+
+// SearchAPI is implemented by *SearchService.
+type SearchAPI interface {
+	Search(ctx context.Context, query string, opts *ListOptions) ([]SearchResult, error)
+	SearchPage(ctx context.Context, query string, page, count int) (*SearchResponse, error)
+	SearchAll(ctx context.Context, query string) iter.Seq2[SearchResult, error]
+}
+
+// AttachmentsAPI is implemented by *AttachmentsService.
+type AttachmentsAPI interface {
+	List(ctx context.Context, opts *ListOptions) ([]Attachment, error)
+	ListAll(ctx context.Context) iter.Seq2[Attachment, error]
+	ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Attachment, error]
+	Get(ctx context.Context, id int) (*Attachment, error)
+	Create(ctx context.Context, req *AttachmentCreateRequest) (*Attachment, error)
+	Upload(ctx context.Context, req *AttachmentUploadRequest) (*Attachment, error)
+	Update(ctx context.Context, id int, req *AttachmentUpdateRequest) (*Attachment, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// CommentsAPI is implemented by *CommentsService.
+type CommentsAPI interface {
+	List(ctx context.Context, opts *ListOptions) ([]Comment, error)
+	ListAll(ctx context.Context) iter.Seq2[Comment, error]
+	ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Comment, error]
+	Get(ctx context.Context, id int) (*Comment, error)
+	Create(ctx context.Context, req *CommentCreateRequest) (*Comment, error)
+	Update(ctx context.Context, id int, req *CommentUpdateRequest) (*Comment, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// ImagesAPI is implemented by *ImagesService.
+type ImagesAPI interface {
+	List(ctx context.Context, opts *ListOptions) ([]Image, error)
+	ListAll(ctx context.Context) iter.Seq2[Image, error]
+	ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Image, error]
+	Get(ctx context.Context, id int) (*Image, error)
+	Create(ctx context.Context, req *ImageUploadRequest) (*Image, error)
+	Update(ctx context.Context, id int, req *ImageUpdateRequest) (*Image, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// DraftsAPI is implemented by *DraftsService.
+type DraftsAPI interface {
+	List(ctx context.Context, opts *ListOptions) ([]Page, error)
+	ListAll(ctx context.Context) iter.Seq2[Page, error]
+}
+
+// TemplatesAPI is implemented by *TemplatesService.
+type TemplatesAPI interface {
+	List(ctx context.Context, opts *ListOptions) ([]Page, error)
+	ListAll(ctx context.Context) iter.Seq2[Page, error]
+	Mark(ctx context.Context, pageID int) (*Page, error)
+	Unmark(ctx context.Context, pageID int) (*Page, error)
+	Instantiate(ctx context.Context, templateID int, req *TemplateRequest) (*Page, error)
+}
+
+// PermissionsAPI is implemented by *PermissionsService.
+type PermissionsAPI interface {
+	Get(ctx context.Context, contentType string, id int) (*ContentPermissions, error)
+	Update(ctx context.Context, contentType string, id int, req *ContentPermissionsUpdateRequest) (*ContentPermissions, error)
+}
+
+var (
+	_ BooksAPI       = (*BooksService)(nil)
+	_ ChaptersAPI    = (*ChaptersService)(nil)
+	_ PagesAPI       = (*PagesService)(nil)
+	_ ShelvesAPI     = (*ShelvesService)(nil)
+	_ RevisionsAPI   = (*RevisionsService)(nil)
+	_ SearchAPI      = (*SearchService)(nil)
+	_ AttachmentsAPI = (*AttachmentsService)(nil)
+	_ CommentsAPI    = (*CommentsService)(nil)
+	_ ImagesAPI      = (*ImagesService)(nil)
+	_ PermissionsAPI = (*PermissionsService)(nil)
+	_ TemplatesAPI   = (*TemplatesService)(nil)
+	_ DraftsAPI      = (*DraftsService)(nil)
+)
@@ -3,6 +3,8 @@ package bookstack
 import (
 	"context"
 	"fmt"
+	"iter"
+	"strconv"
 )
 
 // AttachmentsService handles operations on attachments.
@@ -20,6 +22,16 @@ func (s *AttachmentsService) List(ctx context.Context, opts *ListOptions) ([]Att
 	return resp.Data, nil
 }
 
+// ListAll returns an iterator over all attachments, handling pagination automatically.
+func (s *AttachmentsService) ListAll(ctx context.Context) iter.Seq2[Attachment, error] {
+	return listAll[Attachment](ctx, s.client, "/api/attachments", nil)
+}
+
+// ListAllWith returns an iterator over all attachments matching opts, handling pagination automatically.
+func (s *AttachmentsService) ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Attachment, error] {
+	return listAll[Attachment](ctx, s.client, "/api/attachments", opts)
+}
+
 // Get retrieves a single attachment by ID.
 func (s *AttachmentsService) Get(ctx context.Context, id int) (*Attachment, error) {
 	var a Attachment
@@ -40,6 +52,20 @@ func (s *AttachmentsService) Create(ctx context.Context, req *AttachmentCreateRe
 	return &a, nil
 }
 
+// Upload uploads a file attachment.
+func (s *AttachmentsService) Upload(ctx context.Context, req *AttachmentUploadRequest) (*Attachment, error) {
+	var a Attachment
+	fields := map[string]string{
+		"name":        req.Name,
+		"uploaded_to": strconv.Itoa(req.UploadedTo),
+	}
+	err := s.client.doMultipart(ctx, "POST", "/api/attachments", fields, "file", req.Filename, req.Data, &a)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
 // Update updates an existing attachment.
 func (s *AttachmentsService) Update(ctx context.Context, id int, req *AttachmentUpdateRequest) (*Attachment, error) {
 	var a Attachment
@@ -119,3 +119,39 @@ func TestAttachmentsService_Get_NotFound(t *testing.T) {
 		t.Errorf("expected ErrNotFound, got %v", err)
 	}
 }
+
+func TestAttachmentsService_Upload(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm: %v", err)
+		}
+		if r.FormValue("name") != "Config" || r.FormValue("uploaded_to") != "5" {
+			t.Errorf("form = %v", r.MultipartForm.Value)
+		}
+		f, hdr, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("FormFile: %v", err)
+		}
+		defer f.Close()
+		if hdr.Filename != "config.yaml" {
+			t.Errorf("filename = %q", hdr.Filename)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 3, "name": "Config", "extension": "yaml"})
+	})
+
+	a, err := c.Attachments.Upload(context.Background(), &AttachmentUploadRequest{
+		Name:       "Config",
+		UploadedTo: 5,
+		Filename:   "config.yaml",
+		Data:       []byte("key: value"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.ID != 3 || a.Extension != "yaml" {
+		t.Errorf("got %+v", a)
+	}
+}
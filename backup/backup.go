@@ -0,0 +1,418 @@
+// Package backup writes a Bookstack instance to a portable archive and
+// restores it, using only the REST API.
+//
+// An archive is a zip file with this layout:
+//
+//	manifest.json          format name, version, source URL and item counts
+//	shelves.jsonl          one JSON object per line, see the record types
+//	books.jsonl
+//	chapters.jsonl
+//	pages.jsonl            including both HTML and Markdown
+//	attachments.jsonl
+//	images.jsonl
+//	comments.jsonl
+//	files/attachments/<id> uploaded attachment files
+//	files/images/<id>      gallery images
+//
+// Shelves, books, chapters and pages carry their tags and item-level
+// permissions. Revisions, users, roles and drafts are not included.
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+// Format identifies backup archives in the manifest.
+const Format = "bookstack-backup"
+
+// Version is the archive format version written by Write. Restore reads
+// archives up to this version.
+const Version = 1
+
+// Archive entry names.
+const (
+	manifestFile    = "manifest.json"
+	shelvesFile     = "shelves.jsonl"
+	booksFile       = "books.jsonl"
+	chaptersFile    = "chapters.jsonl"
+	pagesFile       = "pages.jsonl"
+	attachmentsFile = "attachments.jsonl"
+	imagesFile      = "images.jsonl"
+	commentsFile    = "comments.jsonl"
+	attachmentsDir  = "files/attachments/"
+	imagesDir       = "files/images/"
+)
+
+// ErrUnsupportedArchive is returned by Restore for files that are not
+// backup archives or were written by a newer format version.
+var ErrUnsupportedArchive = errors.New("backup: unsupported archive")
+
+// Manifest describes an archive.
+type Manifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Source    string    `json:"source"` // Base URL of the backed-up instance
+
+	// Permissions reports whether item-level permissions were captured.
+	// They are skipped when the API token may not manage permissions.
+	Permissions bool `json:"permissions"`
+
+	Counts map[string]int `json:"counts"` // Items per entry, keyed by entry name
+}
+
+// ShelfRecord is a line of shelves.jsonl.
+type ShelfRecord struct {
+	bookstack.Shelf
+	BookIDs     []int                         `json:"book_ids"`
+	Permissions *bookstack.ContentPermissions `json:"permissions,omitempty"`
+}
+
+// BookRecord is a line of books.jsonl.
+type BookRecord struct {
+	bookstack.Book
+	Permissions *bookstack.ContentPermissions `json:"permissions,omitempty"`
+}
+
+// ChapterRecord is a line of chapters.jsonl.
+type ChapterRecord struct {
+	bookstack.Chapter
+	Permissions *bookstack.ContentPermissions `json:"permissions,omitempty"`
+}
+
+// PageRecord is a line of pages.jsonl.
+type PageRecord struct {
+	bookstack.Page
+	Permissions *bookstack.ContentPermissions `json:"permissions,omitempty"`
+}
+
+// Scope limits a backup to part of an instance. The zero Scope covers
+// everything.
+type Scope struct {
+	ShelfIDs []int // Shelves, along with the books on them
+	BookIDs  []int // Books not necessarily on a selected shelf
+}
+
+func (s Scope) all() bool { return len(s.ShelfIDs) == 0 && len(s.BookIDs) == 0 }
+
+// Write backs up every shelf, book, chapter, page, attachment, gallery
+// image and comment visible to the client's token to w as a zip archive.
+func Write(ctx context.Context, c *bookstack.Client, w io.Writer) (*Manifest, error) {
+	return WriteScope(ctx, c, w, Scope{})
+}
+
+// WriteScope is like Write but only includes the items in scope: the
+// selected shelves and books, and the chapters, pages, attachments,
+// images and comments within those books.
+func WriteScope(ctx context.Context, c *bookstack.Client, w io.Writer, scope Scope) (*Manifest, error) {
+	scope.BookIDs = slices.Clone(scope.BookIDs) // Books on selected shelves are added
+	b := &writer{
+		ctx:         ctx,
+		c:           c,
+		zw:          zip.NewWriter(w),
+		scope:       scope,
+		books:       make(map[int]bool),
+		pages:       make(map[int]bool),
+		permissions: true,
+		manifest: &Manifest{
+			Format:    Format,
+			Version:   Version,
+			CreatedAt: time.Now().UTC(),
+			Source:    c.BaseURL(),
+			Counts:    make(map[string]int),
+		},
+	}
+	steps := []func() error{b.writeShelves, b.writeBooks, b.writeChapters, b.writePages, b.writeAttachments, b.writeImages, b.writeComments}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, err
+		}
+	}
+
+	b.manifest.Permissions = b.permissions
+	if err := b.writeJSON(manifestFile, b.manifest); err != nil {
+		return nil, err
+	}
+	if err := b.zw.Close(); err != nil {
+		return nil, fmt.Errorf("closing archive: %w", err)
+	}
+	return b.manifest, nil
+}
+
+// writer holds the state of one Write call.
+type writer struct {
+	ctx         context.Context
+	c           *bookstack.Client
+	zw          *zip.Writer
+	manifest    *Manifest
+	scope       Scope
+	books       map[int]bool // IDs of the books written
+	pages       map[int]bool // IDs of the pages written
+	permissions bool         // Cleared when the token may not read permissions
+}
+
+// lines writes the items of seq to a JSON Lines entry, converting each
+// with record.
+func lines[T any](b *writer, name string, seq iter.Seq2[T, error], record func(T) (any, error)) error {
+	f, err := b.zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", name, err)
+	}
+	enc := json.NewEncoder(f)
+	for item, err := range seq {
+		if err != nil {
+			return err
+		}
+		rec, err := record(item)
+		if err != nil {
+			return err
+		}
+		if rec == nil {
+			continue
+		}
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		b.manifest.Counts[name]++
+	}
+	return nil
+}
+
+func (b *writer) writeJSON(name string, v any) error {
+	f, err := b.zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", name, err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (b *writer) writeFile(name string, data []byte) error {
+	f, err := b.zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", name, err)
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// itemPermissions fetches the permissions of an item. After the first
+// ErrForbidden, permissions are no longer requested.
+func (b *writer) itemPermissions(contentType string, id int) (*bookstack.ContentPermissions, error) {
+	if !b.permissions {
+		return nil, nil
+	}
+	p, err := b.c.Permissions.Get(b.ctx, contentType, id)
+	if errors.Is(err, bookstack.ErrForbidden) {
+		b.permissions = false
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting permissions of %s %d: %w", contentType, id, err)
+	}
+	return p, nil
+}
+
+func (b *writer) writeShelves() error {
+	shelves := b.c.Shelves.ListAll(b.ctx)
+	if !b.scope.all() {
+		shelves = idSeq(b.scope.ShelfIDs, func(id int) bookstack.Shelf { return bookstack.Shelf{ID: id} })
+	}
+	return lines(b, shelvesFile, shelves, func(s bookstack.Shelf) (any, error) {
+		shelf, err := b.c.Shelves.Get(b.ctx, s.ID)
+		if err != nil {
+			return nil, fmt.Errorf("getting shelf %d: %w", s.ID, err)
+		}
+		rec := &ShelfRecord{Shelf: *shelf, BookIDs: []int{}}
+		for _, book := range shelf.Books {
+			rec.BookIDs = append(rec.BookIDs, book.ID)
+			if !b.scope.all() && !slices.Contains(b.scope.BookIDs, book.ID) {
+				b.scope.BookIDs = append(b.scope.BookIDs, book.ID)
+			}
+		}
+		rec.Books = nil
+		rec.Permissions, err = b.itemPermissions(bookstack.EntityShelf, s.ID)
+		return rec, err
+	})
+}
+
+func (b *writer) writeBooks() error {
+	books := b.c.Books.ListAll(b.ctx)
+	if !b.scope.all() {
+		books = idSeq(b.scope.BookIDs, func(id int) bookstack.Book { return bookstack.Book{ID: id} })
+	}
+	return lines(b, booksFile, books, func(bk bookstack.Book) (any, error) {
+		book, err := b.c.Books.Get(b.ctx, bk.ID)
+		if err != nil {
+			return nil, fmt.Errorf("getting book %d: %w", bk.ID, err)
+		}
+		b.books[book.ID] = true
+		rec := &BookRecord{Book: *book}
+		rec.Permissions, err = b.itemPermissions(bookstack.EntityBook, book.ID)
+		return rec, err
+	})
+}
+
+// inBooks returns the items of list within the books written, listing
+// per book when the backup is scoped.
+func inBooks[T any](b *writer, list func(opts *bookstack.ListOptions) iter.Seq2[T, error]) iter.Seq2[T, error] {
+	return inParents(b, "book_id", b.books, list)
+}
+
+// inPages returns the items of list belonging to the pages written, whose
+// page ID is in the field key, listing per page when the backup is scoped.
+func inPages[T any](b *writer, key string, list func(opts *bookstack.ListOptions) iter.Seq2[T, error]) iter.Seq2[T, error] {
+	return inParents(b, key, b.pages, list)
+}
+
+func inParents[T any](b *writer, key string, ids map[int]bool, list func(opts *bookstack.ListOptions) iter.Seq2[T, error]) iter.Seq2[T, error] {
+	if b.scope.all() {
+		return list(nil)
+	}
+	return func(yield func(T, error) bool) {
+		for _, id := range slices.Sorted(maps.Keys(ids)) {
+			opts := &bookstack.ListOptions{Filter: map[string]string{key: strconv.Itoa(id)}}
+			for item, err := range list(opts) {
+				if !yield(item, err) || err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (b *writer) writeChapters() error {
+	chapters := inBooks(b, func(opts *bookstack.ListOptions) iter.Seq2[bookstack.Chapter, error] {
+		return b.c.Chapters.ListAllWith(b.ctx, opts)
+	})
+	return lines(b, chaptersFile, chapters, func(ch bookstack.Chapter) (any, error) {
+		chapter, err := b.c.Chapters.Get(b.ctx, ch.ID)
+		if err != nil {
+			return nil, fmt.Errorf("getting chapter %d: %w", ch.ID, err)
+		}
+		rec := &ChapterRecord{Chapter: *chapter}
+		rec.Permissions, err = b.itemPermissions(bookstack.EntityChapter, chapter.ID)
+		return rec, err
+	})
+}
+
+func (b *writer) writePages() error {
+	pages := inBooks(b, func(opts *bookstack.ListOptions) iter.Seq2[bookstack.Page, error] {
+		return b.c.Pages.ListAllWith(b.ctx, opts)
+	})
+	return lines(b, pagesFile, pages, func(p bookstack.Page) (any, error) {
+		if p.Draft {
+			return nil, nil
+		}
+		page, err := b.c.Pages.Get(b.ctx, p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("getting page %d: %w", p.ID, err)
+		}
+		b.pages[page.ID] = true
+		rec := &PageRecord{Page: *page}
+		rec.Permissions, err = b.itemPermissions(bookstack.EntityPage, page.ID)
+		return rec, err
+	})
+}
+
+// writeAttachments stores uploaded files under files/attachments and writes
+// their metadata afterwards, as zip entries cannot be interleaved.
+func (b *writer) writeAttachments() error {
+	var recs []bookstack.Attachment
+	attachments := inPages(b, "uploaded_to", func(opts *bookstack.ListOptions) iter.Seq2[bookstack.Attachment, error] {
+		return b.c.Attachments.ListAllWith(b.ctx, opts)
+	})
+	for a, err := range attachments {
+		if err != nil {
+			return err
+		}
+		full, err := b.c.Attachments.Get(b.ctx, a.ID)
+		if err != nil {
+			return fmt.Errorf("getting attachment %d: %w", a.ID, err)
+		}
+		if !full.External {
+			data, err := base64.StdEncoding.DecodeString(full.Content)
+			if err != nil {
+				return fmt.Errorf("decoding attachment %d: %w", a.ID, err)
+			}
+			if err := b.writeFile(attachmentsDir+strconv.Itoa(a.ID), data); err != nil {
+				return err
+			}
+			full.Content = ""
+		}
+		recs = append(recs, *full)
+	}
+	return lines(b, attachmentsFile, sliceSeq(recs), identity)
+}
+
+// writeImages stores gallery images under files/images, then their metadata.
+func (b *writer) writeImages() error {
+	var recs []bookstack.Image
+	images := inPages(b, "uploaded_to", func(opts *bookstack.ListOptions) iter.Seq2[bookstack.Image, error] {
+		return b.c.Images.ListAllWith(b.ctx, opts)
+	})
+	for img, err := range images {
+		if err != nil {
+			return err
+		}
+		data, err := b.c.Download(b.ctx, img.URL)
+		if err != nil {
+			return fmt.Errorf("downloading image %d: %w", img.ID, err)
+		}
+		if err := b.writeFile(imagesDir+strconv.Itoa(img.ID), data); err != nil {
+			return err
+		}
+		recs = append(recs, img)
+	}
+	return lines(b, imagesFile, sliceSeq(recs), identity)
+}
+
+func (b *writer) writeComments() error {
+	comments := inPages(b, "page_id", func(opts *bookstack.ListOptions) iter.Seq2[bookstack.Comment, error] {
+		return b.c.Comments.ListAllWith(b.ctx, opts)
+	})
+	return lines(b, commentsFile, comments, func(cm bookstack.Comment) (any, error) {
+		full, err := b.c.Comments.Get(b.ctx, cm.ID)
+		if err != nil {
+			return nil, fmt.Errorf("getting comment %d: %w", cm.ID, err)
+		}
+		return full, nil
+	})
+}
+
+func sliceSeq[T any](items []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// idSeq yields a stub item for each ID; the full item is fetched by ID.
+func idSeq[T any](ids []int, stub func(int) T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, id := range ids {
+			if !yield(stub(id), nil) {
+				return
+			}
+		}
+	}
+}
+
+func identity[T any](item T) (any, error) { return item, nil }
@@ -0,0 +1,220 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/bookstacktest"
+)
+
+func seedSource(t *testing.T) *bookstacktest.Server {
+	t.Helper()
+	src := bookstacktest.NewServer()
+	t.Cleanup(src.Close)
+
+	// Padding so that IDs differ between source and target.
+	src.AddBook(bookstack.Book{Name: "Scratch"})
+	book := src.AddBook(bookstack.Book{Name: "Operations", Description: "Ops docs", Tags: []bookstack.Tag{{Name: "team", Value: "platform"}}})
+	ch := src.AddChapter(bookstack.Chapter{BookID: book.ID, Name: "Runbooks", Priority: 3})
+	deploy := src.AddPage(bookstack.Page{ChapterID: ch.ID, Name: "Deploy", Markdown: "# Deploy"})
+	img := src.AddImage(bookstack.Image{Name: "arch.png", UploadedTo: deploy.ID}, []byte("PNG"))
+	att := src.AddAttachment(bookstack.Attachment{
+		Name: "config", Extension: "yaml", UploadedTo: deploy.ID,
+		Content: base64.StdEncoding.EncodeToString([]byte("key: value")),
+	})
+	src.AddAttachment(bookstack.Attachment{Name: "Dashboard", UploadedTo: deploy.ID, External: true, Content: "https://grafana"})
+	src.AddPage(bookstack.Page{BookID: book.ID, Name: "Overview", Template: true, HTML: `<p>See <a href="` + src.URL + `/link/` + strconv.Itoa(deploy.ID) + `">deploy</a>.</p>`})
+
+	c := src.Client()
+	ctx := context.Background()
+	if _, err := c.Pages.Update(ctx, deploy.ID, &bookstack.PageUpdateRequest{
+		Markdown: "# Deploy\n\n![arch](" + img.URL + ")\n\n[config](" + src.URL + "/attachments/" + strconv.Itoa(att.ID) + ")",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	parent := src.AddComment(bookstack.Comment{PageID: deploy.ID, HTML: "<p>Question</p>"})
+	src.AddComment(bookstack.Comment{PageID: deploy.ID, ParentID: parent.ID, HTML: "<p>Answer</p>"})
+	src.AddShelf(bookstack.Shelf{Name: "Team"}, book.ID)
+	if _, err := c.Permissions.Update(ctx, bookstack.EntityBook, book.ID, &bookstack.ContentPermissionsUpdateRequest{
+		RolePermissions:     []bookstack.RolePermission{{RoleID: 2, View: true}},
+		FallbackPermissions: &bookstack.FallbackPermissions{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestWriteRestore(t *testing.T) {
+	src := seedSource(t)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	m, err := Write(ctx, src.Client(), &buf)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if m.Version != Version || !m.Permissions || m.Source != src.URL {
+		t.Errorf("manifest = %+v", m)
+	}
+	if m.Counts[booksFile] != 2 || m.Counts[pagesFile] != 2 || m.Counts[attachmentsFile] != 2 || m.Counts[imagesFile] != 1 || m.Counts[commentsFile] != 2 {
+		t.Errorf("counts = %v", m.Counts)
+	}
+
+	dst := bookstacktest.NewServer()
+	defer dst.Close()
+	ids, err := Restore(ctx, dst.Client(), bytes.NewReader(buf.Bytes()), int64(buf.Len()), nil)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	for res, want := range map[string]int{"books": 2, "chapters": 1, "pages": 2, "shelves": 1, "attachments": 2, "image-gallery": 1, "comments": 2} {
+		if got := dst.Count(res); got != want {
+			t.Errorf("%s = %d, want %d", res, got, want)
+		}
+	}
+
+	c := dst.Client()
+	book, err := c.Books.GetBySlug(ctx, "operations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.Description != "Ops docs" || len(book.Tags) != 1 {
+		t.Errorf("book = %+v", book)
+	}
+	deploy, err := c.Pages.GetBySlug(ctx, "operations", "deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(deploy.Markdown, "]("+dst.URL+"/uploads/images/") {
+		t.Errorf("image URL not rewritten: %q", deploy.Markdown)
+	}
+	for old, id := range ids.Attachments {
+		a, _ := dst.Attachment(id)
+		if !a.External && !strings.Contains(deploy.Markdown, dst.URL+"/attachments/"+strconv.Itoa(id)) {
+			t.Errorf("attachment %d link not rewritten to %d: %q", old, id, deploy.Markdown)
+		}
+	}
+	overview, err := c.Pages.GetBySlug(ctx, "operations", "overview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := dst.URL + "/link/" + strconv.Itoa(deploy.ID); !strings.Contains(overview.HTML, want) {
+		t.Errorf("overview = %q, want link %s", overview.HTML, want)
+	}
+	if !overview.Template || deploy.Template {
+		t.Errorf("template flags: overview %v, deploy %v", overview.Template, deploy.Template)
+	}
+
+	shelf, err := c.Shelves.GetBySlug(ctx, "team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shelf.Books) != 1 || shelf.Books[0].ID != book.ID {
+		t.Errorf("shelf books = %+v", shelf.Books)
+	}
+	p, err := c.Permissions.Get(ctx, bookstack.EntityBook, book.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.FallbackPermissions.Inheriting || len(p.RolePermissions) != 1 || p.RolePermissions[0].RoleID != 2 {
+		t.Errorf("permissions = %+v", p)
+	}
+
+	var replies int
+	for _, id := range ids.Comments {
+		cm, _ := dst.Comment(id)
+		if cm.ParentID != 0 {
+			replies++
+			if _, ok := dst.Comment(cm.ParentID); !ok {
+				t.Errorf("reply %d has dangling parent %d", id, cm.ParentID)
+			}
+		}
+	}
+	if replies != 1 {
+		t.Errorf("replies = %d, want 1", replies)
+	}
+}
+
+func TestWriteScope(t *testing.T) {
+	src := seedSource(t)
+	scratch := src.AddPage(bookstack.Page{BookID: 1, Name: "Notes", Markdown: "notes"})
+	src.AddImage(bookstack.Image{Name: "scratch.png", UploadedTo: scratch.ID}, []byte("PNG"))
+	src.AddAttachment(bookstack.Attachment{Name: "Link", UploadedTo: scratch.ID, External: true, Content: "https://x"})
+	src.AddComment(bookstack.Comment{PageID: scratch.ID, HTML: "<p>Hi</p>"})
+	src.ResetRequests()
+
+	var buf bytes.Buffer
+	m, err := WriteScope(context.Background(), src.Client(), &buf, Scope{BookIDs: []int{2}})
+	if err != nil {
+		t.Fatalf("WriteScope: %v", err)
+	}
+	if m.Counts[booksFile] != 1 || m.Counts[pagesFile] != 2 || m.Counts[attachmentsFile] != 2 || m.Counts[imagesFile] != 1 || m.Counts[commentsFile] != 2 {
+		t.Errorf("counts = %v", m.Counts)
+	}
+	for _, req := range src.Requests() {
+		for _, path := range []string{"/api/attachments?", "/api/image-gallery?", "/api/comments?"} {
+			if strings.Contains(req, path) && !strings.Contains(req, "filter") {
+				t.Errorf("scoped backup listed all items: %s", req)
+			}
+		}
+	}
+}
+
+func TestRewriter_ImageURLs(t *testing.T) {
+	const path = "/uploads/images/gallery/2024-01/x.png"
+	images := map[string]string{
+		"https://old.example.com" + path: "https://new.example.com/uploads/images/gallery/2024-05/7-x.png",
+		path:                             "https://new.example.com/uploads/images/gallery/2024-05/7-x.png",
+	}
+	rw := newRewriter("https://old.example.com", "https://new.example.com", &IDMap{}, images, nil)
+	for in, want := range map[string]string{
+		"![](https://old.example.com" + path + ")":          "![](https://new.example.com/uploads/images/gallery/2024-05/7-x.png)",
+		`<img src="` + path + `">`:                          `<img src="https://new.example.com/uploads/images/gallery/2024-05/7-x.png">`,
+		"![](http://old.example.com" + path + ")":           "![](http://old.example.com" + path + ")",
+		"![](https://cdn.example.com" + path + ")":          "![](https://cdn.example.com" + path + ")",
+		"![](//cdn.example.com" + path + ")":                "![](//cdn.example.com" + path + ")",
+		"![](https://old.example.com/uploads/images/y.png)": "![](https://old.example.com/uploads/images/y.png)",
+	} {
+		if got := rw.rewrite(in); got != want {
+			t.Errorf("rewrite(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRestore_UnsupportedVersion(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, _ := zw.Create(manifestFile)
+	json.NewEncoder(f).Encode(Manifest{Format: Format, Version: Version + 1})
+	zw.Close()
+
+	dst := bookstacktest.NewServer()
+	defer dst.Close()
+	_, err := Restore(context.Background(), dst.Client(), bytes.NewReader(buf.Bytes()), int64(buf.Len()), nil)
+	if !errors.Is(err, ErrUnsupportedArchive) {
+		t.Errorf("expected ErrUnsupportedArchive, got %v", err)
+	}
+}
+
+func TestWrite_WithoutPermissionAccess(t *testing.T) {
+	src := bookstacktest.NewServer()
+	defer src.Close()
+	src.AddBook(bookstack.Book{Name: "A"})
+	src.AddBook(bookstack.Book{Name: "B"})
+	src.InjectFailure(bookstacktest.Failure{Path: "/api/content-permissions", Status: 403})
+
+	m, err := Write(context.Background(), src.Client(), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if m.Permissions || m.Counts[booksFile] != 2 {
+		t.Errorf("manifest = %+v", m)
+	}
+}
@@ -0,0 +1,481 @@
+package backup
+
+import (
+	"archive/zip"
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+// RestoreOptions configures Restore.
+type RestoreOptions struct {
+	// RoleIDs maps role IDs of the source instance to the target's.
+	// Unmapped roles keep their ID, which holds for the default roles of
+	// a fresh installation.
+	RoleIDs map[int]int
+
+	// UserIDs maps owner user IDs of the source instance to the target's.
+	// Items whose owner is unmapped are owned by the restoring user.
+	UserIDs map[int]int
+}
+
+// IDMap records the ID each restored item received, keyed by its ID in
+// the archive.
+type IDMap struct {
+	Shelves     map[int]int `json:"shelves"`
+	Books       map[int]int `json:"books"`
+	Chapters    map[int]int `json:"chapters"`
+	Pages       map[int]int `json:"pages"`
+	Attachments map[int]int `json:"attachments"`
+	Images      map[int]int `json:"images"`
+	Comments    map[int]int `json:"comments"`
+}
+
+func newIDMap() *IDMap {
+	return &IDMap{
+		Shelves:     make(map[int]int),
+		Books:       make(map[int]int),
+		Chapters:    make(map[int]int),
+		Pages:       make(map[int]int),
+		Attachments: make(map[int]int),
+		Images:      make(map[int]int),
+		Comments:    make(map[int]int),
+	}
+}
+
+// Restore recreates the content of an archive written by Write. It is
+// meant for an empty instance: existing content is left alone, so names
+// and slugs may clash.
+//
+// Links between pages, book and shelf URLs, attachment links and image
+// URLs in page content are rewritten to the new IDs and the target's base
+// URL; URLs of items not in the archive are left alone. Comments are
+// posted as the restoring user. The returned IDMap is valid for the items
+// restored so far even when an error is returned.
+func Restore(ctx context.Context, c *bookstack.Client, r io.ReaderAt, size int64, opts *RestoreOptions) (*IDMap, error) {
+	if opts == nil {
+		opts = &RestoreOptions{}
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedArchive, err)
+	}
+	m, err := ReadManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &restorer{ctx: ctx, c: c, zr: zr, opts: opts, manifest: m, ids: newIDMap(), imageURLs: make(map[string]string), slugs: make(map[string]string)}
+	steps := []func() error{rs.books, rs.chapters, rs.pages, rs.shelves, rs.attachments, rs.images, rs.rewritePages, rs.comments, rs.permissions}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return rs.ids, err
+		}
+	}
+	return rs.ids, nil
+}
+
+// ReadManifest reads and validates the manifest of an archive.
+func ReadManifest(zr *zip.Reader) (*Manifest, error) {
+	f, err := zr.Open(manifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing %s", ErrUnsupportedArchive, manifestFile)
+	}
+	defer f.Close()
+	var m Manifest
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: reading manifest: %v", ErrUnsupportedArchive, err)
+	}
+	if m.Format != Format {
+		return nil, fmt.Errorf("%w: format %q", ErrUnsupportedArchive, m.Format)
+	}
+	if m.Version < 1 || m.Version > Version {
+		return nil, fmt.Errorf("%w: version %d, want at most %d", ErrUnsupportedArchive, m.Version, Version)
+	}
+	return &m, nil
+}
+
+// restorer holds the state of one Restore call.
+type restorer struct {
+	ctx      context.Context
+	c        *bookstack.Client
+	zr       *zip.Reader
+	opts     *RestoreOptions
+	manifest *Manifest
+	ids      *IDMap
+
+	restored  []PageRecord        // Pages created, kept for rewriting
+	imageURLs map[string]string   // Old image URL or path to new URL
+	slugs     map[string]string   // Old book and shelf paths to new, see rewriter
+	perms     []pendingPermission // Applied last, once owners exist
+}
+
+type pendingPermission struct {
+	contentType string
+	id          int
+	p           *bookstack.ContentPermissions
+}
+
+// readLines decodes each line of a JSON Lines entry. Missing entries are
+// treated as empty.
+func readLines[T any](zr *zip.Reader, name string, fn func(T) error) error {
+	f, err := zr.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 256<<20)
+	for n := 1; sc.Scan(); n++ {
+		var item T
+		if err := json.Unmarshal(sc.Bytes(), &item); err != nil {
+			return fmt.Errorf("%s line %d: %w", name, n, err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func (rs *restorer) readFile(name string) ([]byte, error) {
+	f, err := rs.zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func (rs *restorer) queuePermissions(contentType string, id int, p *bookstack.ContentPermissions) {
+	if p != nil {
+		rs.perms = append(rs.perms, pendingPermission{contentType, id, p})
+	}
+}
+
+func (rs *restorer) books() error {
+	return readLines(rs.zr, booksFile, func(rec BookRecord) error {
+		book, err := rs.c.Books.Create(rs.ctx, &bookstack.BookCreateRequest{
+			Name:        rec.Name,
+			Description: rec.Description,
+			Tags:        rec.Tags,
+		})
+		if err != nil {
+			return fmt.Errorf("restoring book %d: %w", rec.ID, err)
+		}
+		rs.ids.Books[rec.ID] = book.ID
+		rs.slugs["/books/"+rec.Slug] = "/books/" + book.Slug
+		rs.queuePermissions(bookstack.EntityBook, book.ID, rec.Permissions)
+		return nil
+	})
+}
+
+func (rs *restorer) chapters() error {
+	return readLines(rs.zr, chaptersFile, func(rec ChapterRecord) error {
+		bookID, ok := rs.ids.Books[rec.BookID]
+		if !ok {
+			return fmt.Errorf("restoring chapter %d: book %d not in archive", rec.ID, rec.BookID)
+		}
+		ch, err := rs.c.Chapters.Create(rs.ctx, &bookstack.ChapterCreateRequest{
+			BookID:      bookID,
+			Name:        rec.Name,
+			Description: rec.Description,
+			Priority:    rec.Priority,
+			Tags:        rec.Tags,
+		})
+		if err != nil {
+			return fmt.Errorf("restoring chapter %d: %w", rec.ID, err)
+		}
+		rs.ids.Chapters[rec.ID] = ch.ID
+		rs.queuePermissions(bookstack.EntityChapter, ch.ID, rec.Permissions)
+		return nil
+	})
+}
+
+// pages creates the pages with their original content and template flag.
+// Links and images are fixed up by rewritePages once everything they point
+// to exists.
+func (rs *restorer) pages() error {
+	return readLines(rs.zr, pagesFile, func(rec PageRecord) error {
+		req := &bookstack.PageCreateRequest{
+			BookID:   rs.ids.Books[rec.BookID],
+			Name:     rec.Name,
+			Priority: rec.Priority,
+			Tags:     rec.Tags,
+		}
+		if rec.ChapterID != 0 {
+			req.ChapterID = rs.ids.Chapters[rec.ChapterID]
+		}
+		if req.BookID == 0 && req.ChapterID == 0 {
+			return fmt.Errorf("restoring page %d: book %d not in archive", rec.ID, rec.BookID)
+		}
+		if rec.Markdown != "" {
+			req.Markdown = rec.Markdown
+		} else {
+			req.HTML = rec.HTML
+		}
+		page, err := rs.c.Pages.Create(rs.ctx, req)
+		if err != nil {
+			return fmt.Errorf("restoring page %d: %w", rec.ID, err)
+		}
+		rs.ids.Pages[rec.ID] = page.ID
+		if rec.Template {
+			if _, err := rs.c.Templates.Mark(rs.ctx, page.ID); err != nil {
+				return fmt.Errorf("restoring template flag of page %d: %w", rec.ID, err)
+			}
+		}
+		rs.restored = append(rs.restored, rec)
+		rs.queuePermissions(bookstack.EntityPage, page.ID, rec.Permissions)
+		return nil
+	})
+}
+
+func (rs *restorer) shelves() error {
+	return readLines(rs.zr, shelvesFile, func(rec ShelfRecord) error {
+		var books []int
+		for _, id := range rec.BookIDs {
+			if newID, ok := rs.ids.Books[id]; ok {
+				books = append(books, newID)
+			}
+		}
+		shelf, err := rs.c.Shelves.Create(rs.ctx, &bookstack.ShelfCreateRequest{
+			Name:        rec.Name,
+			Description: rec.Description,
+			Books:       books,
+			Tags:        rec.Tags,
+		})
+		if err != nil {
+			return fmt.Errorf("restoring shelf %d: %w", rec.ID, err)
+		}
+		rs.ids.Shelves[rec.ID] = shelf.ID
+		rs.slugs["/shelves/"+rec.Slug] = "/shelves/" + shelf.Slug
+		rs.queuePermissions(bookstack.EntityShelf, shelf.ID, rec.Permissions)
+		return nil
+	})
+}
+
+func (rs *restorer) attachments() error {
+	return readLines(rs.zr, attachmentsFile, func(rec bookstack.Attachment) error {
+		pageID, ok := rs.ids.Pages[rec.UploadedTo]
+		if !ok {
+			return nil
+		}
+		var a *bookstack.Attachment
+		var err error
+		if rec.External {
+			a, err = rs.c.Attachments.Create(rs.ctx, &bookstack.AttachmentCreateRequest{
+				Name: rec.Name, UploadedTo: pageID, Link: rec.Content,
+			})
+		} else {
+			var data []byte
+			data, err = rs.readFile(attachmentsDir + strconv.Itoa(rec.ID))
+			if err != nil {
+				return fmt.Errorf("restoring attachment %d: %w", rec.ID, err)
+			}
+			filename := rec.Name
+			if rec.Extension != "" && path.Ext(filename) != "."+rec.Extension {
+				filename += "." + rec.Extension
+			}
+			a, err = rs.c.Attachments.Upload(rs.ctx, &bookstack.AttachmentUploadRequest{
+				Name: rec.Name, UploadedTo: pageID, Filename: filename, Data: data,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("restoring attachment %d: %w", rec.ID, err)
+		}
+		rs.ids.Attachments[rec.ID] = a.ID
+		return nil
+	})
+}
+
+// images uploads gallery images to their restored pages. Images not
+// attached to a restored page are skipped.
+func (rs *restorer) images() error {
+	return readLines(rs.zr, imagesFile, func(rec bookstack.Image) error {
+		pageID, ok := rs.ids.Pages[rec.UploadedTo]
+		if !ok {
+			return nil
+		}
+		data, err := rs.readFile(imagesDir + strconv.Itoa(rec.ID))
+		if err != nil {
+			return fmt.Errorf("restoring image %d: %w", rec.ID, err)
+		}
+		img, err := rs.c.Images.Create(rs.ctx, &bookstack.ImageUploadRequest{
+			Type:       rec.Type,
+			UploadedTo: pageID,
+			Name:       rec.Name,
+			Filename:   path.Base(cmp.Or(rec.Path, rec.URL)),
+			Data:       data,
+		})
+		if err != nil {
+			return fmt.Errorf("restoring image %d: %w", rec.ID, err)
+		}
+		rs.ids.Images[rec.ID] = img.ID
+		rs.imageURLs[rec.URL] = img.URL
+		if rec.Path != "" {
+			rs.imageURLs[rec.Path] = img.URL
+		}
+		return nil
+	})
+}
+
+// rewritePages points links and images in restored pages at the restored
+// items, updating only pages whose content changes.
+func (rs *restorer) rewritePages() error {
+	rw := newRewriter(rs.manifest.Source, rs.c.BaseURL(), rs.ids, rs.imageURLs, rs.slugs)
+	for _, rec := range rs.restored {
+		req := &bookstack.PageUpdateRequest{Name: rec.Name}
+		if rec.Markdown != "" {
+			req.Markdown = rw.rewrite(rec.Markdown)
+			if req.Markdown == rec.Markdown {
+				continue
+			}
+		} else {
+			req.HTML = rw.rewrite(rec.HTML)
+			if req.HTML == rec.HTML {
+				continue
+			}
+		}
+		if _, err := rs.c.Pages.Update(rs.ctx, rs.ids.Pages[rec.ID], req); err != nil {
+			return fmt.Errorf("rewriting page %d: %w", rec.ID, err)
+		}
+	}
+	return nil
+}
+
+// comments recreates comments in ID order so parents precede replies.
+func (rs *restorer) comments() error {
+	var recs []bookstack.Comment
+	if err := readLines(rs.zr, commentsFile, func(rec bookstack.Comment) error {
+		recs = append(recs, rec)
+		return nil
+	}); err != nil {
+		return err
+	}
+	slices.SortFunc(recs, func(a, b bookstack.Comment) int { return a.ID - b.ID })
+
+	for _, rec := range recs {
+		pageID, ok := rs.ids.Pages[rec.PageID]
+		if !ok {
+			continue
+		}
+		cm, err := rs.c.Comments.Create(rs.ctx, &bookstack.CommentCreateRequest{
+			PageID:   pageID,
+			ParentID: rs.ids.Comments[rec.ParentID],
+			HTML:     rec.HTML,
+		})
+		if err != nil {
+			return fmt.Errorf("restoring comment %d: %w", rec.ID, err)
+		}
+		rs.ids.Comments[rec.ID] = cm.ID
+	}
+	return nil
+}
+
+// permissions applies item-level permissions that differ from the
+// defaults of a new item.
+func (rs *restorer) permissions() error {
+	for _, pp := range rs.perms {
+		req := &bookstack.ContentPermissionsUpdateRequest{}
+		if owner, ok := rs.opts.UserIDs[pp.p.Owner.ID]; ok {
+			req.OwnerID = owner
+		}
+		for _, rp := range pp.p.RolePermissions {
+			if id, ok := rs.opts.RoleIDs[rp.RoleID]; ok {
+				rp.RoleID = id
+			}
+			rp.Role = nil
+			req.RolePermissions = append(req.RolePermissions, rp)
+		}
+		if !pp.p.FallbackPermissions.Inheriting {
+			fb := pp.p.FallbackPermissions
+			req.FallbackPermissions = &fb
+		}
+		if req.OwnerID == 0 && req.RolePermissions == nil && req.FallbackPermissions == nil {
+			continue
+		}
+		if _, err := rs.c.Permissions.Update(rs.ctx, pp.contentType, pp.id, req); err != nil {
+			return fmt.Errorf("restoring permissions of %s %d: %w", pp.contentType, pp.id, err)
+		}
+	}
+	return nil
+}
+
+// rewriter maps instance URLs in content from the source to the target.
+// URLs of items that were not restored keep pointing at the source.
+type rewriter struct {
+	re      *regexp.Regexp
+	newBase string
+	ids     *IDMap
+	images  map[string]string // Old image URL or path to new URL
+	slugs   map[string]string // Old "/books/<slug>" or "/shelves/<slug>" to new
+}
+
+func newRewriter(oldBase, newBase string, ids *IDMap, images, slugs map[string]string) *rewriter {
+	const uploads = `/uploads/images/[^\s"'()<>]+`
+	const slug = `[^/\s"'()<>#?]+`
+	const host = `(?:[a-zA-Z][a-zA-Z0-9+.-]*:)?//[^/\s"'()<>]+`
+	re := regexp.MustCompile(regexp.QuoteMeta(oldBase) +
+		`(/link/\d+|/attachments/\d+|` + uploads + `|/books/` + slug + `|/shelves/` + slug + `)|(` + host + `)?(` + uploads + `)`)
+	return &rewriter{re: re, newBase: newBase, ids: ids, images: images, slugs: slugs}
+}
+
+func (rw *rewriter) rewrite(s string) string {
+	return rw.re.ReplaceAllStringFunc(s, func(m string) string {
+		sub := rw.re.FindStringSubmatch(m)
+		if sub[3] != "" {
+			// A root-relative image path, or an image URL on another host
+			// or scheme, which is only rewritten if it is the recorded URL.
+			if u, ok := rw.images[m]; ok {
+				return u
+			}
+			return m
+		}
+		suffix := sub[1]
+		if u, ok := rw.images[m]; ok {
+			return u
+		}
+		if u, ok := rw.images[suffix]; ok {
+			return u
+		}
+		if p, ok := rw.slugs[suffix]; ok {
+			return rw.newBase + p
+		}
+		if u, ok := rw.mapID(suffix, "/link/", rw.ids.Pages); ok {
+			return u
+		}
+		if u, ok := rw.mapID(suffix, "/attachments/", rw.ids.Attachments); ok {
+			return u
+		}
+		return m
+	})
+}
+
+// mapID rewrites a path of the form prefix + old ID to the target's URL.
+func (rw *rewriter) mapID(suffix, prefix string, ids map[int]int) (string, bool) {
+	rest, ok := strings.CutPrefix(suffix, prefix)
+	if !ok {
+		return "", false
+	}
+	old, _ := strconv.Atoi(rest)
+	id, ok := ids[old]
+	if !ok {
+		return "", false
+	}
+	return rw.newBase + prefix + strconv.Itoa(id), true
+}
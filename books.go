@@ -23,7 +23,12 @@ func (s *BooksService) List(ctx context.Context, opts *ListOptions) ([]Book, err
 
 // ListAll returns an iterator over all books, handling pagination automatically.
 func (s *BooksService) ListAll(ctx context.Context) iter.Seq2[Book, error] {
-	return listAll[Book](ctx, s.client, "/api/books")
+	return listAll[Book](ctx, s.client, "/api/books", nil)
+}
+
+// ListAllWith returns an iterator over all books matching opts, handling pagination automatically.
+func (s *BooksService) ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Book, error] {
+	return listAll[Book](ctx, s.client, "/api/books", opts)
 }
 
 // Get retrieves a single book by ID.
@@ -35,3 +40,35 @@ func (s *BooksService) Get(ctx context.Context, id int) (*Book, error) {
 	}
 	return &book, nil
 }
+
+// GetBySlug retrieves a single book by its URL slug.
+func (s *BooksService) GetBySlug(ctx context.Context, slug string) (*Book, error) {
+	return getBySlug(ctx, s.client, "book:"+slug, func() (int, error) {
+		return s.client.bookIDBySlug(ctx, slug)
+	}, s.Get, func(b *Book) bool { return b.Slug == slug })
+}
+
+// Create creates a new book.
+func (s *BooksService) Create(ctx context.Context, req *BookCreateRequest) (*Book, error) {
+	var book Book
+	err := s.client.do(ctx, "POST", "/api/books", req, &book)
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// Update updates an existing book.
+func (s *BooksService) Update(ctx context.Context, id int, req *BookUpdateRequest) (*Book, error) {
+	var book Book
+	err := s.client.do(ctx, "PUT", fmt.Sprintf("/api/books/%d", id), req, &book)
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// Delete deletes a book by ID.
+func (s *BooksService) Delete(ctx context.Context, id int) error {
+	return s.client.do(ctx, "DELETE", fmt.Sprintf("/api/books/%d", id), nil, nil)
+}
@@ -100,3 +100,55 @@ func TestBooksService_Get_NotFound(t *testing.T) {
 		t.Error("expected ErrNotFound")
 	}
 }
+
+func TestBooksService_Create(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/books" {
+			t.Errorf("%s %s, want POST /api/books", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["name"] != "New Book" {
+			t.Errorf("name = %v", body["name"])
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 4, "name": "New Book", "slug": "new-book"})
+	})
+
+	book, err := c.Books.Create(context.Background(), &BookCreateRequest{
+		Name: "New Book",
+		Tags: []Tag{{Name: "team", Value: "ops"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if book.ID != 4 {
+		t.Errorf("ID = %d, want 4", book.ID)
+	}
+}
+
+func TestBooksService_UpdateDelete(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/books/4" {
+			t.Errorf("path = %s, want /api/books/4", r.URL.Path)
+		}
+		switch r.Method {
+		case "PUT":
+			json.NewEncoder(w).Encode(map[string]any{"id": 4, "name": "Renamed"})
+		case "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	})
+
+	book, err := c.Books.Update(context.Background(), 4, &BookUpdateRequest{Name: "Renamed"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if book.Name != "Renamed" {
+		t.Errorf("Name = %q", book.Name)
+	}
+	if err := c.Books.Delete(context.Background(), 4); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+}
@@ -17,7 +17,9 @@ package bookstack
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -38,38 +40,71 @@ type Config struct {
 	HTTPClient *http.Client
 }
 
+// Validate checks that all required fields are set and that BaseURL is an
+// absolute http or https URL. Each problem is reported as a *ConfigError;
+// several are combined with errors.Join. All match ErrInvalidConfig.
+func (cfg Config) Validate() error {
+	var errs []error
+	if cfg.BaseURL == "" {
+		errs = append(errs, &ConfigError{Field: "BaseURL", Reason: "is required"})
+	} else if reason := checkBaseURL(cfg.BaseURL); reason != "" {
+		errs = append(errs, &ConfigError{Field: "BaseURL", Reason: reason})
+	}
+	if cfg.TokenID == "" {
+		errs = append(errs, &ConfigError{Field: "TokenID", Reason: "is required"})
+	}
+	if cfg.TokenSecret == "" {
+		errs = append(errs, &ConfigError{Field: "TokenSecret", Reason: "is required"})
+	}
+	return errors.Join(errs...)
+}
+
+// checkBaseURL returns why raw is not a usable base URL, or "".
+func checkBaseURL(raw string) string {
+	u, err := url.Parse(raw)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%q is not a valid URL", raw)
+	case u.Scheme != "http" && u.Scheme != "https":
+		return fmt.Sprintf("%q must start with http:// or https://", raw)
+	case u.Host == "":
+		return fmt.Sprintf("%q has no host", raw)
+	case u.User != nil:
+		return fmt.Sprintf("%q must not contain credentials; use TokenID and TokenSecret", u.Redacted())
+	case u.RawQuery != "" || u.Fragment != "":
+		return fmt.Sprintf("%q must not have a query or fragment", raw)
+	}
+	return ""
+}
+
 // Client is the main Bookstack API client.
 type Client struct {
 	baseURL     string
 	tokenID     string
 	tokenSecret string
 	httpClient  *http.Client
+	slugs       slugCache
 
 	// Service instances
 	Attachments *AttachmentsService
 	Books       *BooksService
 	Chapters    *ChaptersService
 	Comments    *CommentsService
+	Drafts      *DraftsService
+	Images      *ImagesService
 	Pages       *PagesService
+	Permissions *PermissionsService
+	Revisions   *RevisionsService
 	Search      *SearchService
 	Shelves     *ShelvesService
+	Templates   *TemplatesService
 }
 
 // NewClient creates a new Bookstack API client.
-// Returns an error if BaseURL, TokenID, or TokenSecret are empty.
+// Returns an error if the configuration is invalid; see Config.Validate.
 func NewClient(cfg Config) (*Client, error) {
-	var errs []string
-	if cfg.BaseURL == "" {
-		errs = append(errs, "BaseURL is required")
-	}
-	if cfg.TokenID == "" {
-		errs = append(errs, "TokenID is required")
-	}
-	if cfg.TokenSecret == "" {
-		errs = append(errs, "TokenSecret is required")
-	}
-	if len(errs) > 0 {
-		return nil, errors.New(strings.Join(errs, "; "))
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
 	httpClient := cfg.HTTPClient
@@ -91,9 +126,19 @@ func NewClient(cfg Config) (*Client, error) {
 	c.Books = &BooksService{client: c}
 	c.Chapters = &ChaptersService{client: c}
 	c.Comments = &CommentsService{client: c}
+	c.Drafts = &DraftsService{client: c}
+	c.Images = &ImagesService{client: c}
+	c.Permissions = &PermissionsService{client: c}
 	c.Pages = &PagesService{client: c}
+	c.Revisions = &RevisionsService{client: c}
 	c.Search = &SearchService{client: c}
 	c.Shelves = &ShelvesService{client: c}
+	c.Templates = &TemplatesService{client: c}
 
 	return c, nil
 }
+
+// BaseURL returns the instance base URL without a trailing slash.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
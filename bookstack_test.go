@@ -1,7 +1,9 @@
 package bookstack
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -92,3 +94,50 @@ func TestNewClient(t *testing.T) {
 		}
 	})
 }
+
+func TestConfig_Validate(t *testing.T) {
+	valid := Config{BaseURL: "https://docs.example.com/wiki", TokenID: "abc", TokenSecret: "xyz"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		baseURL string
+		want    string
+	}{
+		{"no scheme", "docs.example.com", "must start with http:// or https://"},
+		{"wrong scheme", "ftp://docs.example.com", "must start with http:// or https://"},
+		{"no host", "https:///wiki", "has no host"},
+		{"credentials", "https://user:pw@docs.example.com", "must not contain credentials"},
+		{"query", "https://docs.example.com/?x=1", "must not have a query"},
+		{"unparsable", "https://docs example.com\x7f", "is not a valid URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			cfg.BaseURL = tt.baseURL
+			err := cfg.Validate()
+			var ce *ConfigError
+			if !errors.As(err, &ce) || ce.Field != "BaseURL" {
+				t.Fatalf("err = %v, want a BaseURL ConfigError", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %q, want it to contain %q", err, tt.want)
+			}
+			if strings.Contains(err.Error(), "pw") {
+				t.Errorf("err %q leaks the password", err)
+			}
+		})
+	}
+
+	err := Config{BaseURL: "https://x.com"}.Validate()
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("errors.Is(err, ErrInvalidConfig) = false for %v", err)
+	}
+	for _, field := range []string{"TokenID", "TokenSecret"} {
+		if !strings.Contains(err.Error(), "invalid config: "+field+" is required") {
+			t.Errorf("err %q does not mention %s", err, field)
+		}
+	}
+}
@@ -0,0 +1,812 @@
+// Package bookstackmock provides hand-written mock implementations of the
+// bookstack service interfaces.
+//
+// Each mock has one function field per method. Set the fields a test needs;
+// calling a method whose field is nil returns an error (or an iterator
+// yielding one) wrapping ErrNotImplemented:
+//
+//	pages := &bookstackmock.Pages{
+//	    GetFunc: func(ctx context.Context, id int) (*bookstack.Page, error) {
+//	        return &bookstack.Page{ID: id, Name: "Stub"}, nil
+//	    },
+//	}
+//	var api bookstack.PagesAPI = pages
+package bookstackmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+// ErrNotImplemented is returned by mock methods whose function field is nil.
+var ErrNotImplemented = errors.New("bookstackmock: method not implemented")
+
+func notImplemented(method string) error {
+	return fmt.Errorf("%s: %w", method, ErrNotImplemented)
+}
+
+// errSeq returns an iterator that yields a single error.
+func errSeq[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
+
+// Books is a mock implementation of bookstack.BooksAPI.
+type Books struct {
+	ListFunc        func(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Book, error)
+	ListAllFunc     func(ctx context.Context) iter.Seq2[bookstack.Book, error]
+	ListAllWithFunc func(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Book, error]
+	GetFunc         func(ctx context.Context, id int) (*bookstack.Book, error)
+	GetBySlugFunc   func(ctx context.Context, slug string) (*bookstack.Book, error)
+	CreateFunc      func(ctx context.Context, req *bookstack.BookCreateRequest) (*bookstack.Book, error)
+	UpdateFunc      func(ctx context.Context, id int, req *bookstack.BookUpdateRequest) (*bookstack.Book, error)
+	DeleteFunc      func(ctx context.Context, id int) error
+	PlanReorderFunc func(ctx context.Context, bookID int, layout []bookstack.LayoutItem) ([]bookstack.ReorderChange, error)
+	ReorderFunc     func(ctx context.Context, bookID int, layout []bookstack.LayoutItem) ([]bookstack.ReorderChange, error)
+}
+
+// List calls ListFunc.
+func (m *Books) List(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Book, error) {
+	if m.ListFunc == nil {
+		return nil, notImplemented("Books.List")
+	}
+	return m.ListFunc(ctx, opts)
+}
+
+// ListAll calls ListAllFunc.
+func (m *Books) ListAll(ctx context.Context) iter.Seq2[bookstack.Book, error] {
+	if m.ListAllFunc == nil {
+		return errSeq[bookstack.Book](notImplemented("Books.ListAll"))
+	}
+	return m.ListAllFunc(ctx)
+}
+
+// ListAllWith calls ListAllWithFunc.
+func (m *Books) ListAllWith(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Book, error] {
+	if m.ListAllWithFunc == nil {
+		return errSeq[bookstack.Book](notImplemented("Books.ListAllWith"))
+	}
+	return m.ListAllWithFunc(ctx, opts)
+}
+
+// Get calls GetFunc.
+func (m *Books) Get(ctx context.Context, id int) (*bookstack.Book, error) {
+	if m.GetFunc == nil {
+		return nil, notImplemented("Books.Get")
+	}
+	return m.GetFunc(ctx, id)
+}
+
+// GetBySlug calls GetBySlugFunc.
+func (m *Books) GetBySlug(ctx context.Context, slug string) (*bookstack.Book, error) {
+	if m.GetBySlugFunc == nil {
+		return nil, notImplemented("Books.GetBySlug")
+	}
+	return m.GetBySlugFunc(ctx, slug)
+}
+
+// Create calls CreateFunc.
+func (m *Books) Create(ctx context.Context, req *bookstack.BookCreateRequest) (*bookstack.Book, error) {
+	if m.CreateFunc == nil {
+		return nil, notImplemented("Books.Create")
+	}
+	return m.CreateFunc(ctx, req)
+}
+
+// Update calls UpdateFunc.
+func (m *Books) Update(ctx context.Context, id int, req *bookstack.BookUpdateRequest) (*bookstack.Book, error) {
+	if m.UpdateFunc == nil {
+		return nil, notImplemented("Books.Update")
+	}
+	return m.UpdateFunc(ctx, id, req)
+}
+
+// Delete calls DeleteFunc.
+func (m *Books) Delete(ctx context.Context, id int) error {
+	if m.DeleteFunc == nil {
+		return notImplemented("Books.Delete")
+	}
+	return m.DeleteFunc(ctx, id)
+}
+
+// PlanReorder calls PlanReorderFunc.
+func (m *Books) PlanReorder(ctx context.Context, bookID int, layout []bookstack.LayoutItem) ([]bookstack.ReorderChange, error) {
+	if m.PlanReorderFunc == nil {
+		return nil, notImplemented("Books.PlanReorder")
+	}
+	return m.PlanReorderFunc(ctx, bookID, layout)
+}
+
+// Reorder calls ReorderFunc.
+func (m *Books) Reorder(ctx context.Context, bookID int, layout []bookstack.LayoutItem) ([]bookstack.ReorderChange, error) {
+	if m.ReorderFunc == nil {
+		return nil, notImplemented("Books.Reorder")
+	}
+	return m.ReorderFunc(ctx, bookID, layout)
+}
+
+// Chapters is a mock implementation of bookstack.ChaptersAPI.
+type Chapters struct {
+	ListFunc        func(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Chapter, error)
+	ListAllFunc     func(ctx context.Context) iter.Seq2[bookstack.Chapter, error]
+	ListAllWithFunc func(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Chapter, error]
+	GetFunc         func(ctx context.Context, id int) (*bookstack.Chapter, error)
+	GetBySlugFunc   func(ctx context.Context, bookSlug, slug string) (*bookstack.Chapter, error)
+	CreateFunc      func(ctx context.Context, req *bookstack.ChapterCreateRequest) (*bookstack.Chapter, error)
+	UpdateFunc      func(ctx context.Context, id int, req *bookstack.ChapterUpdateRequest) (*bookstack.Chapter, error)
+	DeleteFunc      func(ctx context.Context, id int) error
+	MoveFunc        func(ctx context.Context, id int, target bookstack.MoveTarget) (*bookstack.Chapter, error)
+	CopyFunc        func(ctx context.Context, id int, target bookstack.MoveTarget) (*bookstack.Chapter, error)
+}
+
+// List calls ListFunc.
+func (m *Chapters) List(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Chapter, error) {
+	if m.ListFunc == nil {
+		return nil, notImplemented("Chapters.List")
+	}
+	return m.ListFunc(ctx, opts)
+}
+
+// ListAll calls ListAllFunc.
+func (m *Chapters) ListAll(ctx context.Context) iter.Seq2[bookstack.Chapter, error] {
+	if m.ListAllFunc == nil {
+		return errSeq[bookstack.Chapter](notImplemented("Chapters.ListAll"))
+	}
+	return m.ListAllFunc(ctx)
+}
+
+// ListAllWith calls ListAllWithFunc.
+func (m *Chapters) ListAllWith(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Chapter, error] {
+	if m.ListAllWithFunc == nil {
+		return errSeq[bookstack.Chapter](notImplemented("Chapters.ListAllWith"))
+	}
+	return m.ListAllWithFunc(ctx, opts)
+}
+
+// Get calls GetFunc.
+func (m *Chapters) Get(ctx context.Context, id int) (*bookstack.Chapter, error) {
+	if m.GetFunc == nil {
+		return nil, notImplemented("Chapters.Get")
+	}
+	return m.GetFunc(ctx, id)
+}
+
+// GetBySlug calls GetBySlugFunc.
+func (m *Chapters) GetBySlug(ctx context.Context, bookSlug, slug string) (*bookstack.Chapter, error) {
+	if m.GetBySlugFunc == nil {
+		return nil, notImplemented("Chapters.GetBySlug")
+	}
+	return m.GetBySlugFunc(ctx, bookSlug, slug)
+}
+
+// Create calls CreateFunc.
+func (m *Chapters) Create(ctx context.Context, req *bookstack.ChapterCreateRequest) (*bookstack.Chapter, error) {
+	if m.CreateFunc == nil {
+		return nil, notImplemented("Chapters.Create")
+	}
+	return m.CreateFunc(ctx, req)
+}
+
+// Update calls UpdateFunc.
+func (m *Chapters) Update(ctx context.Context, id int, req *bookstack.ChapterUpdateRequest) (*bookstack.Chapter, error) {
+	if m.UpdateFunc == nil {
+		return nil, notImplemented("Chapters.Update")
+	}
+	return m.UpdateFunc(ctx, id, req)
+}
+
+// Delete calls DeleteFunc.
+func (m *Chapters) Delete(ctx context.Context, id int) error {
+	if m.DeleteFunc == nil {
+		return notImplemented("Chapters.Delete")
+	}
+	return m.DeleteFunc(ctx, id)
+}
+
+// Move calls MoveFunc.
+func (m *Chapters) Move(ctx context.Context, id int, target bookstack.MoveTarget) (*bookstack.Chapter, error) {
+	if m.MoveFunc == nil {
+		return nil, notImplemented("Chapters.Move")
+	}
+	return m.MoveFunc(ctx, id, target)
+}
+
+// Copy calls CopyFunc.
+func (m *Chapters) Copy(ctx context.Context, id int, target bookstack.MoveTarget) (*bookstack.Chapter, error) {
+	if m.CopyFunc == nil {
+		return nil, notImplemented("Chapters.Copy")
+	}
+	return m.CopyFunc(ctx, id, target)
+}
+
+// Pages is a mock implementation of bookstack.PagesAPI.
+type Pages struct {
+	ListFunc              func(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Page, error)
+	ListAllFunc           func(ctx context.Context) iter.Seq2[bookstack.Page, error]
+	ListAllWithFunc       func(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Page, error]
+	GetFunc               func(ctx context.Context, id int) (*bookstack.Page, error)
+	GetBySlugFunc         func(ctx context.Context, bookSlug, slug string) (*bookstack.Page, error)
+	CreateFunc            func(ctx context.Context, req *bookstack.PageCreateRequest) (*bookstack.Page, error)
+	UpdateFunc            func(ctx context.Context, id int, req *bookstack.PageUpdateRequest) (*bookstack.Page, error)
+	UpdateIfUnchangedFunc func(ctx context.Context, seen *bookstack.Page, req *bookstack.PageUpdateRequest) (*bookstack.Page, error)
+	DeleteFunc            func(ctx context.Context, id int) error
+	MoveFunc              func(ctx context.Context, id int, target bookstack.MoveTarget) (*bookstack.Page, error)
+	CopyFunc              func(ctx context.Context, id int, target bookstack.MoveTarget) (*bookstack.Page, error)
+	ExportMarkdownFunc    func(ctx context.Context, id int) ([]byte, error)
+	ExportPDFFunc         func(ctx context.Context, id int) ([]byte, error)
+}
+
+// List calls ListFunc.
+func (m *Pages) List(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Page, error) {
+	if m.ListFunc == nil {
+		return nil, notImplemented("Pages.List")
+	}
+	return m.ListFunc(ctx, opts)
+}
+
+// ListAll calls ListAllFunc.
+func (m *Pages) ListAll(ctx context.Context) iter.Seq2[bookstack.Page, error] {
+	if m.ListAllFunc == nil {
+		return errSeq[bookstack.Page](notImplemented("Pages.ListAll"))
+	}
+	return m.ListAllFunc(ctx)
+}
+
+// ListAllWith calls ListAllWithFunc.
+func (m *Pages) ListAllWith(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Page, error] {
+	if m.ListAllWithFunc == nil {
+		return errSeq[bookstack.Page](notImplemented("Pages.ListAllWith"))
+	}
+	return m.ListAllWithFunc(ctx, opts)
+}
+
+// Get calls GetFunc.
+func (m *Pages) Get(ctx context.Context, id int) (*bookstack.Page, error) {
+	if m.GetFunc == nil {
+		return nil, notImplemented("Pages.Get")
+	}
+	return m.GetFunc(ctx, id)
+}
+
+// GetBySlug calls GetBySlugFunc.
+func (m *Pages) GetBySlug(ctx context.Context, bookSlug, slug string) (*bookstack.Page, error) {
+	if m.GetBySlugFunc == nil {
+		return nil, notImplemented("Pages.GetBySlug")
+	}
+	return m.GetBySlugFunc(ctx, bookSlug, slug)
+}
+
+// Create calls CreateFunc.
+func (m *Pages) Create(ctx context.Context, req *bookstack.PageCreateRequest) (*bookstack.Page, error) {
+	if m.CreateFunc == nil {
+		return nil, notImplemented("Pages.Create")
+	}
+	return m.CreateFunc(ctx, req)
+}
+
+// Update calls UpdateFunc.
+func (m *Pages) Update(ctx context.Context, id int, req *bookstack.PageUpdateRequest) (*bookstack.Page, error) {
+	if m.UpdateFunc == nil {
+		return nil, notImplemented("Pages.Update")
+	}
+	return m.UpdateFunc(ctx, id, req)
+}
+
+// UpdateIfUnchanged calls UpdateIfUnchangedFunc.
+func (m *Pages) UpdateIfUnchanged(ctx context.Context, seen *bookstack.Page, req *bookstack.PageUpdateRequest) (*bookstack.Page, error) {
+	if m.UpdateIfUnchangedFunc == nil {
+		return nil, notImplemented("Pages.UpdateIfUnchanged")
+	}
+	return m.UpdateIfUnchangedFunc(ctx, seen, req)
+}
+
+// Delete calls DeleteFunc.
+func (m *Pages) Delete(ctx context.Context, id int) error {
+	if m.DeleteFunc == nil {
+		return notImplemented("Pages.Delete")
+	}
+	return m.DeleteFunc(ctx, id)
+}
+
+// Move calls MoveFunc.
+func (m *Pages) Move(ctx context.Context, id int, target bookstack.MoveTarget) (*bookstack.Page, error) {
+	if m.MoveFunc == nil {
+		return nil, notImplemented("Pages.Move")
+	}
+	return m.MoveFunc(ctx, id, target)
+}
+
+// Copy calls CopyFunc.
+func (m *Pages) Copy(ctx context.Context, id int, target bookstack.MoveTarget) (*bookstack.Page, error) {
+	if m.CopyFunc == nil {
+		return nil, notImplemented("Pages.Copy")
+	}
+	return m.CopyFunc(ctx, id, target)
+}
+
+// ExportMarkdown calls ExportMarkdownFunc.
+func (m *Pages) ExportMarkdown(ctx context.Context, id int) ([]byte, error) {
+	if m.ExportMarkdownFunc == nil {
+		return nil, notImplemented("Pages.ExportMarkdown")
+	}
+	return m.ExportMarkdownFunc(ctx, id)
+}
+
+// ExportPDF calls ExportPDFFunc.
+func (m *Pages) ExportPDF(ctx context.Context, id int) ([]byte, error) {
+	if m.ExportPDFFunc == nil {
+		return nil, notImplemented("Pages.ExportPDF")
+	}
+	return m.ExportPDFFunc(ctx, id)
+}
+
+// Shelves is a mock implementation of bookstack.ShelvesAPI.
+type Shelves struct {
+	ListFunc        func(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Shelf, error)
+	ListAllFunc     func(ctx context.Context) iter.Seq2[bookstack.Shelf, error]
+	ListAllWithFunc func(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Shelf, error]
+	GetFunc         func(ctx context.Context, id int) (*bookstack.Shelf, error)
+	GetBySlugFunc   func(ctx context.Context, slug string) (*bookstack.Shelf, error)
+	CreateFunc      func(ctx context.Context, req *bookstack.ShelfCreateRequest) (*bookstack.Shelf, error)
+	UpdateFunc      func(ctx context.Context, id int, req *bookstack.ShelfUpdateRequest) (*bookstack.Shelf, error)
+	DeleteFunc      func(ctx context.Context, id int) error
+}
+
+// List calls ListFunc.
+func (m *Shelves) List(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Shelf, error) {
+	if m.ListFunc == nil {
+		return nil, notImplemented("Shelves.List")
+	}
+	return m.ListFunc(ctx, opts)
+}
+
+// ListAll calls ListAllFunc.
+func (m *Shelves) ListAll(ctx context.Context) iter.Seq2[bookstack.Shelf, error] {
+	if m.ListAllFunc == nil {
+		return errSeq[bookstack.Shelf](notImplemented("Shelves.ListAll"))
+	}
+	return m.ListAllFunc(ctx)
+}
+
+// ListAllWith calls ListAllWithFunc.
+func (m *Shelves) ListAllWith(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Shelf, error] {
+	if m.ListAllWithFunc == nil {
+		return errSeq[bookstack.Shelf](notImplemented("Shelves.ListAllWith"))
+	}
+	return m.ListAllWithFunc(ctx, opts)
+}
+
+// Get calls GetFunc.
+func (m *Shelves) Get(ctx context.Context, id int) (*bookstack.Shelf, error) {
+	if m.GetFunc == nil {
+		return nil, notImplemented("Shelves.Get")
+	}
+	return m.GetFunc(ctx, id)
+}
+
+// GetBySlug calls GetBySlugFunc.
+func (m *Shelves) GetBySlug(ctx context.Context, slug string) (*bookstack.Shelf, error) {
+	if m.GetBySlugFunc == nil {
+		return nil, notImplemented("Shelves.GetBySlug")
+	}
+	return m.GetBySlugFunc(ctx, slug)
+}
+
+// Create calls CreateFunc.
+func (m *Shelves) Create(ctx context.Context, req *bookstack.ShelfCreateRequest) (*bookstack.Shelf, error) {
+	if m.CreateFunc == nil {
+		return nil, notImplemented("Shelves.Create")
+	}
+	return m.CreateFunc(ctx, req)
+}
+
+// Update calls UpdateFunc.
+func (m *Shelves) Update(ctx context.Context, id int, req *bookstack.ShelfUpdateRequest) (*bookstack.Shelf, error) {
+	if m.UpdateFunc == nil {
+		return nil, notImplemented("Shelves.Update")
+	}
+	return m.UpdateFunc(ctx, id, req)
+}
+
+// Delete calls DeleteFunc.
+func (m *Shelves) Delete(ctx context.Context, id int) error {
+	if m.DeleteFunc == nil {
+		return notImplemented("Shelves.Delete")
+	}
+	return m.DeleteFunc(ctx, id)
+}
+
+// Revisions is a mock implementation of bookstack.RevisionsAPI.
+type Revisions struct {
+	ListFunc    func(ctx context.Context, pageID int, opts *bookstack.ListOptions) ([]bookstack.Revision, error)
+	ListAllFunc func(ctx context.Context, pageID int) iter.Seq2[bookstack.Revision, error]
+	GetFunc     func(ctx context.Context, pageID, revisionID int) (*bookstack.Revision, error)
+	DiffFunc    func(ctx context.Context, pageID, fromID, toID int, format bookstack.DiffFormat) (*bookstack.Diff, error)
+}
+
+// List calls ListFunc.
+func (m *Revisions) List(ctx context.Context, pageID int, opts *bookstack.ListOptions) ([]bookstack.Revision, error) {
+	if m.ListFunc == nil {
+		return nil, notImplemented("Revisions.List")
+	}
+	return m.ListFunc(ctx, pageID, opts)
+}
+
+// ListAll calls ListAllFunc.
+func (m *Revisions) ListAll(ctx context.Context, pageID int) iter.Seq2[bookstack.Revision, error] {
+	if m.ListAllFunc == nil {
+		return errSeq[bookstack.Revision](notImplemented("Revisions.ListAll"))
+	}
+	return m.ListAllFunc(ctx, pageID)
+}
+
+// Get calls GetFunc.
+func (m *Revisions) Get(ctx context.Context, pageID, revisionID int) (*bookstack.Revision, error) {
+	if m.GetFunc == nil {
+		return nil, notImplemented("Revisions.Get")
+	}
+	return m.GetFunc(ctx, pageID, revisionID)
+}
+
+// Diff calls DiffFunc.
+func (m *Revisions) Diff(ctx context.Context, pageID, fromID, toID int, format bookstack.DiffFormat) (*bookstack.Diff, error) {
+	if m.DiffFunc == nil {
+		return nil, notImplemented("Revisions.Diff")
+	}
+	return m.DiffFunc(ctx, pageID, fromID, toID, format)
+}
+
+// Search is a mock implementation of bookstack.SearchAPI.
+type Search struct {
+	SearchFunc     func(ctx context.Context, query string, opts *bookstack.ListOptions) ([]bookstack.SearchResult, error)
+	SearchPageFunc func(ctx context.Context, query string, page, count int) (*bookstack.SearchResponse, error)
+	SearchAllFunc  func(ctx context.Context, query string) iter.Seq2[bookstack.SearchResult, error]
+}
+
+// Search calls SearchFunc.
+func (m *Search) Search(ctx context.Context, query string, opts *bookstack.ListOptions) ([]bookstack.SearchResult, error) {
+	if m.SearchFunc == nil {
+		return nil, notImplemented("Search.Search")
+	}
+	return m.SearchFunc(ctx, query, opts)
+}
+
+// SearchPage calls SearchPageFunc.
+func (m *Search) SearchPage(ctx context.Context, query string, page, count int) (*bookstack.SearchResponse, error) {
+	if m.SearchPageFunc == nil {
+		return nil, notImplemented("Search.SearchPage")
+	}
+	return m.SearchPageFunc(ctx, query, page, count)
+}
+
+// SearchAll calls SearchAllFunc.
+func (m *Search) SearchAll(ctx context.Context, query string) iter.Seq2[bookstack.SearchResult, error] {
+	if m.SearchAllFunc == nil {
+		return errSeq[bookstack.SearchResult](notImplemented("Search.SearchAll"))
+	}
+	return m.SearchAllFunc(ctx, query)
+}
+
+// Attachments is a mock implementation of bookstack.AttachmentsAPI.
+type Attachments struct {
+	ListFunc        func(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Attachment, error)
+	ListAllFunc     func(ctx context.Context) iter.Seq2[bookstack.Attachment, error]
+	ListAllWithFunc func(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Attachment, error]
+	GetFunc         func(ctx context.Context, id int) (*bookstack.Attachment, error)
+	CreateFunc      func(ctx context.Context, req *bookstack.AttachmentCreateRequest) (*bookstack.Attachment, error)
+	UploadFunc      func(ctx context.Context, req *bookstack.AttachmentUploadRequest) (*bookstack.Attachment, error)
+	UpdateFunc      func(ctx context.Context, id int, req *bookstack.AttachmentUpdateRequest) (*bookstack.Attachment, error)
+	DeleteFunc      func(ctx context.Context, id int) error
+}
+
+// List calls ListFunc.
+func (m *Attachments) List(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Attachment, error) {
+	if m.ListFunc == nil {
+		return nil, notImplemented("Attachments.List")
+	}
+	return m.ListFunc(ctx, opts)
+}
+
+// ListAll calls ListAllFunc.
+func (m *Attachments) ListAll(ctx context.Context) iter.Seq2[bookstack.Attachment, error] {
+	if m.ListAllFunc == nil {
+		return errSeq[bookstack.Attachment](notImplemented("Attachments.ListAll"))
+	}
+	return m.ListAllFunc(ctx)
+}
+
+// ListAllWith calls ListAllWithFunc.
+func (m *Attachments) ListAllWith(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Attachment, error] {
+	if m.ListAllWithFunc == nil {
+		return errSeq[bookstack.Attachment](notImplemented("Attachments.ListAllWith"))
+	}
+	return m.ListAllWithFunc(ctx, opts)
+}
+
+// Get calls GetFunc.
+func (m *Attachments) Get(ctx context.Context, id int) (*bookstack.Attachment, error) {
+	if m.GetFunc == nil {
+		return nil, notImplemented("Attachments.Get")
+	}
+	return m.GetFunc(ctx, id)
+}
+
+// Create calls CreateFunc.
+func (m *Attachments) Create(ctx context.Context, req *bookstack.AttachmentCreateRequest) (*bookstack.Attachment, error) {
+	if m.CreateFunc == nil {
+		return nil, notImplemented("Attachments.Create")
+	}
+	return m.CreateFunc(ctx, req)
+}
+
+// Upload calls UploadFunc.
+func (m *Attachments) Upload(ctx context.Context, req *bookstack.AttachmentUploadRequest) (*bookstack.Attachment, error) {
+	if m.UploadFunc == nil {
+		return nil, notImplemented("Attachments.Upload")
+	}
+	return m.UploadFunc(ctx, req)
+}
+
+// Update calls UpdateFunc.
+func (m *Attachments) Update(ctx context.Context, id int, req *bookstack.AttachmentUpdateRequest) (*bookstack.Attachment, error) {
+	if m.UpdateFunc == nil {
+		return nil, notImplemented("Attachments.Update")
+	}
+	return m.UpdateFunc(ctx, id, req)
+}
+
+// Delete calls DeleteFunc.
+func (m *Attachments) Delete(ctx context.Context, id int) error {
+	if m.DeleteFunc == nil {
+		return notImplemented("Attachments.Delete")
+	}
+	return m.DeleteFunc(ctx, id)
+}
+
+// Comments is a mock implementation of bookstack.CommentsAPI.
+type Comments struct {
+	ListFunc        func(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Comment, error)
+	ListAllFunc     func(ctx context.Context) iter.Seq2[bookstack.Comment, error]
+	ListAllWithFunc func(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Comment, error]
+	GetFunc         func(ctx context.Context, id int) (*bookstack.Comment, error)
+	CreateFunc      func(ctx context.Context, req *bookstack.CommentCreateRequest) (*bookstack.Comment, error)
+	UpdateFunc      func(ctx context.Context, id int, req *bookstack.CommentUpdateRequest) (*bookstack.Comment, error)
+	DeleteFunc      func(ctx context.Context, id int) error
+}
+
+// List calls ListFunc.
+func (m *Comments) List(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Comment, error) {
+	if m.ListFunc == nil {
+		return nil, notImplemented("Comments.List")
+	}
+	return m.ListFunc(ctx, opts)
+}
+
+// ListAll calls ListAllFunc.
+func (m *Comments) ListAll(ctx context.Context) iter.Seq2[bookstack.Comment, error] {
+	if m.ListAllFunc == nil {
+		return errSeq[bookstack.Comment](notImplemented("Comments.ListAll"))
+	}
+	return m.ListAllFunc(ctx)
+}
+
+// ListAllWith calls ListAllWithFunc.
+func (m *Comments) ListAllWith(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Comment, error] {
+	if m.ListAllWithFunc == nil {
+		return errSeq[bookstack.Comment](notImplemented("Comments.ListAllWith"))
+	}
+	return m.ListAllWithFunc(ctx, opts)
+}
+
+// Get calls GetFunc.
+func (m *Comments) Get(ctx context.Context, id int) (*bookstack.Comment, error) {
+	if m.GetFunc == nil {
+		return nil, notImplemented("Comments.Get")
+	}
+	return m.GetFunc(ctx, id)
+}
+
+// Create calls CreateFunc.
+func (m *Comments) Create(ctx context.Context, req *bookstack.CommentCreateRequest) (*bookstack.Comment, error) {
+	if m.CreateFunc == nil {
+		return nil, notImplemented("Comments.Create")
+	}
+	return m.CreateFunc(ctx, req)
+}
+
+// Update calls UpdateFunc.
+func (m *Comments) Update(ctx context.Context, id int, req *bookstack.CommentUpdateRequest) (*bookstack.Comment, error) {
+	if m.UpdateFunc == nil {
+		return nil, notImplemented("Comments.Update")
+	}
+	return m.UpdateFunc(ctx, id, req)
+}
+
+// Delete calls DeleteFunc.
+func (m *Comments) Delete(ctx context.Context, id int) error {
+	if m.DeleteFunc == nil {
+		return notImplemented("Comments.Delete")
+	}
+	return m.DeleteFunc(ctx, id)
+}
+
+// Images is a mock implementation of bookstack.ImagesAPI.
+type Images struct {
+	ListFunc        func(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Image, error)
+	ListAllFunc     func(ctx context.Context) iter.Seq2[bookstack.Image, error]
+	ListAllWithFunc func(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Image, error]
+	GetFunc         func(ctx context.Context, id int) (*bookstack.Image, error)
+	CreateFunc      func(ctx context.Context, req *bookstack.ImageUploadRequest) (*bookstack.Image, error)
+	UpdateFunc      func(ctx context.Context, id int, req *bookstack.ImageUpdateRequest) (*bookstack.Image, error)
+	DeleteFunc      func(ctx context.Context, id int) error
+}
+
+// List calls ListFunc.
+func (m *Images) List(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Image, error) {
+	if m.ListFunc == nil {
+		return nil, notImplemented("Images.List")
+	}
+	return m.ListFunc(ctx, opts)
+}
+
+// ListAll calls ListAllFunc.
+func (m *Images) ListAll(ctx context.Context) iter.Seq2[bookstack.Image, error] {
+	if m.ListAllFunc == nil {
+		return errSeq[bookstack.Image](notImplemented("Images.ListAll"))
+	}
+	return m.ListAllFunc(ctx)
+}
+
+// ListAllWith calls ListAllWithFunc.
+func (m *Images) ListAllWith(ctx context.Context, opts *bookstack.ListOptions) iter.Seq2[bookstack.Image, error] {
+	if m.ListAllWithFunc == nil {
+		return errSeq[bookstack.Image](notImplemented("Images.ListAllWith"))
+	}
+	return m.ListAllWithFunc(ctx, opts)
+}
+
+// Get calls GetFunc.
+func (m *Images) Get(ctx context.Context, id int) (*bookstack.Image, error) {
+	if m.GetFunc == nil {
+		return nil, notImplemented("Images.Get")
+	}
+	return m.GetFunc(ctx, id)
+}
+
+// Create calls CreateFunc.
+func (m *Images) Create(ctx context.Context, req *bookstack.ImageUploadRequest) (*bookstack.Image, error) {
+	if m.CreateFunc == nil {
+		return nil, notImplemented("Images.Create")
+	}
+	return m.CreateFunc(ctx, req)
+}
+
+// Update calls UpdateFunc.
+func (m *Images) Update(ctx context.Context, id int, req *bookstack.ImageUpdateRequest) (*bookstack.Image, error) {
+	if m.UpdateFunc == nil {
+		return nil, notImplemented("Images.Update")
+	}
+	return m.UpdateFunc(ctx, id, req)
+}
+
+// Delete calls DeleteFunc.
+func (m *Images) Delete(ctx context.Context, id int) error {
+	if m.DeleteFunc == nil {
+		return notImplemented("Images.Delete")
+	}
+	return m.DeleteFunc(ctx, id)
+}
+
+// Drafts is a mock implementation of bookstack.DraftsAPI.
+type Drafts struct {
+	ListFunc    func(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Page, error)
+	ListAllFunc func(ctx context.Context) iter.Seq2[bookstack.Page, error]
+}
+
+// List calls ListFunc.
+func (m *Drafts) List(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Page, error) {
+	if m.ListFunc == nil {
+		return nil, notImplemented("Drafts.List")
+	}
+	return m.ListFunc(ctx, opts)
+}
+
+// ListAll calls ListAllFunc.
+func (m *Drafts) ListAll(ctx context.Context) iter.Seq2[bookstack.Page, error] {
+	if m.ListAllFunc == nil {
+		return errSeq[bookstack.Page](notImplemented("Drafts.ListAll"))
+	}
+	return m.ListAllFunc(ctx)
+}
+
+// Templates is a mock implementation of bookstack.TemplatesAPI.
+type Templates struct {
+	ListFunc        func(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Page, error)
+	ListAllFunc     func(ctx context.Context) iter.Seq2[bookstack.Page, error]
+	MarkFunc        func(ctx context.Context, pageID int) (*bookstack.Page, error)
+	UnmarkFunc      func(ctx context.Context, pageID int) (*bookstack.Page, error)
+	InstantiateFunc func(ctx context.Context, templateID int, req *bookstack.TemplateRequest) (*bookstack.Page, error)
+}
+
+// List calls ListFunc.
+func (m *Templates) List(ctx context.Context, opts *bookstack.ListOptions) ([]bookstack.Page, error) {
+	if m.ListFunc == nil {
+		return nil, notImplemented("Templates.List")
+	}
+	return m.ListFunc(ctx, opts)
+}
+
+// ListAll calls ListAllFunc.
+func (m *Templates) ListAll(ctx context.Context) iter.Seq2[bookstack.Page, error] {
+	if m.ListAllFunc == nil {
+		return errSeq[bookstack.Page](notImplemented("Templates.ListAll"))
+	}
+	return m.ListAllFunc(ctx)
+}
+
+// Mark calls MarkFunc.
+func (m *Templates) Mark(ctx context.Context, pageID int) (*bookstack.Page, error) {
+	if m.MarkFunc == nil {
+		return nil, notImplemented("Templates.Mark")
+	}
+	return m.MarkFunc(ctx, pageID)
+}
+
+// Unmark calls UnmarkFunc.
+func (m *Templates) Unmark(ctx context.Context, pageID int) (*bookstack.Page, error) {
+	if m.UnmarkFunc == nil {
+		return nil, notImplemented("Templates.Unmark")
+	}
+	return m.UnmarkFunc(ctx, pageID)
+}
+
+// Instantiate calls InstantiateFunc.
+func (m *Templates) Instantiate(ctx context.Context, templateID int, req *bookstack.TemplateRequest) (*bookstack.Page, error) {
+	if m.InstantiateFunc == nil {
+		return nil, notImplemented("Templates.Instantiate")
+	}
+	return m.InstantiateFunc(ctx, templateID, req)
+}
+
+// Permissions is a mock implementation of bookstack.PermissionsAPI.
+type Permissions struct {
+	GetFunc    func(ctx context.Context, contentType string, id int) (*bookstack.ContentPermissions, error)
+	UpdateFunc func(ctx context.Context, contentType string, id int, req *bookstack.ContentPermissionsUpdateRequest) (*bookstack.ContentPermissions, error)
+}
+
+// Get calls GetFunc.
+func (m *Permissions) Get(ctx context.Context, contentType string, id int) (*bookstack.ContentPermissions, error) {
+	if m.GetFunc == nil {
+		return nil, notImplemented("Permissions.Get")
+	}
+	return m.GetFunc(ctx, contentType, id)
+}
+
+// Update calls UpdateFunc.
+func (m *Permissions) Update(ctx context.Context, contentType string, id int, req *bookstack.ContentPermissionsUpdateRequest) (*bookstack.ContentPermissions, error) {
+	if m.UpdateFunc == nil {
+		return nil, notImplemented("Permissions.Update")
+	}
+	return m.UpdateFunc(ctx, contentType, id, req)
+}
+
+var (
+	_ bookstack.BooksAPI       = (*Books)(nil)
+	_ bookstack.ChaptersAPI    = (*Chapters)(nil)
+	_ bookstack.PagesAPI       = (*Pages)(nil)
+	_ bookstack.ShelvesAPI     = (*Shelves)(nil)
+	_ bookstack.RevisionsAPI   = (*Revisions)(nil)
+	_ bookstack.SearchAPI      = (*Search)(nil)
+	_ bookstack.AttachmentsAPI = (*Attachments)(nil)
+	_ bookstack.CommentsAPI    = (*Comments)(nil)
+	_ bookstack.ImagesAPI      = (*Images)(nil)
+	_ bookstack.DraftsAPI      = (*Drafts)(nil)
+	_ bookstack.TemplatesAPI   = (*Templates)(nil)
+	_ bookstack.PermissionsAPI = (*Permissions)(nil)
+)
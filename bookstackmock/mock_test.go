@@ -0,0 +1,37 @@
+package bookstackmock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+func TestPages_Get(t *testing.T) {
+	var api bookstack.PagesAPI = &Pages{
+		GetFunc: func(ctx context.Context, id int) (*bookstack.Page, error) {
+			return &bookstack.Page{ID: id, Name: "Stub"}, nil
+		},
+	}
+
+	page, err := api.Get(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.ID != 7 || page.Name != "Stub" {
+		t.Errorf("got %+v", page)
+	}
+}
+
+func TestPages_NotImplemented(t *testing.T) {
+	m := &Pages{}
+	if err := m.Delete(context.Background(), 1); !errors.Is(err, ErrNotImplemented) {
+		t.Errorf("expected ErrNotImplemented, got %v", err)
+	}
+	for _, err := range m.ListAll(context.Background()) {
+		if !errors.Is(err, ErrNotImplemented) {
+			t.Errorf("expected ErrNotImplemented, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package bookstacktest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// route dispatches an authenticated API request.
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, "/api/") && r.Method == http.MethodGet {
+		s.handleUpload(w, r)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "api" {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	if parts[1] == "search" && len(parts) == 2 && r.Method == http.MethodGet {
+		s.handleSearch(w, r)
+		return
+	}
+
+	if parts[1] == "content-permissions" && len(parts) == 4 {
+		s.handlePermissions(w, r, parts[2], parts[3])
+		return
+	}
+
+	res := parts[1]
+	if _, ok := s.data[res]; !ok {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		s.handleList(w, r, res)
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		s.handleCreate(w, r, res)
+	case len(parts) >= 3:
+		id, err := strconv.Atoi(parts[2])
+		if err != nil {
+			writeError(w, http.StatusNotFound, "Route not found")
+			return
+		}
+		switch {
+		case len(parts) == 3 && r.Method == http.MethodGet:
+			s.handleGet(w, res, id)
+		case len(parts) == 3 && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+			// POST with a _method=PUT form field is how multipart updates are sent.
+			s.handleUpdate(w, r, res, id)
+		case len(parts) == 3 && r.Method == http.MethodDelete:
+			s.handleDelete(w, res, id)
+		case len(parts) == 5 && parts[3] == "export" && res == resPages && r.Method == http.MethodGet:
+			s.handleExport(w, id, parts[4])
+		case len(parts) == 4 && parts[3] == "revisions" && res == resPages && r.Method == http.MethodGet:
+			s.handleRevisions(w, r, id)
+		case len(parts) == 5 && parts[3] == "revisions" && res == resPages && r.Method == http.MethodGet:
+			s.handleRevision(w, id, parts[4])
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request, res string) {
+	lq, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recs := s.data[res].sorted()
+	if res == resPages {
+		// Other users' drafts are never listed.
+		recs = slices.DeleteFunc(recs, func(rec record) bool {
+			return rec.bool("draft") && rec.int("created_by") != apiUserID
+		})
+	}
+	page, total := lq.apply(recs)
+	data := make([]record, len(page))
+	for i, rec := range page {
+		data[i] = listView(res, rec)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data, "total": total})
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, res string, id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.data[res].items[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, notFoundMessage(res))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.readView(res, rec))
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request, res string) {
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, status, err := s.create(res, body)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.readView(res, rec))
+}
+
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request, res string, id int) {
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.data[res].items[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, notFoundMessage(res))
+		return
+	}
+	if status, err := s.update(res, rec, body); err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.readView(res, rec))
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, res string, id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data[res].items[id]; !ok {
+		writeError(w, http.StatusNotFound, notFoundMessage(res))
+		return
+	}
+	s.delete(res, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleExport(w http.ResponseWriter, id int, format string) {
+	s.mu.Lock()
+	rec, ok := s.data[resPages].items[id]
+	if ok {
+		rec = rec.clone()
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, notFoundMessage(resPages))
+		return
+	}
+
+	var contentType, body string
+	switch format {
+	case "markdown":
+		contentType = "text/markdown; charset=utf-8"
+		body = rec.str("markdown")
+		if body == "" {
+			body = "# " + rec.str("name") + "\n\n" + plainText(rec.str("html")) + "\n"
+		}
+	case "plaintext":
+		contentType = "text/plain; charset=utf-8"
+		body = rec.str("name") + "\n\n" + plainText(rec.str("html")) + "\n"
+	case "html":
+		contentType = "text/html; charset=utf-8"
+		body = "<!doctype html><html><head><title>" + html.EscapeString(rec.str("name")) +
+			"</title></head><body>" + rec.str("html") + "</body></html>"
+	case "pdf":
+		contentType = "application/pdf"
+		body = "%PDF-1.4\n% bookstacktest export of page " + strconv.Itoa(id) + "\n%%EOF\n"
+	default:
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	io.WriteString(w, body)
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data, ok := s.uploads[r.URL.Path]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if ct := mime.TypeByExtension(filepath.Ext(r.URL.Path)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.Write(data)
+}
+
+// readBody decodes a JSON or multipart form request body into a record.
+// Uploaded files are stored base64-encoded under their field name, with the
+// file name in "<field>_name".
+func readBody(r *http.Request) (record, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return nil, fmt.Errorf("invalid multipart body: %v", err)
+		}
+		rec := record{}
+		for key, vals := range r.MultipartForm.Value {
+			rec[key] = vals[0]
+		}
+		for key, files := range r.MultipartForm.File {
+			f, err := files[0].Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			rec[key] = base64.StdEncoding.EncodeToString(data)
+			rec[key+"_name"] = files[0].Filename
+		}
+		return rec, nil
+	}
+
+	rec := record{}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return rec, nil
+	}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %v", err)
+	}
+	return rec, nil
+}
+
+// intField reads an integer that may have been sent as a JSON number or a form string.
+func intField(rec record, key string) (int, bool) {
+	switch v := rec[key].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func notFoundMessage(res string) string {
+	name := strings.TrimSuffix(res, "s")
+	switch res {
+	case resShelves:
+		name = "bookshelf"
+	case resImages:
+		name = "image"
+	}
+	return strings.ToUpper(name[:1]) + name[1:] + " not found"
+}
+
+// listView returns the fields of rec included in list responses.
+func listView(res string, rec record) record {
+	out := rec.clone()
+	switch res {
+	case resPages:
+		delete(out, "html")
+		delete(out, "markdown")
+		delete(out, "tags")
+	case resShelves:
+		delete(out, "books")
+		delete(out, "tags")
+	case resBooks, resChapters:
+		delete(out, "tags")
+	case resAttachments:
+		delete(out, "content")
+	}
+	return out
+}
+
+// readView returns the fields of rec included in single-item responses.
+// s.mu must be held.
+func (s *Server) readView(res string, rec record) record {
+	out := rec.clone()
+	switch res {
+	case resBooks:
+		out["contents"] = s.bookContents(rec.int("id"))
+	case resChapters:
+		out["book_slug"] = s.bookSlug(rec.int("book_id"))
+		var pages []record
+		for _, p := range s.data[resPages].sorted() {
+			if p.int("chapter_id") == rec.int("id") && !p.bool("draft") {
+				pages = append(pages, listView(resPages, p))
+			}
+		}
+		sortByPriority(pages)
+		out["pages"] = nonNil(pages)
+	case resPages:
+		out["book_slug"] = s.bookSlug(rec.int("book_id"))
+	case resShelves:
+		var books []record
+		for _, id := range intSlice(rec["books"]) {
+			if b, ok := s.data[resBooks].items[id]; ok {
+				books = append(books, record{"id": id, "name": b["name"], "slug": b["slug"]})
+			}
+		}
+		out["books"] = nonNil(books)
+	case resAttachments:
+		out["links"] = map[string]string{
+			"html":     fmt.Sprintf(`<a href="%s/attachments/%d">%s</a>`, s.URL, rec.int("id"), html.EscapeString(rec.str("name"))),
+			"markdown": fmt.Sprintf("[%s](%s/attachments/%d)", rec.str("name"), s.URL, rec.int("id")),
+		}
+	}
+	return out
+}
+
+// bookContents lists a book's chapters (with their pages) and direct pages
+// in priority order, as returned by the book read endpoint. s.mu must be held.
+func (s *Server) bookContents(bookID int) []record {
+	var contents []record
+	for _, ch := range s.data[resChapters].sorted() {
+		if ch.int("book_id") != bookID {
+			continue
+		}
+		item := listView(resChapters, ch)
+		item["type"] = "chapter"
+		var pages []record
+		for _, p := range s.data[resPages].sorted() {
+			if p.int("chapter_id") == ch.int("id") && !p.bool("draft") {
+				pages = append(pages, listView(resPages, p))
+			}
+		}
+		sortByPriority(pages)
+		item["pages"] = nonNil(pages)
+		contents = append(contents, item)
+	}
+	for _, p := range s.data[resPages].sorted() {
+		if p.int("book_id") == bookID && p.int("chapter_id") == 0 && !p.bool("draft") {
+			item := listView(resPages, p)
+			item["type"] = "page"
+			contents = append(contents, item)
+		}
+	}
+	sortByPriority(contents)
+	return nonNil(contents)
+}
+
+func sortByPriority(recs []record) {
+	slices.SortStableFunc(recs, func(a, b record) int { return a.int("priority") - b.int("priority") })
+}
+
+func nonNil(recs []record) []record {
+	if recs == nil {
+		return []record{}
+	}
+	return recs
+}
+
+func intSlice(v any) []int {
+	var out []int
+	switch v := v.(type) {
+	case []int:
+		return v
+	case []any:
+		for _, x := range v {
+			if f, ok := x.(float64); ok {
+				out = append(out, int(f))
+			}
+		}
+	}
+	return out
+}
+
+// bookSlug returns the slug of a book. s.mu must be held.
+func (s *Server) bookSlug(id int) string {
+	return s.data[resBooks].items[id].str("slug")
+}
+
+var tagPattern = regexp.MustCompile(`(?s)<[^>]*>`)
+
+// plainText strips tags from an HTML fragment and collapses whitespace.
+func plainText(h string) string {
+	text := tagPattern.ReplaceAllString(h, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+// filepathExt returns a file extension without the leading dot.
+func filepathExt(name string) string {
+	return strings.TrimPrefix(filepath.Ext(name), ".")
+}
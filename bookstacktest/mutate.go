@@ -0,0 +1,351 @@
+package bookstacktest
+
+import (
+	"cmp"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/markup"
+)
+
+// create validates body and stores a new record of type res.
+// It returns the HTTP status to use on error. s.mu must be held.
+func (s *Server) create(res string, body record) (record, int, error) {
+	now := formatTime(s.now())
+	rec := record{
+		"created_at": now,
+		"updated_at": now,
+		"created_by": apiUserID,
+		"updated_by": apiUserID,
+	}
+
+	switch res {
+	case resBooks, resShelves, resChapters, resPages:
+		name := strings.TrimSpace(body.str("name"))
+		if name == "" {
+			return nil, http.StatusUnprocessableEntity, fmt.Errorf("the name field is required")
+		}
+		rec["name"] = name
+		rec["slug"] = slugify(name)
+		rec["owned_by"] = 1
+		rec["tags"] = tagsOrEmpty(body["tags"])
+	}
+
+	switch res {
+	case resBooks:
+		rec["description"] = body.str("description")
+	case resShelves:
+		rec["description"] = body.str("description")
+		rec["books"] = intSlice(body["books"])
+	case resChapters:
+		bookID, _ := intField(body, "book_id")
+		if _, ok := s.data[resBooks].items[bookID]; !ok {
+			return nil, http.StatusUnprocessableEntity, fmt.Errorf("the book_id field must reference an existing book")
+		}
+		rec["book_id"] = bookID
+		rec["description"] = body.str("description")
+		rec["priority"] = s.nextPriority(bookID)
+		if p, ok := intField(body, "priority"); ok && p > 0 {
+			rec["priority"] = p
+		}
+	case resPages:
+		bookID, chapterID, err := s.pageParent(body, 0, 0)
+		if err != nil {
+			return nil, http.StatusUnprocessableEntity, err
+		}
+		rec["book_id"] = bookID
+		rec["chapter_id"] = chapterID
+		rec["markdown"] = body.str("markdown")
+		rec["html"] = body.str("html")
+		if rec.str("html") == "" && rec.str("markdown") != "" {
+			rec["html"] = markup.ToHTML(rec.str("markdown"))
+		}
+		rec["priority"] = s.nextPriority(bookID)
+		if p, ok := intField(body, "priority"); ok && p > 0 {
+			rec["priority"] = p
+		}
+		rec["draft"] = false
+		rec["template"] = false
+		rec["revision_count"] = 1
+	case resAttachments:
+		pageID, _ := intField(body, "uploaded_to")
+		if _, ok := s.data[resPages].items[pageID]; !ok {
+			return nil, http.StatusUnprocessableEntity, fmt.Errorf("the uploaded_to field must reference an existing page")
+		}
+		name := strings.TrimSpace(body.str("name"))
+		if name == "" {
+			return nil, http.StatusUnprocessableEntity, fmt.Errorf("the name field is required")
+		}
+		rec["name"] = name
+		rec["uploaded_to"] = pageID
+		rec["order"] = len(s.attachmentsOf(pageID))
+		if err := setAttachmentContent(rec, body); err != nil {
+			return nil, http.StatusUnprocessableEntity, err
+		}
+	case resImages:
+		pageID, _ := intField(body, "uploaded_to")
+		if _, ok := s.data[resPages].items[pageID]; !ok {
+			return nil, http.StatusUnprocessableEntity, fmt.Errorf("the uploaded_to field must reference an existing page")
+		}
+		data, err := base64.StdEncoding.DecodeString(body.str("image"))
+		if err != nil || len(data) == 0 {
+			return nil, http.StatusUnprocessableEntity, fmt.Errorf("the image field is required")
+		}
+		filename := path.Base(body.str("image_name"))
+		typ := cmp.Or(body.str("type"), "gallery")
+		p := fmt.Sprintf("/uploads/images/%s/%s/%d-%s", typ, s.now().Format("2006-01"), s.data[resImages].nextID, filename)
+		s.uploads[p] = data
+		rec["name"] = cmp.Or(strings.TrimSpace(body.str("name")), filename)
+		rec["type"] = typ
+		rec["uploaded_to"] = pageID
+		rec["path"] = p
+		rec["url"] = s.URL + p
+	case resComments:
+		pageID, _ := intField(body, "page_id")
+		if _, ok := s.data[resPages].items[pageID]; !ok {
+			return nil, http.StatusUnprocessableEntity, fmt.Errorf("the page_id field must reference an existing page")
+		}
+		if body.str("html") == "" {
+			return nil, http.StatusUnprocessableEntity, fmt.Errorf("the html field is required")
+		}
+		rec["page_id"] = pageID
+		rec["html"] = body.str("html")
+		if parentID, ok := intField(body, "parent_id"); ok && parentID > 0 {
+			rec["parent_id"] = parentID
+		}
+	}
+
+	rec = s.data[res].insert(rec)
+	if res == resPages {
+		s.saveRevision(rec, body.str("summary"))
+	}
+	return rec, 0, nil
+}
+
+// update applies body to rec. s.mu must be held.
+func (s *Server) update(res string, rec, body record) (int, error) {
+	if name, ok := body["name"].(string); ok && strings.TrimSpace(name) != "" && name != rec.str("name") {
+		rec["name"] = strings.TrimSpace(name)
+		if _, hasSlug := rec["slug"]; hasSlug {
+			rec["slug"] = slugify(name)
+		}
+	}
+	if tags, ok := body["tags"]; ok && rec["tags"] != nil {
+		rec["tags"] = tagsOrEmpty(tags)
+	}
+
+	switch res {
+	case resBooks, resChapters, resShelves:
+		if d, ok := body["description"].(string); ok {
+			rec["description"] = d
+		}
+		if res == resShelves {
+			if books, ok := body["books"]; ok {
+				rec["books"] = intSlice(books)
+			}
+		}
+		if res == resChapters {
+			if bookID, ok := intField(body, "book_id"); ok && bookID != rec.int("book_id") {
+				if _, exists := s.data[resBooks].items[bookID]; !exists {
+					return http.StatusUnprocessableEntity, fmt.Errorf("the book_id field must reference an existing book")
+				}
+				rec["book_id"] = bookID
+				rec["priority"] = s.nextPriority(bookID)
+				for _, p := range s.data[resPages].items {
+					if p.int("chapter_id") == rec.int("id") {
+						p["book_id"] = bookID
+					}
+				}
+			}
+			if p, ok := intField(body, "priority"); ok {
+				rec["priority"] = p
+			}
+		}
+	case resPages:
+		bookID, chapterID, err := s.pageParent(body, rec.int("book_id"), rec.int("chapter_id"))
+		if err != nil {
+			return http.StatusUnprocessableEntity, err
+		}
+		if bookID != rec.int("book_id") || chapterID != rec.int("chapter_id") {
+			rec["priority"] = s.nextPriority(bookID)
+		}
+		rec["book_id"], rec["chapter_id"] = bookID, chapterID
+		changed := false
+		if h, ok := body["html"].(string); ok && h != "" {
+			rec["html"], rec["markdown"] = h, ""
+			changed = true
+		}
+		if md, ok := body["markdown"].(string); ok && md != "" {
+			rec["markdown"], rec["html"] = md, markup.ToHTML(md)
+			changed = true
+		}
+		if p, ok := intField(body, "priority"); ok {
+			rec["priority"] = p
+		}
+		if t, ok := body["template"]; ok {
+			rec["template"] = boolValue(t)
+		}
+		if changed || body["name"] != nil {
+			rec["revision_count"] = rec.int("revision_count") + 1
+			defer s.saveRevision(rec, body.str("summary"))
+		}
+	case resAttachments:
+		if _, hasLink := body["link"]; hasLink || body["file"] != nil {
+			if err := setAttachmentContent(rec, body); err != nil {
+				return http.StatusUnprocessableEntity, err
+			}
+		}
+		if pageID, ok := intField(body, "uploaded_to"); ok && pageID > 0 {
+			if _, exists := s.data[resPages].items[pageID]; !exists {
+				return http.StatusUnprocessableEntity, fmt.Errorf("the uploaded_to field must reference an existing page")
+			}
+			rec["uploaded_to"] = pageID
+		}
+	case resComments:
+		if h, ok := body["html"].(string); ok && h != "" {
+			rec["html"] = h
+		}
+	}
+
+	rec["updated_at"] = formatTime(s.now())
+	return 0, nil
+}
+
+// delete removes a record and everything that depends on it. s.mu must be held.
+func (s *Server) delete(res string, id int) {
+	rec := s.data[res].items[id]
+	delete(s.data[res].items, id)
+	delete(s.permissions, permissionKey(res, id))
+	switch res {
+	case resImages:
+		delete(s.uploads, rec.str("path"))
+	case resBooks:
+		for cid, ch := range s.data[resChapters].items {
+			if ch.int("book_id") == id {
+				s.delete(resChapters, cid)
+			}
+		}
+		for pid, p := range s.data[resPages].items {
+			if p.int("book_id") == id {
+				s.delete(resPages, pid)
+			}
+		}
+		for _, sh := range s.data[resShelves].items {
+			books := intSlice(sh["books"])
+			kept := books[:0]
+			for _, b := range books {
+				if b != id {
+					kept = append(kept, b)
+				}
+			}
+			sh["books"] = kept
+		}
+	case resChapters:
+		for pid, p := range s.data[resPages].items {
+			if p.int("chapter_id") == id {
+				s.delete(resPages, pid)
+			}
+		}
+	case resPages:
+		for iid, img := range s.data[resImages].items {
+			if img.int("uploaded_to") == id {
+				s.delete(resImages, iid)
+			}
+		}
+		for aid, a := range s.data[resAttachments].items {
+			if a.int("uploaded_to") == id {
+				delete(s.data[resAttachments].items, aid)
+			}
+		}
+		for cid, c := range s.data[resComments].items {
+			if c.int("page_id") == id {
+				delete(s.data[resComments].items, cid)
+			}
+		}
+		for rid, rev := range s.revisions.items {
+			if rev.int("page_id") == id {
+				delete(s.revisions.items, rid)
+			}
+		}
+	}
+}
+
+// pageParent determines a page's book and chapter from a request body,
+// falling back to the current values. A chapter always implies its book.
+func (s *Server) pageParent(body record, bookID, chapterID int) (int, int, error) {
+	if id, ok := intField(body, "book_id"); ok && id > 0 {
+		if _, exists := s.data[resBooks].items[id]; !exists {
+			return 0, 0, fmt.Errorf("the book_id field must reference an existing book")
+		}
+		// Naming a book moves the page to its top level.
+		bookID, chapterID = id, 0
+	}
+	if id, ok := intField(body, "chapter_id"); ok && id > 0 {
+		ch, exists := s.data[resChapters].items[id]
+		if !exists {
+			return 0, 0, fmt.Errorf("the chapter_id field must reference an existing chapter")
+		}
+		chapterID, bookID = id, ch.int("book_id")
+	}
+	if bookID == 0 {
+		return 0, 0, fmt.Errorf("the book_id field is required when chapter_id is not present")
+	}
+	return bookID, chapterID, nil
+}
+
+// nextPriority returns a priority placing a new item at the end of a book.
+func (s *Server) nextPriority(bookID int) int {
+	p := 0
+	for _, res := range []string{resChapters, resPages} {
+		for _, rec := range s.data[res].items {
+			if rec.int("book_id") == bookID {
+				p = max(p, rec.int("priority")+1)
+			}
+		}
+	}
+	return p
+}
+
+func (s *Server) attachmentsOf(pageID int) []record {
+	var out []record
+	for _, a := range s.data[resAttachments].sorted() {
+		if a.int("uploaded_to") == pageID {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// setAttachmentContent stores either a link or an uploaded file on rec.
+func setAttachmentContent(rec, body record) error {
+	if link := body.str("link"); link != "" {
+		rec["external"] = true
+		rec["extension"] = ""
+		rec["content"] = link
+		return nil
+	}
+	if file := body.str("file"); file != "" {
+		rec["external"] = false
+		rec["extension"] = filepathExt(body.str("file_name"))
+		rec["content"] = file
+		return nil
+	}
+	return fmt.Errorf("a link or file must be provided")
+}
+
+// tagsOrEmpty normalizes a tags value from a request body.
+func tagsOrEmpty(v any) []any {
+	tags, _ := v.([]any)
+	out := make([]any, 0, len(tags))
+	for i, t := range tags {
+		m, ok := t.(map[string]any)
+		if !ok {
+			continue
+		}
+		out = append(out, map[string]any{"name": m["name"], "value": m["value"], "order": i})
+	}
+	return out
+}
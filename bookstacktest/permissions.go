@@ -0,0 +1,93 @@
+package bookstacktest
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// contentTypes maps content permission types to resources.
+var contentTypes = map[string]string{
+	"book":      resBooks,
+	"chapter":   resChapters,
+	"page":      resPages,
+	"bookshelf": resShelves,
+}
+
+func permissionKey(res string, id int) string {
+	return res + "/" + strconv.Itoa(id)
+}
+
+// handlePermissions serves GET and PUT /api/content-permissions/{type}/{id}.
+func (s *Server) handlePermissions(w http.ResponseWriter, r *http.Request, typ, rawID string) {
+	res, ok := contentTypes[typ]
+	id, err := strconv.Atoi(rawID)
+	if !ok || err != nil {
+		writeError(w, http.StatusNotFound, "Route not found")
+		return
+	}
+
+	var body record
+	if r.Method == http.MethodPut {
+		if body, err = readBody(r); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.data[res].items[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, notFoundMessage(res))
+		return
+	}
+	key := permissionKey(res, id)
+	p, ok := s.permissions[key]
+	if !ok {
+		p = record{
+			"owner_id":             item.int("owned_by"),
+			"role_permissions":     []any{},
+			"fallback_permissions": record{"inheriting": true, "view": nil, "create": nil, "update": nil, "delete": nil},
+		}
+	}
+
+	if body != nil {
+		if owner, ok := intField(body, "owner_id"); ok && owner > 0 {
+			p["owner_id"] = owner
+			item["owned_by"] = owner
+		}
+		if roles, ok := body["role_permissions"].([]any); ok {
+			p["role_permissions"] = roles
+		}
+		if fb, ok := body["fallback_permissions"].(map[string]any); ok {
+			p["fallback_permissions"] = record(fb)
+		}
+		s.permissions[key] = p
+	}
+
+	roles := []record{}
+	for _, rp := range p["role_permissions"].([]any) {
+		rp, ok := rp.(map[string]any)
+		if !ok {
+			continue
+		}
+		roleID, _ := intField(rp, "role_id")
+		roles = append(roles, record{
+			"role_id": roleID,
+			"view":    rp["view"] == true,
+			"create":  rp["create"] == true,
+			"update":  rp["update"] == true,
+			"delete":  rp["delete"] == true,
+			"role":    record{"id": roleID, "display_name": "Role " + strconv.Itoa(roleID)},
+		})
+	}
+	owner := p.int("owner_id")
+	writeJSON(w, http.StatusOK, record{
+		"owner":                record{"id": owner, "name": "User " + strconv.Itoa(owner), "slug": "user-" + strconv.Itoa(owner)},
+		"role_permissions":     roles,
+		"fallback_permissions": p["fallback_permissions"],
+	})
+}
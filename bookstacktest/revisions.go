@@ -0,0 +1,72 @@
+package bookstacktest
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// saveRevision records the current content of a page as a new revision.
+// s.mu must be held.
+func (s *Server) saveRevision(page record, summary string) {
+	s.revisions.insert(record{
+		"page_id":         page.int("id"),
+		"name":            page.str("name"),
+		"slug":            page.str("slug"),
+		"book_slug":       s.bookSlug(page.int("book_id")),
+		"revision_number": page.int("revision_count"),
+		"summary":         summary,
+		"type":            "version",
+		"html":            page.str("html"),
+		"markdown":        page.str("markdown"),
+		"created_at":      formatTime(s.now()),
+		"created_by":      page.int("updated_by"),
+	})
+}
+
+// revisionsOf returns the revisions of a page, oldest first. s.mu must be held.
+func (s *Server) revisionsOf(pageID int) []record {
+	var out []record
+	for _, rev := range s.revisions.sorted() {
+		if rev.int("page_id") == pageID {
+			out = append(out, rev)
+		}
+	}
+	return out
+}
+
+// handleRevisions serves GET /api/pages/{id}/revisions.
+func (s *Server) handleRevisions(w http.ResponseWriter, r *http.Request, pageID int) {
+	lq, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data[resPages].items[pageID]; !ok {
+		writeError(w, http.StatusNotFound, notFoundMessage(resPages))
+		return
+	}
+	page, total := lq.apply(s.revisionsOf(pageID))
+	data := make([]record, len(page))
+	for i, rev := range page {
+		data[i] = rev.clone()
+		delete(data[i], "html")
+		delete(data[i], "markdown")
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data, "total": total})
+}
+
+// handleRevision serves GET /api/pages/{id}/revisions/{revision}.
+func (s *Server) handleRevision(w http.ResponseWriter, pageID int, rawID string) {
+	id, err := strconv.Atoi(rawID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rev, ok := s.revisions.items[id]
+	if err != nil || !ok || rev.int("page_id") != pageID {
+		writeError(w, http.StatusNotFound, "Revision not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, rev)
+}
@@ -0,0 +1,221 @@
+package bookstacktest
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	searchTypeFilter = regexp.MustCompile(`\{type:([a-z|]+)\}`)
+	searchTagFilter  = regexp.MustCompile(`\[([^\]=]+)(?:=([^\]]*))?\]`)
+	searchTerm       = regexp.MustCompile(`"([^"]+)"|(\S+)`)
+)
+
+// searchQuery is a parsed subset of Bookstack's search syntax: plain terms,
+// "exact phrases", {type:page|book} filters and [name=value] tag filters.
+type searchQuery struct {
+	terms []string
+	types map[string]bool
+	tags  [][2]string
+}
+
+func parseSearchQuery(q string) searchQuery {
+	var sq searchQuery
+	if m := searchTypeFilter.FindStringSubmatch(q); m != nil {
+		sq.types = make(map[string]bool)
+		for _, t := range strings.Split(m[1], "|") {
+			sq.types[t] = true
+		}
+		q = searchTypeFilter.ReplaceAllString(q, " ")
+	}
+	for _, m := range searchTagFilter.FindAllStringSubmatch(q, -1) {
+		sq.tags = append(sq.tags, [2]string{m[1], m[2]})
+	}
+	q = searchTagFilter.ReplaceAllString(q, " ")
+	for _, m := range searchTerm.FindAllStringSubmatch(q, -1) {
+		term := m[1] + m[2]
+		if strings.HasPrefix(term, "{") {
+			continue
+		}
+		sq.terms = append(sq.terms, strings.ToLower(term))
+	}
+	return sq
+}
+
+type searchHit struct {
+	typ   string
+	res   string
+	rec   record
+	text  string
+	score int
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, count := 1, 100
+	if v, err := strconv.Atoi(q.Get("page")); err == nil && v > 0 {
+		page = v
+	}
+	if v, err := strconv.Atoi(q.Get("count")); err == nil && v > 0 {
+		count = min(v, 100)
+	}
+	sq := parseSearchQuery(q.Get("query"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var hits []searchHit
+	sources := []struct{ typ, res, textField string }{
+		{"bookshelf", resShelves, "description"},
+		{"book", resBooks, "description"},
+		{"chapter", resChapters, "description"},
+		{"page", resPages, "html"},
+	}
+	for _, src := range sources {
+		if sq.types != nil && !sq.types[src.typ] {
+			continue
+		}
+		for _, rec := range s.data[src.res].sorted() {
+			if rec.bool("draft") || !hasTags(rec, sq.tags) {
+				continue
+			}
+			text := plainText(rec.str(src.textField))
+			score, ok := scoreMatch(sq.terms, rec.str("name"), text)
+			if !ok {
+				continue
+			}
+			hits = append(hits, searchHit{typ: src.typ, res: src.res, rec: rec, text: text, score: score})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b searchHit) int { return cmp.Compare(b.score, a.score) })
+
+	total := len(hits)
+	start := min((page-1)*count, total)
+	end := min(start+count, total)
+	data := make([]record, 0, end-start)
+	for _, h := range hits[start:end] {
+		data = append(data, s.searchResult(h, sq.terms))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data, "total": total})
+}
+
+// scoreMatch reports whether all terms appear in name or text, and a score
+// counting occurrences, with name matches weighted higher.
+func scoreMatch(terms []string, name, text string) (int, bool) {
+	name, text = strings.ToLower(name), strings.ToLower(text)
+	score := 0
+	for _, t := range terms {
+		n := strings.Count(name, t)*5 + strings.Count(text, t)
+		if n == 0 {
+			return 0, false
+		}
+		score += n
+	}
+	return max(score, 1), true
+}
+
+func hasTags(rec record, want [][2]string) bool {
+	tags, _ := rec["tags"].([]any)
+	for _, w := range want {
+		found := false
+		for _, t := range tags {
+			m, _ := t.(map[string]any)
+			name, _ := m["name"].(string)
+			value, _ := m["value"].(string)
+			if strings.EqualFold(name, w[0]) && (w[1] == "" || strings.EqualFold(value, w[1])) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// searchResult builds a search result item for a hit. s.mu must be held.
+func (s *Server) searchResult(h searchHit, terms []string) record {
+	rec := h.rec
+	out := record{
+		"type":         h.typ,
+		"id":           rec["id"],
+		"name":         rec["name"],
+		"slug":         rec["slug"],
+		"created_at":   rec["created_at"],
+		"updated_at":   rec["updated_at"],
+		"tags":         tagsOrEmpty(rec["tags"]),
+		"url":          s.webURL(h.res, rec),
+		"preview_html": map[string]string{"name": highlight(rec.str("name"), terms), "content": highlight(excerpt(h.text, terms), terms)},
+	}
+	if h.res == resChapters || h.res == resPages {
+		out["book_id"] = rec["book_id"]
+	}
+	if h.res == resPages {
+		out["chapter_id"] = rec["chapter_id"]
+	}
+	return out
+}
+
+// webURL returns the web URL of a record. s.mu must be held.
+func (s *Server) webURL(res string, rec record) string {
+	switch res {
+	case resShelves:
+		return s.URL + "/shelves/" + rec.str("slug")
+	case resBooks:
+		return s.URL + "/books/" + rec.str("slug")
+	case resChapters:
+		return fmt.Sprintf("%s/books/%s/chapter/%s", s.URL, s.bookSlug(rec.int("book_id")), rec.str("slug"))
+	default:
+		return fmt.Sprintf("%s/books/%s/page/%s", s.URL, s.bookSlug(rec.int("book_id")), rec.str("slug"))
+	}
+}
+
+// excerpt returns up to about 200 characters of text around the first term.
+func excerpt(text string, terms []string) string {
+	start := 0
+	lower := strings.ToLower(text)
+	for _, t := range terms {
+		if i := strings.Index(lower, t); i >= 0 {
+			start = max(0, i-50)
+			break
+		}
+	}
+	end := min(len(text), start+200)
+	for start > 0 && !isRuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !isRuneStart(text[end]) {
+		end++
+	}
+	return text[start:end]
+}
+
+func isRuneStart(b byte) bool { return b&0xC0 != 0x80 }
+
+// highlight HTML-escapes s and wraps each occurrence of a term in <strong>.
+func highlight(s string, terms []string) string {
+	if len(terms) == 0 {
+		return html.EscapeString(s)
+	}
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	re := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(s, -1) {
+		b.WriteString(html.EscapeString(s[last:loc[0]]))
+		b.WriteString("<strong>" + html.EscapeString(s[loc[0]:loc[1]]) + "</strong>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(s[last:]))
+	return b.String()
+}
@@ -0,0 +1,169 @@
+package bookstacktest
+
+import (
+	"path/filepath"
+	"strings"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/markup"
+)
+
+// seed stores v as a new record of type res, filling in IDs, slugs and
+// timestamps that were left empty, and returns the stored form.
+func seed[T any](s *Server, res string, v T, fill func(record)) *T {
+	rec := toRecord(v)
+	now := formatTime(s.now())
+	for _, key := range []string{"created_at", "updated_at"} {
+		if _, ok := rec[key]; ok && rec.isZeroTime(key) {
+			rec[key] = now
+		}
+	}
+	if _, ok := rec["slug"]; ok && rec.str("slug") == "" {
+		rec["slug"] = slugify(rec.str("name"))
+	}
+	if fill != nil {
+		fill(rec)
+	}
+	return decode[T](s.readView(res, s.data[res].insert(rec)))
+}
+
+// AddBook stores a book. A zero ID is assigned automatically; empty slugs
+// and timestamps are derived from the name and the server clock.
+func (s *Server) AddBook(b bookstack.Book) *bookstack.Book {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return seed(s, resBooks, b, nil)
+}
+
+// AddChapter stores a chapter. The referenced book should already exist.
+func (s *Server) AddChapter(ch bookstack.Chapter) *bookstack.Chapter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return seed(s, resChapters, ch, func(rec record) {
+		delete(rec, "book_slug")
+		if ch.Priority == 0 {
+			rec["priority"] = s.nextPriority(ch.BookID)
+		}
+	})
+}
+
+// AddPage stores a page along with a revision of its content, unless it
+// is a draft. If ChapterID is set, BookID is taken from the chapter; if
+// only Markdown is given, HTML is rendered from it.
+func (s *Server) AddPage(p bookstack.Page) *bookstack.Page {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.data[resChapters].items[p.ChapterID]; ok {
+		p.BookID = ch.int("book_id")
+	}
+	page := seed(s, resPages, p, func(rec record) {
+		delete(rec, "book_slug")
+		if p.HTML == "" && p.Markdown != "" {
+			rec["html"] = markup.ToHTML(p.Markdown)
+		}
+		if p.Revision == 0 && !p.Draft {
+			rec["revision_count"] = 1
+		}
+		if p.Priority == 0 {
+			rec["priority"] = s.nextPriority(p.BookID)
+		}
+	})
+	if !p.Draft {
+		s.saveRevision(s.data[resPages].items[page.ID], "")
+	}
+	return page
+}
+
+// AddShelf stores a shelf containing the given books.
+func (s *Server) AddShelf(sh bookstack.Shelf, bookIDs ...int) *bookstack.Shelf {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return seed(s, resShelves, sh, func(rec record) {
+		rec["books"] = append([]int(nil), bookIDs...)
+	})
+}
+
+// AddAttachment stores an attachment. Content holds the link target for
+// external attachments and base64-encoded file data otherwise.
+func (s *Server) AddAttachment(a bookstack.Attachment) *bookstack.Attachment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return seed(s, resAttachments, a, nil)
+}
+
+// AddComment stores a comment.
+func (s *Server) AddComment(c bookstack.Comment) *bookstack.Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return seed(s, resComments, c, nil)
+}
+
+// AddImage stores a gallery image with the given content, serving it at
+// img.Path (derived from the name when empty).
+func (s *Server) AddImage(img bookstack.Image, data []byte) *bookstack.Image {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return seed(s, resImages, img, func(rec record) {
+		if img.Type == "" {
+			rec["type"] = "gallery"
+		}
+		if img.Path == "" {
+			ext := filepath.Ext(img.Name)
+			rec["path"] = "/uploads/images/gallery/" + slugify(strings.TrimSuffix(img.Name, ext)) + ext
+		}
+		rec["url"] = s.URL + rec.str("path")
+		s.uploads[rec.str("path")] = append([]byte(nil), data...)
+	})
+}
+
+// get returns the stored form of a record as a typed value.
+func get[T any](s *Server, res string, id int) (*T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.data[res].items[id]
+	if !ok {
+		return nil, false
+	}
+	return decode[T](s.readView(res, rec)), true
+}
+
+// Book returns the stored book with the given ID.
+func (s *Server) Book(id int) (*bookstack.Book, bool) { return get[bookstack.Book](s, resBooks, id) }
+
+// Chapter returns the stored chapter with the given ID.
+func (s *Server) Chapter(id int) (*bookstack.Chapter, bool) {
+	return get[bookstack.Chapter](s, resChapters, id)
+}
+
+// Page returns the stored page with the given ID.
+func (s *Server) Page(id int) (*bookstack.Page, bool) { return get[bookstack.Page](s, resPages, id) }
+
+// Shelf returns the stored shelf with the given ID.
+func (s *Server) Shelf(id int) (*bookstack.Shelf, bool) {
+	return get[bookstack.Shelf](s, resShelves, id)
+}
+
+// Attachment returns the stored attachment with the given ID.
+func (s *Server) Attachment(id int) (*bookstack.Attachment, bool) {
+	return get[bookstack.Attachment](s, resAttachments, id)
+}
+
+// Comment returns the stored comment with the given ID.
+func (s *Server) Comment(id int) (*bookstack.Comment, bool) {
+	return get[bookstack.Comment](s, resComments, id)
+}
+
+// Image returns the stored gallery image with the given ID.
+func (s *Server) Image(id int) (*bookstack.Image, bool) {
+	return get[bookstack.Image](s, resImages, id)
+}
+
+// Count returns the number of stored items of a resource, such as "pages".
+func (s *Server) Count(resource string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.data[resource]; ok {
+		return len(c.items)
+	}
+	return 0
+}
@@ -0,0 +1,236 @@
+// Package bookstacktest provides an in-memory Bookstack API emulator for tests.
+//
+// A Server behaves like a small Bookstack instance: it stores books, chapters,
+// pages with their revisions, shelves, attachments and comments, supports
+// token authentication, the count/offset/sort/filter list semantics and
+// full-text search, and can inject failures and latency:
+//
+//	srv := bookstacktest.NewServer()
+//	defer srv.Close()
+//
+//	book := srv.AddBook(bookstack.Book{Name: "Operations"})
+//	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", HTML: "<p>Steps</p>"})
+//
+//	client := srv.Client()
+//	pages, err := client.Pages.List(ctx, nil)
+package bookstacktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+// Default credentials accepted by a Server.
+const (
+	DefaultTokenID     = "test-token-id"
+	DefaultTokenSecret = "test-token-secret"
+)
+
+// apiUserID is the ID of the user the API token belongs to. Records created
+// through the API are attributed to it, and only its drafts are listed.
+const apiUserID = 1
+
+// Server is a stateful, in-memory emulation of the Bookstack REST API.
+// All methods are safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the running server, suitable for Config.BaseURL.
+	URL string
+
+	srv *httptest.Server
+
+	mu          sync.Mutex
+	tokenID     string
+	tokenSecret string
+	now         func() time.Time
+	latency     time.Duration
+	failures    []*Failure
+	requests    []string
+	data        map[string]*collection
+	uploads     map[string][]byte
+	permissions map[string]record // Keyed by resource and ID, see permissionKey
+	revisions   *collection       // Page revisions
+}
+
+// Failure describes an injected error response.
+type Failure struct {
+	Method     string // HTTP method to match; empty matches any
+	Path       string // Path prefix to match, e.g. "/api/pages"; empty matches any
+	Status     int    // Status code to return
+	Times      int    // Number of matching requests to fail; 0 fails all of them
+	RetryAfter int    // Value of the Retry-After header in seconds, if non-zero
+}
+
+// NewServer starts a new empty Server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		tokenID:     DefaultTokenID,
+		tokenSecret: DefaultTokenSecret,
+		now:         func() time.Time { return time.Now().UTC() },
+		data:        make(map[string]*collection),
+		uploads:     make(map[string][]byte),
+		permissions: make(map[string]record),
+		revisions:   newCollection(),
+	}
+	for _, name := range resourceNames {
+		s.data[name] = newCollection()
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Config returns a client configuration pointing at the server.
+func (s *Server) Config() bookstack.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return bookstack.Config{
+		BaseURL:     s.URL,
+		TokenID:     s.tokenID,
+		TokenSecret: s.tokenSecret,
+		HTTPClient:  s.srv.Client(),
+	}
+}
+
+// Client returns a new client connected to the server.
+func (s *Server) Client() *bookstack.Client {
+	c, err := bookstack.NewClient(s.Config())
+	if err != nil {
+		panic("bookstacktest: " + err.Error())
+	}
+	return c
+}
+
+// SetToken changes the credentials the server accepts.
+func (s *Server) SetToken(id, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenID, s.tokenSecret = id, secret
+}
+
+// SetClock replaces the time source used for created_at and updated_at.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// InjectFailure makes matching requests fail with f.Status.
+// Failures are checked in the order they were added.
+func (s *Server) InjectFailure(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// AddUpload makes data available at path, such as
+// "/uploads/images/gallery/2024-01/diagram.png", for authenticated GET requests.
+func (s *Server) AddUpload(path string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads[path] = append([]byte(nil), data...)
+}
+
+// ClearFailures removes all injected failures.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// Requests returns the requests received so far as "METHOD /path?query" strings.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// ResetRequests clears the request log.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+	latency := s.latency
+	failure := s.matchFailure(r)
+	authorized := r.Header.Get("Authorization") == "Token "+s.tokenID+":"+s.tokenSecret
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if failure != nil {
+		if failure.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(failure.RetryAfter))
+		}
+		writeError(w, failure.Status, http.StatusText(failure.Status))
+		return
+	}
+	if !authorized {
+		writeError(w, http.StatusUnauthorized, "No authorization token found on the request")
+		return
+	}
+
+	s.route(w, r)
+}
+
+// matchFailure returns the first injected failure matching r, consuming one
+// use of it. s.mu must be held.
+func (s *Server) matchFailure(r *http.Request) *Failure {
+	for i, f := range s.failures {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i:i], s.failures[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// writeJSON writes v as a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in Bookstack's JSON error format.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{"code": status, "message": message},
+	})
+}
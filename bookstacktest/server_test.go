@@ -0,0 +1,490 @@
+package bookstacktest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestServer_Auth(t *testing.T) {
+	srv := newTestServer(t)
+	cfg := srv.Config()
+	cfg.TokenSecret = "wrong"
+	c, err := bookstack.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	_, err = c.Books.List(context.Background(), nil)
+	if !errors.Is(err, bookstack.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestServer_PageCRUD(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	book := srv.AddBook(bookstack.Book{Name: "Operations"})
+	ch := srv.AddChapter(bookstack.Chapter{BookID: book.ID, Name: "Runbooks"})
+
+	page, err := c.Pages.Create(ctx, &bookstack.PageCreateRequest{
+		ChapterID: ch.ID,
+		Name:      "Deploy Guide",
+		Markdown:  "# Deploy\n\nRun the script.",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if page.BookID != book.ID || page.Slug != "deploy-guide" || page.Revision != 1 {
+		t.Errorf("created page = %+v", page)
+	}
+
+	page, err = c.Pages.Update(ctx, page.ID, &bookstack.PageUpdateRequest{HTML: "<p>New</p>"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if page.HTML != "<p>New</p>" || page.Revision != 2 {
+		t.Errorf("updated page = %+v", page)
+	}
+
+	got, err := c.Pages.GetBySlug(ctx, "operations", "deploy-guide")
+	if err != nil {
+		t.Fatalf("GetBySlug: %v", err)
+	}
+	if got.ID != page.ID || got.BookSlug != "operations" {
+		t.Errorf("GetBySlug = %+v", got)
+	}
+
+	if err := c.Pages.Delete(ctx, page.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := c.Pages.Get(ctx, page.ID); !errors.Is(err, bookstack.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestServer_ListSemantics(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	for i := 1; i <= 250; i++ {
+		srv.AddBook(bookstack.Book{Name: fmt.Sprintf("Book %03d", i)})
+	}
+
+	books, err := c.Books.List(ctx, &bookstack.ListOptions{Count: 5, Offset: 10, Sort: "-name"})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(books) != 5 || books[0].Name != "Book 240" {
+		t.Errorf("got %d books starting at %q", len(books), books[0].Name)
+	}
+
+	books, err = c.Books.List(ctx, &bookstack.ListOptions{Filter: map[string]string{"name:like": "Book 12%"}})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(books) != 10 {
+		t.Errorf("like filter matched %d books, want 10", len(books))
+	}
+
+	n := 0
+	for _, err := range c.Books.ListAll(ctx) {
+		if err != nil {
+			t.Fatalf("ListAll: %v", err)
+		}
+		n++
+	}
+	if n != 250 {
+		t.Errorf("ListAll yielded %d books, want 250", n)
+	}
+}
+
+func TestServer_FilterUpdatedAt(t *testing.T) {
+	srv := newTestServer(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv.AddBook(bookstack.Book{Name: "Old", UpdatedAt: base})
+	srv.AddBook(bookstack.Book{Name: "New", UpdatedAt: base.Add(48 * time.Hour)})
+
+	books, err := srv.Client().Books.List(context.Background(), &bookstack.ListOptions{
+		Filter: map[string]string{"updated_at:gt": "2024-01-02"},
+	})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(books) != 1 || books[0].Name != "New" {
+		t.Errorf("got %+v, want only New", books)
+	}
+}
+
+func TestServer_Search(t *testing.T) {
+	srv := newTestServer(t)
+	book := srv.AddBook(bookstack.Book{Name: "Operations"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", HTML: "<p>How to deploy the app</p>"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Backups", HTML: "<p>Nightly</p>"})
+
+	resp, err := srv.Client().Search.SearchPage(context.Background(), "deploy {type:page}", 1, 10)
+	if err != nil {
+		t.Fatalf("SearchPage: %v", err)
+	}
+	if resp.Total != 1 || resp.Results[0].Name != "Deploy" {
+		t.Fatalf("got %+v", resp)
+	}
+	r := resp.Results[0]
+	if r.URL != srv.URL+"/books/operations/page/deploy" {
+		t.Errorf("URL = %q", r.URL)
+	}
+	if bookstack.PreviewText(r.PreviewHTML.Content) != "How to deploy the app" {
+		t.Errorf("preview = %q", r.PreviewHTML.Content)
+	}
+}
+
+func TestServer_InjectFailure(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	srv.InjectFailure(Failure{Path: "/api/books", Status: http.StatusTooManyRequests, Times: 1, RetryAfter: 5})
+
+	if _, err := c.Books.List(ctx, nil); !errors.Is(err, bookstack.ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+	if _, err := c.Books.List(ctx, nil); err != nil {
+		t.Errorf("second request should succeed, got %v", err)
+	}
+	if got := len(srv.Requests()); got != 2 {
+		t.Errorf("recorded %d requests, want 2", got)
+	}
+}
+
+func TestServer_Latency(t *testing.T) {
+	srv := newTestServer(t)
+	srv.SetLatency(200 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := srv.Client().Books.List(ctx, nil); err == nil {
+		t.Error("expected timeout error")
+	}
+}
+
+func TestServer_CascadeDelete(t *testing.T) {
+	srv := newTestServer(t)
+	book := srv.AddBook(bookstack.Book{Name: "Ops"})
+	page := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "P"})
+	srv.AddAttachment(bookstack.Attachment{Name: "a", UploadedTo: page.ID, External: true, Content: "https://x"})
+	srv.AddComment(bookstack.Comment{PageID: page.ID, HTML: "<p>hi</p>"})
+	srv.AddShelf(bookstack.Shelf{Name: "Shelf"}, book.ID)
+
+	if err := srv.Client().Pages.Delete(context.Background(), page.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if srv.Count("attachments") != 0 || srv.Count("comments") != 0 {
+		t.Error("page dependents were not deleted")
+	}
+}
+
+func TestServer_ContentPermissions(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	book := srv.AddBook(bookstack.Book{Name: "Secrets"})
+
+	p, err := c.Permissions.Get(ctx, bookstack.EntityBook, book.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !p.FallbackPermissions.Inheriting || len(p.RolePermissions) != 0 {
+		t.Errorf("default permissions = %+v", p)
+	}
+
+	_, err = c.Permissions.Update(ctx, bookstack.EntityBook, book.ID, &bookstack.ContentPermissionsUpdateRequest{
+		OwnerID:             4,
+		RolePermissions:     []bookstack.RolePermission{{RoleID: 2, View: true, Update: true}},
+		FallbackPermissions: &bookstack.FallbackPermissions{View: false},
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	p, err = c.Permissions.Get(ctx, bookstack.EntityBook, book.ID)
+	if err != nil {
+		t.Fatalf("Get after update: %v", err)
+	}
+	if p.Owner.ID != 4 || p.FallbackPermissions.Inheriting || len(p.RolePermissions) != 1 || !p.RolePermissions[0].Update {
+		t.Errorf("permissions = %+v", p)
+	}
+
+	if _, err := c.Permissions.Get(ctx, bookstack.EntityPage, 999); !errors.Is(err, bookstack.ErrNotFound) {
+		t.Errorf("missing item: %v", err)
+	}
+}
+
+func TestServer_Revisions(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	book := srv.AddBook(bookstack.Book{Name: "Operations"})
+	page := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", Markdown: "# Deploy\n\nRun make."})
+
+	if _, err := c.Pages.Update(ctx, page.ID, &bookstack.PageUpdateRequest{Markdown: "# Deploy\n\nRun make deploy."}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	revs, err := c.Revisions.List(ctx, page.ID, nil)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(revs) != 2 || revs[0].Number != 1 || revs[1].Number != 2 || revs[1].Markdown != "" {
+		t.Fatalf("revisions = %+v", revs)
+	}
+
+	first, err := c.Revisions.Get(ctx, page.ID, revs[0].ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if first.Markdown != "# Deploy\n\nRun make." || first.BookSlug != "operations" {
+		t.Errorf("first revision = %+v", first)
+	}
+
+	d, err := c.Revisions.Diff(ctx, page.ID, revs[0].ID, revs[1].ID, bookstack.DiffMarkdown)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if ins, del := d.Stats(); ins != 1 || del != 1 {
+		t.Errorf("Stats = %d, %d\n%s", ins, del, d.Unified(1))
+	}
+
+	if _, err := c.Revisions.Get(ctx, page.ID+1, revs[0].ID); !errors.Is(err, bookstack.ErrNotFound) {
+		t.Errorf("revision of another page: err = %v", err)
+	}
+	if err := c.Pages.Delete(ctx, page.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Revisions.List(ctx, page.ID, nil); !errors.Is(err, bookstack.ErrNotFound) {
+		t.Errorf("revisions of deleted page: err = %v", err)
+	}
+}
+
+func TestServer_RevisionSummary(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	book := srv.AddBook(bookstack.Book{Name: "Operations"})
+	page := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", Markdown: "v1"})
+
+	if _, err := c.Pages.UpdateIfUnchanged(ctx, page, &bookstack.PageUpdateRequest{Markdown: "v2", Summary: "Second draft"}); err != nil {
+		t.Fatalf("UpdateIfUnchanged: %v", err)
+	}
+	revs, err := c.Revisions.List(ctx, page.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 || revs[1].Summary != "Second draft" {
+		t.Errorf("revisions = %+v", revs)
+	}
+
+	// page is now stale.
+	if _, err := c.Pages.UpdateIfUnchanged(ctx, page, &bookstack.PageUpdateRequest{Markdown: "v3"}); !errors.Is(err, bookstack.ErrConflict) {
+		t.Errorf("stale update: err = %v, want ErrConflict", err)
+	}
+	if p, _ := srv.Page(page.ID); p.Markdown != "v2" {
+		t.Errorf("stale update overwrote the page: %q", p.Markdown)
+	}
+}
+
+func TestServer_MoveAndCopy(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	ops := srv.AddBook(bookstack.Book{Name: "Operations"})
+	archive := srv.AddBook(bookstack.Book{Name: "Archive"})
+	runbooks := srv.AddChapter(bookstack.Chapter{BookID: ops.ID, Name: "Runbooks"})
+	first := srv.AddPage(bookstack.Page{ChapterID: runbooks.ID, Name: "Deploy", Markdown: "# Deploy", Tags: []bookstack.Tag{{Name: "team", Value: "ops"}}})
+	second := srv.AddPage(bookstack.Page{ChapterID: runbooks.ID, Name: "Rollback", Markdown: "# Rollback"})
+	srv.AddAttachment(bookstack.Attachment{Name: "Spec", UploadedTo: first.ID, External: true, Content: "https://example.com/spec"})
+
+	moved, err := c.Pages.Move(ctx, second.ID, bookstack.MoveTarget{BookID: ops.ID})
+	if err != nil {
+		t.Fatalf("Move page: %v", err)
+	}
+	if moved.ChapterID != 0 || moved.BookID != ops.ID {
+		t.Errorf("moved page = book %d, chapter %d", moved.BookID, moved.ChapterID)
+	}
+
+	cp, err := c.Chapters.Copy(ctx, runbooks.ID, bookstack.MoveTarget{BookID: archive.ID})
+	if err != nil {
+		t.Fatalf("Copy chapter: %v", err)
+	}
+	pages, err := c.Pages.List(ctx, &bookstack.ListOptions{Filter: map[string]string{"chapter_id": fmt.Sprint(cp.ID)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || pages[0].Name != "Deploy" || pages[0].BookID != archive.ID {
+		t.Fatalf("copied pages = %+v", pages)
+	}
+	copied, _ := srv.Page(pages[0].ID)
+	if copied.Markdown != "# Deploy" || len(copied.Tags) != 1 {
+		t.Errorf("copied page = %+v", copied)
+	}
+	atts, err := c.Attachments.List(ctx, &bookstack.ListOptions{Filter: map[string]string{"uploaded_to": fmt.Sprint(copied.ID)}})
+	if err != nil || len(atts) != 1 || atts[0].Name != "Spec" {
+		t.Errorf("copied attachments = %+v, %v", atts, err)
+	}
+
+	if _, err := c.Chapters.Move(ctx, runbooks.ID, bookstack.MoveTarget{BookID: archive.ID}); err != nil {
+		t.Fatalf("Move chapter: %v", err)
+	}
+	if p, _ := srv.Page(first.ID); p.BookID != archive.ID || p.ChapterID != runbooks.ID {
+		t.Errorf("page of moved chapter = book %d, chapter %d", p.BookID, p.ChapterID)
+	}
+}
+
+func TestServer_Reorder(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	ops := srv.AddBook(bookstack.Book{Name: "Operations"})
+	archive := srv.AddBook(bookstack.Book{Name: "Archive"})
+	setup := srv.AddChapter(bookstack.Chapter{BookID: ops.ID, Name: "Setup"})
+	install := srv.AddPage(bookstack.Page{ChapterID: setup.ID, Name: "Install"})
+	intro := srv.AddPage(bookstack.Page{BookID: ops.ID, Name: "Intro"})
+	old := srv.AddChapter(bookstack.Chapter{BookID: archive.ID, Name: "Legacy"})
+	legacy := srv.AddPage(bookstack.Page{ChapterID: old.ID, Name: "Legacy setup"})
+
+	layout := []bookstack.LayoutItem{
+		{PageID: intro.ID},
+		{ChapterID: setup.ID, Pages: []int{install.ID}},
+		{ChapterID: old.ID, Pages: []int{legacy.ID}},
+	}
+	if _, err := c.Books.Reorder(ctx, ops.ID, layout); err != nil {
+		t.Fatal(err)
+	}
+	if ch, _ := c.Chapters.Get(ctx, old.ID); ch.BookID != ops.ID {
+		t.Errorf("moved chapter book = %d", ch.BookID)
+	}
+
+	chapters, _ := c.Chapters.List(ctx, &bookstack.ListOptions{Filter: map[string]string{"book_id": fmt.Sprint(ops.ID)}})
+	pages, _ := c.Pages.List(ctx, &bookstack.ListOptions{Filter: map[string]string{"book_id": fmt.Sprint(ops.ID), "chapter_id": "0"}})
+	type entry struct {
+		name     string
+		priority int
+	}
+	var top []entry
+	for _, ch := range chapters {
+		top = append(top, entry{ch.Name, ch.Priority})
+	}
+	for _, p := range pages {
+		top = append(top, entry{p.Name, p.Priority})
+	}
+	slices.SortFunc(top, func(a, b entry) int { return a.priority - b.priority })
+	var order []string
+	for _, e := range top {
+		order = append(order, e.name)
+	}
+	if want := []string{"Intro", "Setup", "Legacy"}; !slices.Equal(order, want) {
+		t.Errorf("order = %q, want %q", order, want)
+	}
+
+	// Applying the same layout again changes nothing.
+	changes, err := c.Books.Reorder(ctx, ops.ID, layout)
+	if err != nil || len(changes) != 0 {
+		t.Errorf("second Reorder = %+v, %v", changes, err)
+	}
+}
+
+func TestServer_ReorderPageListedBeforeIncomingChapter(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	ops := srv.AddBook(bookstack.Book{Name: "Operations"})
+	archive := srv.AddBook(bookstack.Book{Name: "Archive"})
+	old := srv.AddChapter(bookstack.Chapter{BookID: archive.ID, Name: "Legacy"})
+	first := srv.AddPage(bookstack.Page{ChapterID: old.ID, Name: "First"})
+	second := srv.AddPage(bookstack.Page{ChapterID: old.ID, Name: "Second"})
+
+	// The chapter's first page is listed before the chapter, at the top level.
+	layout := []bookstack.LayoutItem{
+		{PageID: first.ID},
+		{ChapterID: old.ID, Pages: []int{second.ID}},
+	}
+	if _, err := c.Books.Reorder(ctx, ops.ID, layout); err != nil {
+		t.Fatalf("Reorder: %v", err)
+	}
+	if p, _ := srv.Page(first.ID); p.BookID != ops.ID || p.ChapterID != 0 {
+		t.Errorf("first page = book %d, chapter %d", p.BookID, p.ChapterID)
+	}
+	if p, _ := srv.Page(second.ID); p.BookID != ops.ID || p.ChapterID != old.ID {
+		t.Errorf("second page = book %d, chapter %d", p.BookID, p.ChapterID)
+	}
+}
+
+func TestServer_Templates(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	book := srv.AddBook(bookstack.Book{Name: "Meetings"})
+	tmpl := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Meeting template", HTML: "<h1>{{name}}</h1><p>{{date}}</p>"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Other"})
+
+	if _, err := c.Templates.Mark(ctx, tmpl.ID); err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+	templates, err := c.Templates.List(ctx, nil)
+	if err != nil || len(templates) != 1 || templates[0].ID != tmpl.ID {
+		t.Fatalf("List = %+v, %v", templates, err)
+	}
+	// Filter values are compared as written, as in Bookstack's database.
+	if pages, _ := c.Pages.List(ctx, &bookstack.ListOptions{Filter: map[string]string{"template": "true"}}); len(pages) != 0 {
+		t.Errorf("filter template=true matched %+v", pages)
+	}
+
+	page, err := c.Templates.Instantiate(ctx, tmpl.ID, &bookstack.TemplateRequest{
+		BookID: book.ID,
+		Name:   "Standup {{date}}",
+		Now:    time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Instantiate: %v", err)
+	}
+	got, _ := srv.Page(page.ID)
+	if got.Name != "Standup 2024-05-01" || got.HTML != "<h1>Standup 2024-05-01</h1><p>2024-05-01</p>" || got.Template {
+		t.Errorf("instantiated page = %+v", got)
+	}
+
+	if _, err := c.Templates.Unmark(ctx, tmpl.ID); err != nil {
+		t.Fatalf("Unmark: %v", err)
+	}
+	if templates, _ := c.Templates.List(ctx, nil); len(templates) != 0 {
+		t.Errorf("templates after Unmark = %+v", templates)
+	}
+}
+
+func TestServer_Drafts(t *testing.T) {
+	srv := newTestServer(t)
+	c := srv.Client()
+	ctx := context.Background()
+	book := srv.AddBook(bookstack.Book{Name: "Handbook"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Published"})
+	own := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Proposal", Draft: true, CreatedBy: 1})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Other", Draft: true, CreatedBy: 2})
+
+	if own.Revision != 0 {
+		t.Errorf("draft revision = %d, want 0", own.Revision)
+	}
+	drafts, err := c.Drafts.List(ctx, nil)
+	if err != nil || len(drafts) != 1 || drafts[0].ID != own.ID {
+		t.Fatalf("List = %+v, %v", drafts, err)
+	}
+
+}
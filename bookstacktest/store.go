@@ -0,0 +1,337 @@
+package bookstacktest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resource names, matching the API path segments.
+const (
+	resBooks       = "books"
+	resChapters    = "chapters"
+	resPages       = "pages"
+	resShelves     = "shelves"
+	resAttachments = "attachments"
+	resComments    = "comments"
+	resImages      = "image-gallery"
+)
+
+var resourceNames = []string{resBooks, resChapters, resPages, resShelves, resAttachments, resComments, resImages}
+
+// timeLayout matches the timestamp format used by the Bookstack API.
+const timeLayout = "2006-01-02T15:04:05.000000Z"
+
+// record is a stored item in its JSON object form.
+type record map[string]any
+
+// collection holds the records of one resource type.
+type collection struct {
+	nextID int
+	items  map[int]record
+}
+
+func newCollection() *collection {
+	return &collection{nextID: 1, items: make(map[int]record)}
+}
+
+// insert stores rec, assigning an ID if it has none.
+func (c *collection) insert(rec record) record {
+	id := rec.int("id")
+	if id == 0 {
+		id = c.nextID
+		rec["id"] = id
+	}
+	c.nextID = max(c.nextID, id+1)
+	c.items[id] = rec
+	return rec
+}
+
+// sorted returns all records ordered by ID.
+func (c *collection) sorted() []record {
+	ids := make([]int, 0, len(c.items))
+	for id := range c.items {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	out := make([]record, len(ids))
+	for i, id := range ids {
+		out[i] = c.items[id]
+	}
+	return out
+}
+
+// toRecord converts a value to its JSON object form.
+func toRecord(v any) record {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic("bookstacktest: " + err.Error())
+	}
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		panic("bookstacktest: " + err.Error())
+	}
+	return rec
+}
+
+// decode converts a record into a typed value.
+func decode[T any](rec record) *T {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		panic("bookstacktest: " + err.Error())
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		panic("bookstacktest: " + err.Error())
+	}
+	return &v
+}
+
+// clone returns a shallow copy of the record.
+func (r record) clone() record {
+	out := make(record, len(r))
+	for k, v := range r {
+		out[k] = v
+	}
+	return out
+}
+
+func (r record) int(key string) int {
+	switch v := r[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return int(n)
+	}
+	return 0
+}
+
+func (r record) str(key string) string {
+	s, _ := r[key].(string)
+	return s
+}
+
+func (r record) bool(key string) bool {
+	b, _ := r[key].(bool)
+	return b
+}
+
+// isZeroTime reports whether a timestamp field is unset.
+func (r record) isZeroTime(key string) bool {
+	s := r.str(key)
+	return s == "" || strings.HasPrefix(s, "0001-01-01")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+var slugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify converts a name to a Bookstack-style URL slug.
+func slugify(name string) string {
+	s := strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if s == "" {
+		return "untitled"
+	}
+	return s
+}
+
+// listQuery holds the parsed count/offset/sort/filter parameters of a list request.
+type listQuery struct {
+	count   int
+	offset  int
+	sort    string
+	filters []filter
+}
+
+type filter struct {
+	field string
+	op    string
+	value string
+}
+
+// parseListQuery parses list parameters using the API's defaults and limits.
+func parseListQuery(q url.Values) (listQuery, error) {
+	lq := listQuery{count: 100}
+	if v := q.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return lq, fmt.Errorf("the count must be a non-negative integer")
+		}
+		lq.count = min(n, 500)
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return lq, fmt.Errorf("the offset must be a non-negative integer")
+		}
+		lq.offset = n
+	}
+	lq.sort = q.Get("sort")
+	for key, vals := range q {
+		inner, ok := strings.CutPrefix(key, "filter[")
+		if !ok || !strings.HasSuffix(inner, "]") || len(vals) == 0 {
+			continue
+		}
+		inner = strings.TrimSuffix(inner, "]")
+		field, op, found := strings.Cut(inner, ":")
+		if !found {
+			op = "eq"
+		}
+		switch op {
+		case "eq", "ne", "gt", "lt", "gte", "lte", "like":
+		default:
+			return lq, fmt.Errorf("unsupported filter operation %q", op)
+		}
+		lq.filters = append(lq.filters, filter{field: field, op: op, value: vals[0]})
+	}
+	return lq, nil
+}
+
+// apply filters, sorts and pages recs, returning the page and the total
+// number of matching records.
+func (lq listQuery) apply(recs []record) ([]record, int) {
+	var matched []record
+	for _, rec := range recs {
+		if lq.matches(rec) {
+			matched = append(matched, rec)
+		}
+	}
+
+	if lq.sort != "" {
+		field := strings.TrimLeft(lq.sort, "+- ")
+		desc := strings.HasPrefix(lq.sort, "-")
+		slices.SortStableFunc(matched, func(a, b record) int {
+			c := compareValues(a[field], b[field])
+			if desc {
+				return -c
+			}
+			return c
+		})
+	}
+
+	total := len(matched)
+	start := min(lq.offset, total)
+	end := min(start+lq.count, total)
+	return matched[start:end], total
+}
+
+func (lq listQuery) matches(rec record) bool {
+	for _, f := range lq.filters {
+		v, ok := rec[f.field]
+		if !ok {
+			return false
+		}
+		if f.op == "like" {
+			if !likeMatch(fmt.Sprint(v), f.value) {
+				return false
+			}
+			continue
+		}
+		c := compareValues(v, f.value)
+		var ok2 bool
+		switch f.op {
+		case "eq":
+			ok2 = c == 0
+		case "ne":
+			ok2 = c != 0
+		case "gt":
+			ok2 = c > 0
+		case "lt":
+			ok2 = c < 0
+		case "gte":
+			ok2 = c >= 0
+		case "lte":
+			ok2 = c <= 0
+		}
+		if !ok2 {
+			return false
+		}
+	}
+	return true
+}
+
+// compareValues compares a stored value with another stored value or a
+// query string, numerically when both are numbers and as strings otherwise.
+func compareValues(a, b any) int {
+	as, bs := valueString(a), valueString(b)
+	if at, ok := parseTime(as); ok {
+		if bt, ok := parseTime(bs); ok {
+			return at.Compare(bt)
+		}
+	}
+	af, aerr := strconv.ParseFloat(as, 64)
+	bf, berr := strconv.ParseFloat(bs, 64)
+	if aerr == nil && berr == nil {
+		return cmp.Compare(af, bf)
+	}
+	return strings.Compare(strings.ToLower(as), strings.ToLower(bs))
+}
+
+// parseTime parses the timestamp formats accepted in filters.
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func valueString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// boolValue interprets a request body field as a boolean, accepting JSON
+// booleans and the "true"/"1" strings sent by Bookstack's forms.
+func boolValue(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return v == "true" || v == "1"
+	default:
+		return valueString(v) == "1"
+	}
+}
+
+// likeMatch implements SQL LIKE matching with % and _ wildcards, case-insensitively.
+func likeMatch(s, pattern string) bool {
+	var re strings.Builder
+	re.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			re.WriteString(".*")
+		case '_':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	re.WriteString("$")
+	ok, _ := regexp.MatchString(re.String(), s)
+	return ok
+}
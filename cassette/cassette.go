@@ -0,0 +1,363 @@
+// Package cassette records HTTP interactions with a Bookstack instance and
+// replays them later without network access.
+//
+// A Recorder is an http.RoundTripper, so it plugs into Config.HTTPClient:
+//
+//	rec, err := cassette.New("testdata/staging.json", cassette.Options{
+//	    Mode: cassette.Auto,
+//	})
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer rec.Stop()
+//
+//	client, err := bookstack.NewClient(bookstack.Config{
+//	    BaseURL:     "https://staging.example.com",
+//	    TokenID:     cmp.Or(os.Getenv("BOOKSTACK_TOKEN_ID"), "replay"),
+//	    TokenSecret: cmp.Or(os.Getenv("BOOKSTACK_TOKEN_SECRET"), "replay"),
+//	    HTTPClient:  rec.Client(),
+//	})
+//
+// Recorded requests never contain the Authorization header, and every
+// occurrence of the token ID and secret sent in it, as well as of the
+// Redact values, is replaced before the cassette is written.
+// Requests are matched by method, path and normalized query string.
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode selects whether a Recorder talks to the network.
+type Mode int
+
+const (
+	// Replay serves responses from the cassette and never uses the network.
+	Replay Mode = iota
+	// Record forwards requests to the network and records every interaction,
+	// replacing any existing cassette on Stop.
+	Record
+	// Auto replays if the cassette file exists and records otherwise.
+	Auto
+)
+
+// Version is the cassette file format version written by this package.
+const Version = 1
+
+// redacted replaces secret values in recorded interactions.
+const redacted = "REDACTED"
+
+// ErrNoInteraction is returned in Replay mode when no recorded interaction
+// matches a request.
+var ErrNoInteraction = errors.New("cassette: no recorded interaction matches request")
+
+// scrubbedHeaders are never written to a cassette.
+var scrubbedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Options configures a Recorder.
+type Options struct {
+	// Mode selects recording or replaying. The default is Replay.
+	Mode Mode
+
+	// Transport performs real requests while recording.
+	// If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	// Redact lists secret values to be replaced wherever they appear in
+	// recorded URLs, headers and bodies. The token ID and secret of the
+	// Authorization header are redacted automatically.
+	Redact []string
+}
+
+// Cassette is the on-disk format of recorded interactions.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded form of an HTTP request.
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"` // Normalized; see NormalizeQuery
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is the recorded form of an HTTP response. Body holds UTF-8
+// content; binary bodies are stored base64-encoded in BodyBase64.
+type Response struct {
+	Status     int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 string      `json:"body_base64,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that records or replays interactions.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	redact    []string
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New creates a Recorder backed by the cassette file at path.
+// In Replay mode the file must exist.
+func New(path string, opts Options) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		mode:      opts.Mode,
+		transport: opts.Transport,
+		cassette:  Cassette{Version: Version},
+	}
+	if r.transport == nil {
+		r.transport = http.DefaultTransport
+	}
+	for _, s := range opts.Redact {
+		if s != "" {
+			r.redact = append(r.redact, s)
+		}
+	}
+
+	if r.mode == Auto {
+		r.mode = Record
+		if _, err := os.Stat(path); err == nil {
+			r.mode = Replay
+		}
+	}
+	if r.mode == Replay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("parsing cassette: %w", err)
+		}
+		if r.cassette.Version != Version {
+			return nil, fmt.Errorf("unsupported cassette version %d", r.cassette.Version)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Mode returns the effective mode, resolving Auto.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an HTTP client that uses the Recorder as its transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Stop writes the cassette file when recording. It is a no-op when replaying.
+func (r *Recorder) Stop() error {
+	if r.mode != Record {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("encoding cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("creating cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing cassette: %w", err)
+	}
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == Replay {
+		return r.replay(req)
+	}
+	return r.record(req)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+		reqBody = data
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	r.redactToken(req.Header.Get("Authorization"))
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	in := Interaction{
+		Request: Request{
+			Method: req.Method,
+			Path:   r.scrub(req.URL.Path),
+			Query:  r.scrub(NormalizeQuery(req.URL.RawQuery)),
+			Header: r.scrubHeader(req.Header),
+			Body:   r.scrub(string(reqBody)),
+		},
+		Response: Response{
+			Status: resp.StatusCode,
+			Header: r.scrubHeader(resp.Header),
+		},
+	}
+	if utf8.Valid(respBody) {
+		in.Response.Body = r.scrub(string(respBody))
+	} else {
+		in.Response.BodyBase64 = base64.StdEncoding.EncodeToString(respBody)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// replay returns the first unused interaction matching req. Once all
+// matching interactions have been used, the last one is served again.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	path := r.scrub(req.URL.Path)
+	query := r.scrub(NormalizeQuery(req.URL.RawQuery))
+
+	r.mu.Lock()
+	match := -1
+	for i, in := range r.cassette.Interactions {
+		if in.Request.Method != req.Method || in.Request.Path != path || in.Request.Query != query {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.RequestURI())
+	}
+	r.used[match] = true
+	rec := r.cassette.Interactions[match].Response
+	r.mu.Unlock()
+
+	body := []byte(rec.Body)
+	if rec.BodyBase64 != "" {
+		data, err := base64.StdEncoding.DecodeString(rec.BodyBase64)
+		if err != nil {
+			return nil, fmt.Errorf("decoding recorded body: %w", err)
+		}
+		body = data
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// NormalizeQuery returns raw with parameters sorted by key and value, so
+// that equivalent queries, such as those built from ListOptions filter maps
+// in different orders, compare equal.
+func NormalizeQuery(raw string) string {
+	v, err := url.ParseQuery(raw)
+	if err != nil {
+		return raw
+	}
+	for _, vals := range v {
+		slices.Sort(vals)
+	}
+	return v.Encode()
+}
+
+// redactToken adds the token ID and secret of a Bookstack Authorization
+// header ("Token <id>:<secret>") to the redacted values.
+func (r *Recorder) redactToken(auth string) {
+	token, ok := strings.CutPrefix(auth, "Token ")
+	if !ok {
+		return
+	}
+	id, secret, _ := strings.Cut(token, ":")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range []string{id, secret} {
+		if s != "" && !slices.Contains(r.redact, s) {
+			r.redact = append(r.redact, s)
+		}
+	}
+}
+
+// scrub replaces every redacted value in s.
+func (r *Recorder) scrub(s string) string {
+	r.mu.Lock()
+	secrets := r.redact
+	r.mu.Unlock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+		if esc := url.QueryEscape(secret); esc != secret {
+			s = strings.ReplaceAll(s, esc, redacted)
+		}
+	}
+	return s
+}
+
+// scrubHeader copies h without sensitive headers and with redacted values replaced.
+func (r *Recorder) scrubHeader(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for k, vals := range h {
+		if slices.ContainsFunc(scrubbedHeaders, func(s string) bool { return strings.EqualFold(s, k) }) {
+			continue
+		}
+		for _, v := range vals {
+			out.Add(k, r.scrub(v))
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
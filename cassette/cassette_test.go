@@ -0,0 +1,137 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/bookstacktest"
+)
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Operations"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", HTML: "<p>Steps</p>"})
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cfg := srv.Config()
+	opts := &bookstack.ListOptions{Filter: map[string]string{"book_id": "1", "name": "Deploy"}}
+	ctx := context.Background()
+
+	rec, err := New(path, Options{
+		Mode:      Auto,
+		Transport: cfg.HTTPClient.Transport,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if rec.Mode() != Record {
+		t.Fatalf("Mode = %v, want Record for missing cassette", rec.Mode())
+	}
+	cfg.HTTPClient = rec.Client()
+	c, err := bookstack.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	pages, err := c.Pages.List(ctx, opts)
+	if err != nil || len(pages) != 1 {
+		t.Fatalf("List while recording: %v, %d pages", err, len(pages))
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading cassette: %v", err)
+	}
+	for _, secret := range []string{"Authorization", cfg.TokenID, cfg.TokenSecret} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	rec, err = New(path, Options{Mode: Auto})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if rec.Mode() != Replay {
+		t.Fatalf("Mode = %v, want Replay for existing cassette", rec.Mode())
+	}
+	c, _ = bookstack.NewClient(bookstack.Config{
+		BaseURL:     srv.URL,
+		TokenID:     "replay",
+		TokenSecret: "replay",
+		HTTPClient:  rec.Client(),
+	})
+	pages, err = c.Pages.List(ctx, &bookstack.ListOptions{Filter: map[string]string{"name": "Deploy", "book_id": "1"}})
+	if err != nil {
+		t.Fatalf("List while replaying: %v", err)
+	}
+	if len(pages) != 1 || pages[0].Name != "Deploy" {
+		t.Errorf("replayed pages = %+v", pages)
+	}
+
+	_, err = c.Books.List(ctx, nil)
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction, got %v", err)
+	}
+}
+
+func TestRecorder_RedactsTokenAndValues(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Echo the credentials, as an error page or debug endpoint might.
+		fmt.Fprintf(w, `{"auth":%q,"host":"wiki.internal"}`, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := New(path, Options{Mode: Record, Redact: []string{"wiki.internal"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	req, _ := http.NewRequest("GET", srv.URL+"/api/books?secret=s3cr%2Bt", nil)
+	req.Header.Set("Authorization", "Token abc123:s3cr+t")
+	resp, err := rec.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	resp.Body.Close()
+	if err := rec.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading cassette: %v", err)
+	}
+	for _, secret := range []string{"abc123", "s3cr+t", "s3cr%2Bt", "wiki.internal"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+}
+
+func TestRecorder_ReplayMissingFile(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), Options{Mode: Replay})
+	if err == nil {
+		t.Fatal("expected error for missing cassette")
+	}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	a := NormalizeQuery("filter%5Bname%5D=x&count=10&filter%5Bbook_id%5D=1")
+	b := NormalizeQuery("count=10&filter[book_id]=1&filter[name]=x")
+	if a != b {
+		t.Errorf("NormalizeQuery mismatch: %q != %q", a, b)
+	}
+}
@@ -23,7 +23,12 @@ func (s *ChaptersService) List(ctx context.Context, opts *ListOptions) ([]Chapte
 
 // ListAll returns an iterator over all chapters, handling pagination automatically.
 func (s *ChaptersService) ListAll(ctx context.Context) iter.Seq2[Chapter, error] {
-	return listAll[Chapter](ctx, s.client, "/api/chapters")
+	return listAll[Chapter](ctx, s.client, "/api/chapters", nil)
+}
+
+// ListAllWith returns an iterator over all chapters matching opts, handling pagination automatically.
+func (s *ChaptersService) ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Chapter, error] {
+	return listAll[Chapter](ctx, s.client, "/api/chapters", opts)
 }
 
 // Get retrieves a single chapter by ID.
@@ -35,3 +40,49 @@ func (s *ChaptersService) Get(ctx context.Context, id int) (*Chapter, error) {
 	}
 	return &chapter, nil
 }
+
+// GetBySlug retrieves a single chapter by its URL slug.
+// Chapter slugs are only unique within a book, so bookSlug should be given;
+// if it is empty the slug must match exactly one chapter across all books.
+func (s *ChaptersService) GetBySlug(ctx context.Context, bookSlug, slug string) (*Chapter, error) {
+	return getBySlug(ctx, s.client, "chapter:"+bookSlug+"/"+slug, func() (int, error) {
+		bookID, err := s.client.bookIDBySlug(ctx, bookSlug)
+		if err != nil {
+			return 0, err
+		}
+		chapters, err := s.List(ctx, slugFilter(slug, bookID))
+		if err != nil {
+			return 0, err
+		}
+		ids := make([]int, len(chapters))
+		for i, ch := range chapters {
+			ids[i] = ch.ID
+		}
+		return singleMatch("chapter", slug, ids)
+	}, s.Get, func(ch *Chapter) bool { return ch.Slug == slug })
+}
+
+// Create creates a new chapter.
+func (s *ChaptersService) Create(ctx context.Context, req *ChapterCreateRequest) (*Chapter, error) {
+	var chapter Chapter
+	err := s.client.do(ctx, "POST", "/api/chapters", req, &chapter)
+	if err != nil {
+		return nil, err
+	}
+	return &chapter, nil
+}
+
+// Update updates an existing chapter.
+func (s *ChaptersService) Update(ctx context.Context, id int, req *ChapterUpdateRequest) (*Chapter, error) {
+	var chapter Chapter
+	err := s.client.do(ctx, "PUT", fmt.Sprintf("/api/chapters/%d", id), req, &chapter)
+	if err != nil {
+		return nil, err
+	}
+	return &chapter, nil
+}
+
+// Delete deletes a chapter by ID.
+func (s *ChaptersService) Delete(ctx context.Context, id int) error {
+	return s.client.do(ctx, "DELETE", fmt.Sprintf("/api/chapters/%d", id), nil, nil)
+}
@@ -60,3 +60,25 @@ func TestChaptersService_Get_NotFound(t *testing.T) {
 		t.Errorf("expected ErrNotFound, got %v", err)
 	}
 }
+
+func TestChaptersService_Create(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/chapters" {
+			t.Errorf("%s %s, want POST /api/chapters", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["book_id"] != float64(2) {
+			t.Errorf("book_id = %v, want 2", body["book_id"])
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 9, "book_id": 2, "name": "Intro"})
+	})
+
+	ch, err := c.Chapters.Create(context.Background(), &ChapterCreateRequest{BookID: 2, Name: "Intro"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ch.ID != 9 {
+		t.Errorf("ID = %d, want 9", ch.ID)
+	}
+}
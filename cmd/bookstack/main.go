@@ -0,0 +1,180 @@
+// Command bookstack is a command-line client for the Bookstack REST API.
+//
+// Usage:
+//
+//	bookstack [--json] [--profile name] [--config file] <command> [arguments]
+//
+// Commands operate on a service and take an action:
+//
+//	bookstack books list [--all] [--count n] [--sort field] [--filter key=value]
+//	bookstack pages get <id | book-slug/page-slug | url>
+//	bookstack pages export [--format md|pdf] <id>
+//	bookstack pages outline [--section anchor|heading] <id>
+//	bookstack pages revisions <id>
+//	bookstack pages diff [--format markdown|html] <id> <from-revision> [to-revision]
+//	bookstack pages create --data '{"book_id": 1, "name": "New", "markdown": "# Hi"}'
+//	bookstack chapters update <id> < request.json
+//	bookstack pages move|copy [--book id] [--chapter id] <page>
+//	bookstack chapters move|copy --book <id> <chapter>
+//	bookstack books reorder [--dry-run] <book> < layout.json
+//	bookstack shelves delete <id>
+//	bookstack attachments upload --page <id> [--name name] <file>
+//	bookstack images upload --page <id> <file>
+//	bookstack search [--all] <query>
+//
+// The services are books, chapters, pages, shelves, attachments, comments
+// and images. Create and update read a JSON request body from --data or
+// standard input, using the field names of the Bookstack API.
+//
+// Credentials come from a profile, resolved by the profile package from the
+// profiles file (see --config), BOOKSTACK_<PROFILE>_* environment variables
+// and the plain BOOKSTACK_URL, BOOKSTACK_TOKEN_ID and BOOKSTACK_TOKEN_SECRET
+// variables. Output is a table unless --json is given, which prints the API
+// objects for scripts and AI agents.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/profile"
+)
+
+// errUsage marks errors caused by invalid command-line arguments.
+var errUsage = errors.New("usage error")
+
+// cli holds the environment of a command invocation.
+type cli struct {
+	client *bookstack.Client
+	json   bool
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a top-level command such as "pages" or "search".
+type command interface {
+	run(ctx context.Context, cl *cli, args []string) error
+	usage() string
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Getenv, os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command line args and returns the exit status: 0 on
+// success, 1 on failure and 2 for invalid usage.
+func run(ctx context.Context, args []string, getenv func(string) string, stdin io.Reader, stdout, stderr io.Writer) int {
+	cl := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+	fs := flag.NewFlagSet("bookstack", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.BoolVar(&cl.json, "json", false, "print JSON instead of tables")
+	configPath := fs.String("config", "", "profiles file (default $BOOKSTACK_CONFIG or <user config dir>/bookstack/config.toml)")
+	profileName := fs.String("profile", "", "profile to use (default $BOOKSTACK_PROFILE or the file's default)")
+	fs.Usage = func() { printUsage(stderr, fs) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	commands := newCommands()
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "bookstack: unknown command %q\n", name)
+		fs.Usage()
+		return 2
+	}
+
+	loader := &profile.Loader{Path: *configPath, Getenv: getenv}
+	cfg, err := loader.Load(ctx, *profileName)
+	if err != nil {
+		fmt.Fprintf(stderr, "bookstack: %v\n", err)
+		return 1
+	}
+	cl.client, err = bookstack.NewClient(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "bookstack: %v\n", err)
+		return 1
+	}
+
+	if err := cmd.run(ctx, cl, fs.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "bookstack %s: %v\n", name, err)
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "usage: bookstack %s\n", cmd.usage())
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+func printUsage(w io.Writer, fs *flag.FlagSet) {
+	fmt.Fprintln(w, "usage: bookstack [--json] [--profile name] [--config file] <command> [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	commands := newCommands()
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  bookstack %s\n", commands[name].usage())
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	fs.PrintDefaults()
+}
+
+// usageError returns an error wrapping errUsage.
+func usageError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+// parseFlags parses a subcommand's flags, which may be mixed with its
+// positional arguments, and returns the positional arguments. The global
+// --json flag is accepted in any position.
+func parseFlags(cl *cli, fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(cl.stderr)
+	fs.BoolVar(&cl.json, "json", cl.json, "print JSON instead of tables")
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// joinArgs joins positional arguments into a single string, as used for
+// search queries.
+func joinArgs(args []string) string {
+	return strings.TrimSpace(strings.Join(args, " "))
+}
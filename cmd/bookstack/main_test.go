@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/bookstacktest"
+)
+
+// runCLI runs the command line against srv and returns the exit code,
+// standard output and standard error.
+func runCLI(t *testing.T, srv *bookstacktest.Server, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	env := map[string]string{
+		"BOOKSTACK_CONFIG":       emptyProfiles(t),
+		"BOOKSTACK_URL":          srv.URL,
+		"BOOKSTACK_TOKEN_ID":     bookstacktest.DefaultTokenID,
+		"BOOKSTACK_TOKEN_SECRET": bookstacktest.DefaultTokenSecret,
+	}
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, func(k string) string { return env[k] }, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// emptyProfiles returns an empty profiles file, so that tests do not read
+// the user's configuration.
+func emptyProfiles(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun_ListTable(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	srv.AddBook(bookstack.Book{Name: "Operations"})
+	srv.AddBook(bookstack.Book{Name: "Engineering"})
+
+	code, out, errOut := runCLI(t, srv, "", "books", "list", "--sort", "name")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, errOut)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), out)
+	}
+	if !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[1], "Engineering") || !strings.Contains(lines[2], "operations") {
+		t.Errorf("unexpected table:\n%s", out)
+	}
+}
+
+func TestRun_ListJSONWithFilter(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Handbook"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Rollback"})
+
+	code, out, errOut := runCLI(t, srv, "", "--json", "pages", "list", "--all", "--filter", "name=Deploy")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, errOut)
+	}
+	var pages []bookstack.Page
+	if err := json.Unmarshal([]byte(out), &pages); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if len(pages) != 1 || pages[0].Name != "Deploy" {
+		t.Errorf("pages = %+v", pages)
+	}
+
+	code, out, _ = runCLI(t, srv, "", "pages", "list", "--json", "--filter", "name=Missing")
+	if code != 0 || strings.TrimSpace(out) != "[]" {
+		t.Errorf("empty list: code %d, output %q", code, out)
+	}
+}
+
+func TestRun_GetByReference(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Handbook"})
+	page := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "On Call"})
+
+	for _, ref := range []string{"1", "handbook/on-call", srv.URL + "/books/handbook/page/on-call"} {
+		code, out, errOut := runCLI(t, srv, "", "pages", "get", "--json", ref)
+		if code != 0 {
+			t.Fatalf("get %s: exit code = %d, stderr: %s", ref, code, errOut)
+		}
+		var got bookstack.Page
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != page.ID {
+			t.Errorf("get %s: ID = %d, want %d", ref, got.ID, page.ID)
+		}
+	}
+
+	code, _, errOut := runCLI(t, srv, "", "books", "get", srv.URL+"/books/handbook/page/on-call")
+	if code != 1 || !strings.Contains(errOut, "not one of books") {
+		t.Errorf("wrong type: code %d, stderr %q", code, errOut)
+	}
+	code, _, errOut = runCLI(t, srv, "", "pages", "get", "99")
+	if code != 1 || errOut == "" {
+		t.Errorf("missing page: code %d, stderr %q", code, errOut)
+	}
+}
+
+func TestRun_CreateUpdateDelete(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Handbook"})
+
+	code, out, errOut := runCLI(t, srv, `{"book_id": 1, "name": "Runbook", "markdown": "# Steps"}`, "--json", "pages", "create")
+	if code != 0 {
+		t.Fatalf("create: exit code = %d, stderr: %s", code, errOut)
+	}
+	var created bookstack.Page
+	if err := json.Unmarshal([]byte(out), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.BookID != book.ID || created.Name != "Runbook" {
+		t.Errorf("created = %+v", created)
+	}
+
+	code, out, errOut = runCLI(t, srv, "", "pages", "update", "handbook/runbook", "--data", `{"name": "Runbook v2"}`)
+	if code != 0 {
+		t.Fatalf("update: exit code = %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, "Runbook v2") {
+		t.Errorf("update output:\n%s", out)
+	}
+
+	code, out, errOut = runCLI(t, srv, "", "pages", "delete", "1")
+	if code != 0 || strings.TrimSpace(out) != "deleted 1" {
+		t.Fatalf("delete: code %d, output %q, stderr %s", code, out, errOut)
+	}
+	if srv.Count("pages") != 0 {
+		t.Error("page was not deleted")
+	}
+}
+
+func TestRun_InvalidBody(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	code, _, errOut := runCLI(t, srv, `{"title": "typo"}`, "books", "create")
+	if code != 2 || !strings.Contains(errOut, "unknown field") {
+		t.Errorf("code %d, stderr %q", code, errOut)
+	}
+	code, _, _ = runCLI(t, srv, "", "books", "create")
+	if code != 2 {
+		t.Errorf("empty body: code %d, want 2", code)
+	}
+}
+
+func TestRun_ExportMarkdown(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Handbook"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", Markdown: "# Deploy\n\nRun it."})
+
+	code, out, errOut := runCLI(t, srv, "", "pages", "export", "1")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, "Run it.") {
+		t.Errorf("export output = %q", out)
+	}
+	if code, _, _ := runCLI(t, srv, "", "pages", "export", "--format", "odt", "1"); code != 2 {
+		t.Errorf("unknown format: code %d, want 2", code)
+	}
+}
+
+func TestRun_Upload(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Handbook"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy"})
+
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, []byte("notes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, out, errOut := runCLI(t, srv, "", "attachments", "upload", "--page", "1", file)
+	if code != 0 {
+		t.Fatalf("attachment: exit code = %d, stderr: %s", code, errOut)
+	}
+	if !strings.Contains(out, "notes.txt") {
+		t.Errorf("attachment output:\n%s", out)
+	}
+
+	code, _, errOut = runCLI(t, srv, "PNGDATA", "images", "upload", "--page", "1", "--name", "chart.png", "-")
+	if code != 0 {
+		t.Fatalf("image: exit code = %d, stderr: %s", code, errOut)
+	}
+	if srv.Count("image-gallery") != 1 {
+		t.Error("image was not uploaded")
+	}
+	if code, _, _ := runCLI(t, srv, "", "images", "create"); code != 2 {
+		t.Errorf("images create: code %d, want 2", code)
+	}
+}
+
+func TestRun_Search(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Handbook"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", HTML: "<p>kubernetes rollout</p>"})
+
+	code, out, errOut := runCLI(t, srv, "", "--json", "search", "--all", "kubernetes")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, errOut)
+	}
+	var results []bookstack.SearchResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Name != "Deploy" {
+		t.Errorf("results = %+v", results)
+	}
+
+	code, out, errOut = runCLI(t, srv, "", "--json", "search", "--count", "1", "kubernetes")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, errOut)
+	}
+	var page struct {
+		Total int                      `json:"total"`
+		Data  []bookstack.SearchResult `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(out), &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || len(page.Data) != 1 {
+		t.Errorf("page = %+v", page)
+	}
+
+	if code, out, _ = runCLI(t, srv, "", "search", "kubernetes"); code != 0 || !strings.HasSuffix(out, "showing 1 of 1\n") {
+		t.Errorf("table output: code %d, %q", code, out)
+	}
+}
+
+func TestRun_GetByLink(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Ops"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", Markdown: "# Deploy"})
+
+	for _, ref := range []string{srv.URL + "/books/ops/page/deploy", "/books/ops/page/deploy"} {
+		code, out, errOut := runCLI(t, srv, "", "pages", "get", ref)
+		if code != 0 || !strings.Contains(out, "Deploy") {
+			t.Errorf("get %s: code %d, stdout %q, stderr %q", ref, code, out, errOut)
+		}
+	}
+}
+
+func TestGroupDigits(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 20: "20", 999: "999", 1342: "1,342", 1234567: "1,234,567", -1342: "-1,342"} {
+		if got := groupDigits(n); got != want {
+			t.Errorf("groupDigits(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	tests := [][]string{
+		{},
+		{"nosuch"},
+		{"books"},
+		{"books", "frobnicate"},
+		{"books", "list", "--bogus"},
+		{"attachments", "get", "some-slug"},
+	}
+	for _, args := range tests {
+		if code, _, errOut := runCLI(t, srv, "", args...); code != 2 || errOut == "" {
+			t.Errorf("%q: code %d, stderr %q", args, code, errOut)
+		}
+	}
+}
+
+func TestRun_Profile(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	srv.AddBook(bookstack.Book{Name: "Staging Handbook"})
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "[staging]\nurl = \"" + srv.URL + "\"\ntoken_id = \"" + bookstacktest.DefaultTokenID + "\"\ntoken_secret = \"" + bookstacktest.DefaultTokenSecret + "\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"--config", path, "--profile", "staging", "books", "list"},
+		func(string) string { return "" }, strings.NewReader(""), &stdout, &stderr)
+	if code != 0 || !strings.Contains(stdout.String(), "Staging Handbook") {
+		t.Errorf("code %d, stdout %q, stderr %q", code, stdout.String(), stderr.String())
+	}
+
+	stderr.Reset()
+	code = run(context.Background(), []string{"--config", path, "--profile", "prod", "books", "list"},
+		func(string) string { return "" }, strings.NewReader(""), &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), `profile not found: "prod"`) {
+		t.Errorf("unknown profile: code %d, stderr %q", code, stderr.String())
+	}
+}
+
+func TestRun_RevisionsAndDiff(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Handbook"})
+	page := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", Markdown: "# Deploy\n\nRun make."})
+	if _, err := srv.Client().Pages.Update(context.Background(), page.ID, &bookstack.PageUpdateRequest{Markdown: "# Deploy\n\nRun make deploy."}); err != nil {
+		t.Fatal(err)
+	}
+
+	code, out, errOut := runCLI(t, srv, "", "pages", "revisions", "handbook/deploy")
+	if code != 0 || strings.Count(out, "\n") != 3 {
+		t.Fatalf("revisions: code %d, stdout %q, stderr %q", code, out, errOut)
+	}
+
+	code, out, errOut = runCLI(t, srv, "", "pages", "diff", "1", "1")
+	if code != 0 {
+		t.Fatalf("diff: code %d, stderr %q", code, errOut)
+	}
+	if !strings.Contains(out, "-Run make.\n+Run make deploy.\n") || !strings.HasPrefix(out, "--- revision 1\n+++ current\n") {
+		t.Errorf("diff output:\n%s", out)
+	}
+}
+
+func TestRun_MoveCopy(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	ops := srv.AddBook(bookstack.Book{Name: "Operations"})
+	archive := srv.AddBook(bookstack.Book{Name: "Archive"})
+	ch := srv.AddChapter(bookstack.Chapter{BookID: archive.ID, Name: "Old"})
+	page := srv.AddPage(bookstack.Page{BookID: ops.ID, Name: "Deploy", Markdown: "# Deploy"})
+
+	if code, _, errOut := runCLI(t, srv, "", "pages", "copy", "--book", "2", "operations/deploy"); code != 0 {
+		t.Fatalf("copy: code %d, stderr %q", code, errOut)
+	}
+	if code, _, errOut := runCLI(t, srv, "", "pages", "move", "--chapter", "1", "1"); code != 0 {
+		t.Fatalf("move: code %d, stderr %q", code, errOut)
+	}
+	if p, _ := srv.Page(page.ID); p.ChapterID != ch.ID || p.BookID != archive.ID {
+		t.Errorf("moved page = %+v", p)
+	}
+	if srv.Count("pages") != 2 {
+		t.Errorf("pages = %d, want 2", srv.Count("pages"))
+	}
+	if code, _, _ := runCLI(t, srv, "", "chapters", "move", "1"); code != 2 {
+		t.Errorf("move without target: code %d, want 2", code)
+	}
+}
+
+func TestRun_Reorder(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Operations"})
+	first := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy"})
+	second := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Rollback"})
+	layout := fmt.Sprintf(`[{"page_id":%d},{"page_id":%d}]`, second.ID, first.ID)
+
+	code, out, errOut := runCLI(t, srv, "", "books", "reorder", "--dry-run", "--data", layout, "operations")
+	if code != 0 || !strings.Contains(out, "Deploy") {
+		t.Fatalf("dry run: code %d, out %q, stderr %q", code, out, errOut)
+	}
+	if p, _ := srv.Page(first.ID); p.Priority != first.Priority {
+		t.Errorf("dry run changed priority to %d", p.Priority)
+	}
+	code, out, errOut = runCLI(t, srv, layout, "books", "reorder", "--json", "operations")
+	if code != 0 {
+		t.Fatalf("code %d, stderr %q", code, errOut)
+	}
+	var changes []bookstack.ReorderChange
+	if err := json.Unmarshal([]byte(out), &changes); err != nil || len(changes) == 0 {
+		t.Fatalf("changes = %q, %v", out, err)
+	}
+	a, _ := srv.Page(first.ID)
+	b, _ := srv.Page(second.ID)
+	if b.Priority >= a.Priority {
+		t.Errorf("priorities = %d, %d", b.Priority, a.Priority)
+	}
+}
+
+func TestRun_Outline(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Handbook"})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy",
+		HTML: `<p>Intro</p><h2 id="bkmrk-setup">Setup</h2><p>Run make.</p><h3 id="bkmrk-linux">Linux</h3><p>apt</p>`})
+
+	code, out, errOut := runCLI(t, srv, "", "pages", "outline", "handbook/deploy")
+	if code != 0 {
+		t.Fatalf("outline: code %d, stderr %q", code, errOut)
+	}
+	if !strings.Contains(out, "  Linux") || !strings.Contains(out, "/books/handbook/page/deploy#bkmrk-setup") {
+		t.Errorf("outline output:\n%s", out)
+	}
+
+	code, out, errOut = runCLI(t, srv, "", "pages", "outline", "--section", "linux", "1")
+	if code != 0 || out != "<h3 id=\"bkmrk-linux\">Linux</h3><p>apt</p>\n" {
+		t.Errorf("section: code %d, stdout %q, stderr %q", code, out, errOut)
+	}
+
+	code, out, _ = runCLI(t, srv, "", "--json", "pages", "outline", "--section", "#bkmrk-setup", "1")
+	var entry struct{ Anchor, URL, HTML string }
+	if code != 0 || json.Unmarshal([]byte(out), &entry) != nil || entry.Anchor != "bkmrk-setup" || !strings.HasSuffix(entry.URL, "#bkmrk-setup") || !strings.HasPrefix(entry.HTML, "<h2") {
+		t.Errorf("json section: code %d, %q", code, out)
+	}
+
+	if code, _, errOut = runCLI(t, srv, "", "pages", "outline", "--section", "missing", "1"); code != 1 || !strings.Contains(errOut, `no section "missing"`) {
+		t.Errorf("missing section: code %d, stderr %q", code, errOut)
+	}
+}
+
+func TestCleanCell(t *testing.T) {
+	if got := cleanCell("a\n  b\tc"); got != "a b c" {
+		t.Errorf("cleanCell = %q", got)
+	}
+	got := cleanCell(strings.Repeat("ä", 100))
+	if !utf8.ValidString(got) || got != strings.Repeat("ä", 77)+"..." {
+		t.Errorf("truncated cell = %q", got)
+	}
+}
@@ -0,0 +1,910 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/markup"
+)
+
+// resource is the command for one service, such as "books". T is the item
+// type, C and U the create and update request types.
+type resource[T, C, U any] struct {
+	name    string
+	list    func(ctx context.Context, c *bookstack.Client, opts *bookstack.ListOptions) ([]T, error)
+	listAll func(ctx context.Context, c *bookstack.Client, opts *bookstack.ListOptions) iter.Seq2[T, error]
+	get     func(ctx context.Context, c *bookstack.Client, id int) (*T, error)
+	create  func(ctx context.Context, c *bookstack.Client, req *C) (*T, error)
+	update  func(ctx context.Context, c *bookstack.Client, id int, req *U) (*T, error)
+	delete  func(ctx context.Context, c *bookstack.Client, id int) error
+
+	// bySlug looks up an item by its slug, or "book-slug/slug" for chapters
+	// and pages. It is nil for services without slugs.
+	bySlug func(ctx context.Context, c *bookstack.Client, ref string) (*T, error)
+	id     func(*T) int
+
+	header []string
+	row    func(*T) []string
+
+	// extra holds additional actions such as "export".
+	extra map[string]action
+}
+
+// action is an additional subcommand of a resource.
+type action func(ctx context.Context, cl *cli, args []string) error
+
+func (r *resource[T, C, U]) usage() string {
+	actions := []string{"list", "get", "create", "update", "delete"}
+	if r.create == nil {
+		actions = slices.Delete(actions, 2, 3)
+	}
+	actions = append(actions, slices.Sorted(maps.Keys(r.extra))...)
+	return r.name + " " + strings.Join(actions, "|") + " [arguments]"
+}
+
+func (r *resource[T, C, U]) run(ctx context.Context, cl *cli, args []string) error {
+	if len(args) == 0 {
+		return usageError("missing action")
+	}
+	name, args := args[0], args[1:]
+	switch name {
+	case "list", "ls":
+		return r.runList(ctx, cl, args)
+	case "get", "show":
+		return r.runGet(ctx, cl, args)
+	case "create":
+		return r.runCreate(ctx, cl, args)
+	case "update":
+		return r.runUpdate(ctx, cl, args)
+	case "delete", "rm":
+		return r.runDelete(ctx, cl, args)
+	}
+	if a, ok := r.extra[name]; ok {
+		return a(ctx, cl, args)
+	}
+	return usageError("unknown action %q", name)
+}
+
+func (r *resource[T, C, U]) runList(ctx context.Context, cl *cli, args []string) error {
+	fs := flag.NewFlagSet(r.name+" list", flag.ContinueOnError)
+	all := fs.Bool("all", false, "fetch every page of results")
+	opts := listFlags(fs)
+	args, err := parseFlags(cl, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageError("unexpected argument %q", args[0])
+	}
+
+	var items []T
+	if *all {
+		for item, err := range r.listAll(ctx, cl.client, opts) {
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+	} else if items, err = r.list(ctx, cl.client, opts); err != nil {
+		return err
+	}
+
+	if cl.json {
+		if items == nil {
+			items = []T{}
+		}
+		return printJSON(cl.stdout, items)
+	}
+	rows := make([][]string, len(items))
+	for i := range items {
+		rows[i] = r.row(&items[i])
+	}
+	return printTable(cl.stdout, r.header, rows)
+}
+
+func (r *resource[T, C, U]) runGet(ctx context.Context, cl *cli, args []string) error {
+	fs := flag.NewFlagSet(r.name+" get", flag.ContinueOnError)
+	args, err := parseFlags(cl, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageError("expected one %s reference", r.name)
+	}
+	item, err := r.lookup(ctx, cl.client, args[0])
+	if err != nil {
+		return err
+	}
+	return r.print(cl, item)
+}
+
+func (r *resource[T, C, U]) runCreate(ctx context.Context, cl *cli, args []string) error {
+	fs := flag.NewFlagSet(r.name+" create", flag.ContinueOnError)
+	data := fs.String("data", "", "JSON request body (default: read from standard input)")
+	args, err := parseFlags(cl, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return usageError("unexpected argument %q", args[0])
+	}
+	if r.create == nil {
+		return usageError("%s cannot be created from JSON; see the upload action", r.name)
+	}
+	var req C
+	if err := readBody(cl.stdin, *data, &req); err != nil {
+		return err
+	}
+	item, err := r.create(ctx, cl.client, &req)
+	if err != nil {
+		return err
+	}
+	return r.print(cl, item)
+}
+
+func (r *resource[T, C, U]) runUpdate(ctx context.Context, cl *cli, args []string) error {
+	fs := flag.NewFlagSet(r.name+" update", flag.ContinueOnError)
+	data := fs.String("data", "", "JSON request body (default: read from standard input)")
+	args, err := parseFlags(cl, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usageError("expected one %s reference", r.name)
+	}
+	id, err := r.resolveID(ctx, cl.client, args[0])
+	if err != nil {
+		return err
+	}
+	var req U
+	if err := readBody(cl.stdin, *data, &req); err != nil {
+		return err
+	}
+	item, err := r.update(ctx, cl.client, id, &req)
+	if err != nil {
+		return err
+	}
+	return r.print(cl, item)
+}
+
+func (r *resource[T, C, U]) runDelete(ctx context.Context, cl *cli, args []string) error {
+	fs := flag.NewFlagSet(r.name+" delete", flag.ContinueOnError)
+	args, err := parseFlags(cl, fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return usageError("expected at least one %s reference", r.name)
+	}
+	for _, ref := range args {
+		id, err := r.resolveID(ctx, cl.client, ref)
+		if err != nil {
+			return err
+		}
+		if err := r.delete(ctx, cl.client, id); err != nil {
+			return fmt.Errorf("deleting %s: %w", ref, err)
+		}
+		if !cl.json {
+			fmt.Fprintf(cl.stdout, "deleted %d\n", id)
+		}
+	}
+	return nil
+}
+
+// print writes a single item as JSON or as a field list.
+func (r *resource[T, C, U]) print(cl *cli, item *T) error {
+	if cl.json {
+		return printJSON(cl.stdout, item)
+	}
+	return printFields(cl.stdout, r.header, r.row(item))
+}
+
+// lookup fetches the item referenced by ref: an ID, a web URL or
+// root-relative link or, for services with slugs, a slug path.
+func (r *resource[T, C, U]) lookup(ctx context.Context, c *bookstack.Client, ref string) (*T, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return r.get(ctx, c, id)
+	}
+	if r.bySlug == nil {
+		return nil, usageError("invalid %s ID %q", r.name, ref)
+	}
+	if strings.Contains(ref, "://") || strings.HasPrefix(ref, "/") {
+		e, err := c.ResolveURL(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		item, ok := any(e).(*T)
+		if !ok {
+			return nil, fmt.Errorf("%s refers to a %s, not one of %s", ref, e.EntityType(), r.name)
+		}
+		return item, nil
+	}
+	return r.bySlug(ctx, c, ref)
+}
+
+// resolveID returns the ID of the item referenced by ref, fetching it only
+// when ref is not already an ID.
+func (r *resource[T, C, U]) resolveID(ctx context.Context, c *bookstack.Client, ref string) (int, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return id, nil
+	}
+	item, err := r.lookup(ctx, c, ref)
+	if err != nil {
+		return 0, err
+	}
+	return r.id(item), nil
+}
+
+// moveAction returns an action moving or copying an item to the book or
+// chapter given by --book and --chapter.
+func moveAction[T, C, U any](r *resource[T, C, U], verb string, fn func(ctx context.Context, c *bookstack.Client, id int, target bookstack.MoveTarget) (*T, error)) action {
+	return func(ctx context.Context, cl *cli, args []string) error {
+		var target bookstack.MoveTarget
+		fs := flag.NewFlagSet(r.name+" "+verb, flag.ContinueOnError)
+		fs.IntVar(&target.BookID, "book", 0, "ID of the target book")
+		if r.name == "pages" {
+			fs.IntVar(&target.ChapterID, "chapter", 0, "ID of the target chapter")
+		}
+		args, err := parseFlags(cl, fs, args)
+		if err != nil {
+			return err
+		}
+		if len(args) != 1 {
+			return usageError("expected one %s reference", r.name)
+		}
+		if target.BookID == 0 && target.ChapterID == 0 {
+			return usageError("a target --book is required")
+		}
+		id, err := r.resolveID(ctx, cl.client, args[0])
+		if err != nil {
+			return err
+		}
+		item, err := fn(ctx, cl.client, id, target)
+		if err != nil {
+			return err
+		}
+		return r.print(cl, item)
+	}
+}
+
+// listFlags registers the list option flags on fs.
+func listFlags(fs *flag.FlagSet) *bookstack.ListOptions {
+	opts := &bookstack.ListOptions{Filter: map[string]string{}}
+	fs.IntVar(&opts.Count, "count", 0, "maximum number of items per request")
+	fs.IntVar(&opts.Offset, "offset", 0, "number of items to skip")
+	fs.StringVar(&opts.Sort, "sort", "", "sort field, prefixed with - for descending order")
+	fs.Func("filter", "filter as key=value, e.g. name:like=%ops% (repeatable)", func(s string) error {
+		key, val, ok := strings.Cut(s, "=")
+		if !ok || key == "" {
+			return errors.New("filter must have the form key=value")
+		}
+		opts.Filter[key] = val
+		return nil
+	})
+	return opts
+}
+
+// readBody decodes a JSON request body from data or, when data is empty,
+// from stdin. Unknown fields are rejected to catch typos.
+func readBody(stdin io.Reader, data string, v any) error {
+	var r io.Reader = strings.NewReader(data)
+	if data == "" {
+		r = stdin
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return usageError("missing JSON request body")
+		}
+		return fmt.Errorf("%w: invalid request body: %v", errUsage, err)
+	}
+	return nil
+}
+
+// splitSlugs splits a "book-slug/slug" reference.
+func splitSlugs(ref string) (string, string, error) {
+	book, slug, ok := strings.Cut(ref, "/")
+	if !ok || book == "" || slug == "" {
+		return "", "", usageError("expected an ID, URL or book-slug/slug, got %q", ref)
+	}
+	return book, slug, nil
+}
+
+// newCommands returns the top-level commands by name.
+func newCommands() map[string]command {
+	return map[string]command{
+		"books":       booksCommand(),
+		"chapters":    chaptersCommand(),
+		"pages":       pagesCommand(),
+		"shelves":     shelvesCommand(),
+		"attachments": attachmentsCommand(),
+		"comments":    commentsCommand(),
+		"images":      imagesCommand(),
+		"search":      searchCommand{},
+	}
+}
+
+func booksCommand() *resource[bookstack.Book, bookstack.BookCreateRequest, bookstack.BookUpdateRequest] {
+	r := &resource[bookstack.Book, bookstack.BookCreateRequest, bookstack.BookUpdateRequest]{
+		name: "books",
+		list: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) ([]bookstack.Book, error) {
+			return c.Books.List(ctx, o)
+		},
+		listAll: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) iter.Seq2[bookstack.Book, error] {
+			return c.Books.ListAllWith(ctx, o)
+		},
+		get: func(ctx context.Context, c *bookstack.Client, id int) (*bookstack.Book, error) {
+			return c.Books.Get(ctx, id)
+		},
+		create: func(ctx context.Context, c *bookstack.Client, req *bookstack.BookCreateRequest) (*bookstack.Book, error) {
+			return c.Books.Create(ctx, req)
+		},
+		update: func(ctx context.Context, c *bookstack.Client, id int, req *bookstack.BookUpdateRequest) (*bookstack.Book, error) {
+			return c.Books.Update(ctx, id, req)
+		},
+		delete: func(ctx context.Context, c *bookstack.Client, id int) error { return c.Books.Delete(ctx, id) },
+		bySlug: func(ctx context.Context, c *bookstack.Client, ref string) (*bookstack.Book, error) {
+			return c.Books.GetBySlug(ctx, ref)
+		},
+		id:     func(b *bookstack.Book) int { return b.ID },
+		header: []string{"ID", "NAME", "SLUG", "UPDATED"},
+		row: func(b *bookstack.Book) []string {
+			return []string{strconv.Itoa(b.ID), b.Name, b.Slug, formatTime(b.UpdatedAt)}
+		},
+	}
+	r.extra = map[string]action{
+		"reorder": func(ctx context.Context, cl *cli, args []string) error {
+			fs := flag.NewFlagSet("books reorder", flag.ContinueOnError)
+			data := fs.String("data", "", "JSON layout; read from stdin if omitted")
+			dryRun := fs.Bool("dry-run", false, "print the changes without applying them")
+			args, err := parseFlags(cl, fs, args)
+			if err != nil {
+				return err
+			}
+			if len(args) != 1 {
+				return usageError("expected one book reference")
+			}
+			var layout []bookstack.LayoutItem
+			if err := readBody(cl.stdin, *data, &layout); err != nil {
+				return err
+			}
+			id, err := r.resolveID(ctx, cl.client, args[0])
+			if err != nil {
+				return err
+			}
+			reorder := cl.client.Books.Reorder
+			if *dryRun {
+				reorder = cl.client.Books.PlanReorder
+			}
+			changes, err := reorder(ctx, id, layout)
+			if err != nil {
+				return err
+			}
+			if cl.json {
+				return printJSON(cl.stdout, changes)
+			}
+			rows := make([][]string, len(changes))
+			for i, c := range changes {
+				rows[i] = []string{c.Type, strconv.Itoa(c.ID), c.Name,
+					itoa(c.FromChapterID) + " -> " + itoa(c.ToChapterID),
+					strconv.Itoa(c.FromPriority) + " -> " + strconv.Itoa(c.ToPriority)}
+			}
+			return printTable(cl.stdout, []string{"TYPE", "ID", "NAME", "CHAPTER", "PRIORITY"}, rows)
+		},
+	}
+	return r
+}
+
+func chaptersCommand() *resource[bookstack.Chapter, bookstack.ChapterCreateRequest, bookstack.ChapterUpdateRequest] {
+	r := &resource[bookstack.Chapter, bookstack.ChapterCreateRequest, bookstack.ChapterUpdateRequest]{
+		name: "chapters",
+		list: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) ([]bookstack.Chapter, error) {
+			return c.Chapters.List(ctx, o)
+		},
+		listAll: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) iter.Seq2[bookstack.Chapter, error] {
+			return c.Chapters.ListAllWith(ctx, o)
+		},
+		get: func(ctx context.Context, c *bookstack.Client, id int) (*bookstack.Chapter, error) {
+			return c.Chapters.Get(ctx, id)
+		},
+		create: func(ctx context.Context, c *bookstack.Client, req *bookstack.ChapterCreateRequest) (*bookstack.Chapter, error) {
+			return c.Chapters.Create(ctx, req)
+		},
+		update: func(ctx context.Context, c *bookstack.Client, id int, req *bookstack.ChapterUpdateRequest) (*bookstack.Chapter, error) {
+			return c.Chapters.Update(ctx, id, req)
+		},
+		delete: func(ctx context.Context, c *bookstack.Client, id int) error { return c.Chapters.Delete(ctx, id) },
+		bySlug: func(ctx context.Context, c *bookstack.Client, ref string) (*bookstack.Chapter, error) {
+			book, slug, err := splitSlugs(ref)
+			if err != nil {
+				return nil, err
+			}
+			return c.Chapters.GetBySlug(ctx, book, slug)
+		},
+		id:     func(ch *bookstack.Chapter) int { return ch.ID },
+		header: []string{"ID", "BOOK", "NAME", "SLUG", "UPDATED"},
+		row: func(ch *bookstack.Chapter) []string {
+			return []string{strconv.Itoa(ch.ID), strconv.Itoa(ch.BookID), ch.Name, ch.Slug, formatTime(ch.UpdatedAt)}
+		},
+	}
+	r.extra = map[string]action{
+		"move": moveAction(r, "move", func(ctx context.Context, c *bookstack.Client, id int, target bookstack.MoveTarget) (*bookstack.Chapter, error) {
+			return c.Chapters.Move(ctx, id, target)
+		}),
+		"copy": moveAction(r, "copy", func(ctx context.Context, c *bookstack.Client, id int, target bookstack.MoveTarget) (*bookstack.Chapter, error) {
+			return c.Chapters.Copy(ctx, id, target)
+		}),
+	}
+	return r
+}
+
+func pagesCommand() *resource[bookstack.Page, bookstack.PageCreateRequest, bookstack.PageUpdateRequest] {
+	r := &resource[bookstack.Page, bookstack.PageCreateRequest, bookstack.PageUpdateRequest]{
+		name: "pages",
+		list: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) ([]bookstack.Page, error) {
+			return c.Pages.List(ctx, o)
+		},
+		listAll: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) iter.Seq2[bookstack.Page, error] {
+			return c.Pages.ListAllWith(ctx, o)
+		},
+		get: func(ctx context.Context, c *bookstack.Client, id int) (*bookstack.Page, error) {
+			return c.Pages.Get(ctx, id)
+		},
+		create: func(ctx context.Context, c *bookstack.Client, req *bookstack.PageCreateRequest) (*bookstack.Page, error) {
+			return c.Pages.Create(ctx, req)
+		},
+		update: func(ctx context.Context, c *bookstack.Client, id int, req *bookstack.PageUpdateRequest) (*bookstack.Page, error) {
+			return c.Pages.Update(ctx, id, req)
+		},
+		delete: func(ctx context.Context, c *bookstack.Client, id int) error { return c.Pages.Delete(ctx, id) },
+		bySlug: func(ctx context.Context, c *bookstack.Client, ref string) (*bookstack.Page, error) {
+			book, slug, err := splitSlugs(ref)
+			if err != nil {
+				return nil, err
+			}
+			return c.Pages.GetBySlug(ctx, book, slug)
+		},
+		id:     func(p *bookstack.Page) int { return p.ID },
+		header: []string{"ID", "BOOK", "CHAPTER", "NAME", "SLUG", "UPDATED"},
+		row: func(p *bookstack.Page) []string {
+			return []string{strconv.Itoa(p.ID), strconv.Itoa(p.BookID), itoa(p.ChapterID), p.Name, p.Slug, formatTime(p.UpdatedAt)}
+		},
+	}
+	r.extra = map[string]action{
+		"move": moveAction(r, "move", func(ctx context.Context, c *bookstack.Client, id int, target bookstack.MoveTarget) (*bookstack.Page, error) {
+			return c.Pages.Move(ctx, id, target)
+		}),
+		"copy": moveAction(r, "copy", func(ctx context.Context, c *bookstack.Client, id int, target bookstack.MoveTarget) (*bookstack.Page, error) {
+			return c.Pages.Copy(ctx, id, target)
+		}),
+		"export": func(ctx context.Context, cl *cli, args []string) error {
+			fs := flag.NewFlagSet("pages export", flag.ContinueOnError)
+			format := fs.String("format", "md", "export format: md or pdf")
+			args, err := parseFlags(cl, fs, args)
+			if err != nil {
+				return err
+			}
+			if len(args) != 1 {
+				return usageError("expected one page reference")
+			}
+			id, err := r.resolveID(ctx, cl.client, args[0])
+			if err != nil {
+				return err
+			}
+			var data []byte
+			switch *format {
+			case "md", "markdown":
+				data, err = cl.client.Pages.ExportMarkdown(ctx, id)
+			case "pdf":
+				data, err = cl.client.Pages.ExportPDF(ctx, id)
+			default:
+				return usageError("unknown export format %q", *format)
+			}
+			if err != nil {
+				return err
+			}
+			_, err = cl.stdout.Write(data)
+			return err
+		},
+		"outline": func(ctx context.Context, cl *cli, args []string) error {
+			fs := flag.NewFlagSet("pages outline", flag.ContinueOnError)
+			section := fs.String("section", "", "print the section with this anchor or heading")
+			args, err := parseFlags(cl, fs, args)
+			if err != nil {
+				return err
+			}
+			if len(args) != 1 {
+				return usageError("expected one page reference")
+			}
+			page, err := r.lookup(ctx, cl.client, args[0])
+			if err != nil {
+				return err
+			}
+			pageURL, err := cl.client.URL(ctx, page)
+			if err != nil {
+				return err
+			}
+			outline := page.Outline()
+			if *section != "" {
+				sec := outline.Find(*section)
+				if sec == nil {
+					return fmt.Errorf("page %d has no section %q", page.ID, *section)
+				}
+				if cl.json {
+					return printJSON(cl.stdout, newOutlineEntry(sec, pageURL, true))
+				}
+				_, err = fmt.Fprintln(cl.stdout, sec.HTML)
+				return err
+			}
+			entries := []outlineEntry{}
+			rows := [][]string{}
+			for sec := range outline.All() {
+				entries = append(entries, newOutlineEntry(sec, pageURL, false))
+				rows = append(rows, []string{strconv.Itoa(sec.Level), strings.Repeat("  ", sec.Level-1) + sec.Title, sec.Link(pageURL)})
+			}
+			if cl.json {
+				return printJSON(cl.stdout, entries)
+			}
+			return printTable(cl.stdout, []string{"LEVEL", "TITLE", "URL"}, rows)
+		},
+		"revisions": func(ctx context.Context, cl *cli, args []string) error {
+			fs := flag.NewFlagSet("pages revisions", flag.ContinueOnError)
+			args, err := parseFlags(cl, fs, args)
+			if err != nil {
+				return err
+			}
+			if len(args) != 1 {
+				return usageError("expected one page reference")
+			}
+			id, err := r.resolveID(ctx, cl.client, args[0])
+			if err != nil {
+				return err
+			}
+			var revs []bookstack.Revision
+			for rev, err := range cl.client.Revisions.ListAll(ctx, id) {
+				if err != nil {
+					return err
+				}
+				revs = append(revs, rev)
+			}
+			if cl.json {
+				if revs == nil {
+					revs = []bookstack.Revision{}
+				}
+				return printJSON(cl.stdout, revs)
+			}
+			rows := make([][]string, len(revs))
+			for i, rev := range revs {
+				rows[i] = []string{strconv.Itoa(rev.ID), strconv.Itoa(rev.Number), strconv.Itoa(rev.CreatedBy), formatTime(rev.CreatedAt), rev.Summary}
+			}
+			return printTable(cl.stdout, []string{"ID", "REVISION", "AUTHOR", "CREATED", "SUMMARY"}, rows)
+		},
+		"diff": func(ctx context.Context, cl *cli, args []string) error {
+			fs := flag.NewFlagSet("pages diff", flag.ContinueOnError)
+			format := fs.String("format", "markdown", "content to compare: markdown or html")
+			lines := fs.Int("context", 3, "lines of context around changes")
+			args, err := parseFlags(cl, fs, args)
+			if err != nil {
+				return err
+			}
+			if len(args) < 2 || len(args) > 3 {
+				return usageError("expected <page> <from-revision-id> [to-revision-id]")
+			}
+			id, err := r.resolveID(ctx, cl.client, args[0])
+			if err != nil {
+				return err
+			}
+			revIDs := make([]int, 2)
+			for i, arg := range args[1:] {
+				if revIDs[i], err = strconv.Atoi(arg); err != nil {
+					return usageError("invalid revision ID %q", arg)
+				}
+			}
+			f := bookstack.DiffFormat(*format)
+			if f != bookstack.DiffMarkdown && f != bookstack.DiffHTML {
+				return usageError("unknown diff format %q", *format)
+			}
+			d, err := cl.client.Revisions.Diff(ctx, id, revIDs[0], revIDs[1], f)
+			if err != nil {
+				return err
+			}
+			if cl.json {
+				return printJSON(cl.stdout, d)
+			}
+			_, err = io.WriteString(cl.stdout, d.Unified(*lines))
+			return err
+		},
+	}
+	return r
+}
+
+// outlineEntry is the JSON form of a page section printed by
+// "pages outline".
+type outlineEntry struct {
+	Level  int    `json:"level"`
+	Title  string `json:"title"`
+	Anchor string `json:"anchor"`
+	URL    string `json:"url"`
+	HTML   string `json:"html,omitempty"`
+}
+
+func newOutlineEntry(sec *markup.Section, pageURL string, withHTML bool) outlineEntry {
+	e := outlineEntry{Level: sec.Level, Title: sec.Title, Anchor: sec.Anchor, URL: sec.Link(pageURL)}
+	if withHTML {
+		e.HTML = sec.HTML
+	}
+	return e
+}
+
+func shelvesCommand() *resource[bookstack.Shelf, bookstack.ShelfCreateRequest, bookstack.ShelfUpdateRequest] {
+	return &resource[bookstack.Shelf, bookstack.ShelfCreateRequest, bookstack.ShelfUpdateRequest]{
+		name: "shelves",
+		list: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) ([]bookstack.Shelf, error) {
+			return c.Shelves.List(ctx, o)
+		},
+		listAll: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) iter.Seq2[bookstack.Shelf, error] {
+			return c.Shelves.ListAllWith(ctx, o)
+		},
+		get: func(ctx context.Context, c *bookstack.Client, id int) (*bookstack.Shelf, error) {
+			return c.Shelves.Get(ctx, id)
+		},
+		create: func(ctx context.Context, c *bookstack.Client, req *bookstack.ShelfCreateRequest) (*bookstack.Shelf, error) {
+			return c.Shelves.Create(ctx, req)
+		},
+		update: func(ctx context.Context, c *bookstack.Client, id int, req *bookstack.ShelfUpdateRequest) (*bookstack.Shelf, error) {
+			return c.Shelves.Update(ctx, id, req)
+		},
+		delete: func(ctx context.Context, c *bookstack.Client, id int) error { return c.Shelves.Delete(ctx, id) },
+		bySlug: func(ctx context.Context, c *bookstack.Client, ref string) (*bookstack.Shelf, error) {
+			return c.Shelves.GetBySlug(ctx, ref)
+		},
+		id:     func(sh *bookstack.Shelf) int { return sh.ID },
+		header: []string{"ID", "NAME", "SLUG", "UPDATED"},
+		row: func(sh *bookstack.Shelf) []string {
+			return []string{strconv.Itoa(sh.ID), sh.Name, sh.Slug, formatTime(sh.UpdatedAt)}
+		},
+	}
+}
+
+func attachmentsCommand() *resource[bookstack.Attachment, bookstack.AttachmentCreateRequest, bookstack.AttachmentUpdateRequest] {
+	r := &resource[bookstack.Attachment, bookstack.AttachmentCreateRequest, bookstack.AttachmentUpdateRequest]{
+		name: "attachments",
+		list: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) ([]bookstack.Attachment, error) {
+			return c.Attachments.List(ctx, o)
+		},
+		listAll: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) iter.Seq2[bookstack.Attachment, error] {
+			return c.Attachments.ListAllWith(ctx, o)
+		},
+		get: func(ctx context.Context, c *bookstack.Client, id int) (*bookstack.Attachment, error) {
+			return c.Attachments.Get(ctx, id)
+		},
+		create: func(ctx context.Context, c *bookstack.Client, req *bookstack.AttachmentCreateRequest) (*bookstack.Attachment, error) {
+			return c.Attachments.Create(ctx, req)
+		},
+		update: func(ctx context.Context, c *bookstack.Client, id int, req *bookstack.AttachmentUpdateRequest) (*bookstack.Attachment, error) {
+			return c.Attachments.Update(ctx, id, req)
+		},
+		delete: func(ctx context.Context, c *bookstack.Client, id int) error { return c.Attachments.Delete(ctx, id) },
+		id:     func(a *bookstack.Attachment) int { return a.ID },
+		header: []string{"ID", "PAGE", "NAME", "EXTENSION", "EXTERNAL", "UPDATED"},
+		row: func(a *bookstack.Attachment) []string {
+			return []string{strconv.Itoa(a.ID), strconv.Itoa(a.UploadedTo), a.Name, a.Extension, strconv.FormatBool(a.External), formatTime(a.UpdatedAt)}
+		},
+	}
+	r.extra = map[string]action{
+		"upload": func(ctx context.Context, cl *cli, args []string) error {
+			req, err := parseUpload(cl, "attachments upload", args)
+			if err != nil {
+				return err
+			}
+			a, err := cl.client.Attachments.Upload(ctx, &bookstack.AttachmentUploadRequest{
+				Name:       req.name,
+				UploadedTo: req.page,
+				Filename:   req.filename,
+				Data:       req.data,
+			})
+			if err != nil {
+				return err
+			}
+			return r.print(cl, a)
+		},
+	}
+	return r
+}
+
+func commentsCommand() *resource[bookstack.Comment, bookstack.CommentCreateRequest, bookstack.CommentUpdateRequest] {
+	return &resource[bookstack.Comment, bookstack.CommentCreateRequest, bookstack.CommentUpdateRequest]{
+		name: "comments",
+		list: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) ([]bookstack.Comment, error) {
+			return c.Comments.List(ctx, o)
+		},
+		listAll: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) iter.Seq2[bookstack.Comment, error] {
+			return c.Comments.ListAllWith(ctx, o)
+		},
+		get: func(ctx context.Context, c *bookstack.Client, id int) (*bookstack.Comment, error) {
+			return c.Comments.Get(ctx, id)
+		},
+		create: func(ctx context.Context, c *bookstack.Client, req *bookstack.CommentCreateRequest) (*bookstack.Comment, error) {
+			return c.Comments.Create(ctx, req)
+		},
+		update: func(ctx context.Context, c *bookstack.Client, id int, req *bookstack.CommentUpdateRequest) (*bookstack.Comment, error) {
+			return c.Comments.Update(ctx, id, req)
+		},
+		delete: func(ctx context.Context, c *bookstack.Client, id int) error { return c.Comments.Delete(ctx, id) },
+		id:     func(cm *bookstack.Comment) int { return cm.ID },
+		header: []string{"ID", "PAGE", "PARENT", "TEXT", "UPDATED"},
+		row: func(cm *bookstack.Comment) []string {
+			return []string{strconv.Itoa(cm.ID), strconv.Itoa(cm.PageID), itoa(cm.ParentID), bookstack.PreviewText(cm.HTML), formatTime(cm.UpdatedAt)}
+		},
+	}
+}
+
+// imagesCommand returns the images command. Images are added with the
+// upload action since the create request carries file data.
+func imagesCommand() *resource[bookstack.Image, struct{}, bookstack.ImageUpdateRequest] {
+	r := &resource[bookstack.Image, struct{}, bookstack.ImageUpdateRequest]{
+		name: "images",
+		list: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) ([]bookstack.Image, error) {
+			return c.Images.List(ctx, o)
+		},
+		listAll: func(ctx context.Context, c *bookstack.Client, o *bookstack.ListOptions) iter.Seq2[bookstack.Image, error] {
+			return c.Images.ListAllWith(ctx, o)
+		},
+		get: func(ctx context.Context, c *bookstack.Client, id int) (*bookstack.Image, error) {
+			return c.Images.Get(ctx, id)
+		},
+		update: func(ctx context.Context, c *bookstack.Client, id int, req *bookstack.ImageUpdateRequest) (*bookstack.Image, error) {
+			return c.Images.Update(ctx, id, req)
+		},
+		delete: func(ctx context.Context, c *bookstack.Client, id int) error { return c.Images.Delete(ctx, id) },
+		id:     func(img *bookstack.Image) int { return img.ID },
+		header: []string{"ID", "PAGE", "TYPE", "NAME", "URL"},
+		row: func(img *bookstack.Image) []string {
+			return []string{strconv.Itoa(img.ID), strconv.Itoa(img.UploadedTo), img.Type, img.Name, img.URL}
+		},
+	}
+	r.extra = map[string]action{
+		"upload": func(ctx context.Context, cl *cli, args []string) error {
+			req, err := parseUpload(cl, "images upload", args)
+			if err != nil {
+				return err
+			}
+			img, err := cl.client.Images.Create(ctx, &bookstack.ImageUploadRequest{
+				Type:       req.typ,
+				UploadedTo: req.page,
+				Name:       req.name,
+				Filename:   req.filename,
+				Data:       req.data,
+			})
+			if err != nil {
+				return err
+			}
+			return r.print(cl, img)
+		},
+	}
+	return r
+}
+
+// upload holds the parsed arguments of an upload action.
+type upload struct {
+	page     int
+	name     string
+	typ      string
+	filename string
+	data     []byte
+}
+
+// parseUpload parses "--page <id> [--name name] [--type type] <file>",
+// reading the file, or standard input when it is "-".
+func parseUpload(cl *cli, name string, args []string) (*upload, error) {
+	var u upload
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.IntVar(&u.page, "page", 0, "ID of the page to attach to")
+	fs.StringVar(&u.name, "name", "", "display name (default: the file name)")
+	if name == "images upload" {
+		fs.StringVar(&u.typ, "type", "gallery", "image type: gallery or drawio")
+	}
+	args, err := parseFlags(cl, fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, usageError("expected one file")
+	}
+	if u.page <= 0 {
+		return nil, usageError("--page is required")
+	}
+	if args[0] == "-" {
+		if u.name == "" {
+			return nil, usageError("--name is required when reading standard input")
+		}
+		u.filename = u.name
+		u.data, err = io.ReadAll(cl.stdin)
+	} else {
+		u.filename = filepath.Base(args[0])
+		u.data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+	u.name = cmp.Or(u.name, u.filename)
+	return &u, nil
+}
+
+// searchCommand runs full-text searches.
+type searchCommand struct{}
+
+func (searchCommand) usage() string {
+	return "search [--all] [--page n] [--count n] <query>"
+}
+
+func (searchCommand) run(ctx context.Context, cl *cli, args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	all := fs.Bool("all", false, "fetch every page of results")
+	page := fs.Int("page", 1, "result page, starting at 1")
+	count := fs.Int("count", 0, "results per page")
+	args, err := parseFlags(cl, fs, args)
+	if err != nil {
+		return err
+	}
+	query := joinArgs(args)
+	if query == "" {
+		return usageError("missing query")
+	}
+
+	// A single page of results also reports the total number of matches.
+	var results []bookstack.SearchResult
+	total := -1
+	if *all {
+		for res, err := range cl.client.Search.SearchAll(ctx, query) {
+			if err != nil {
+				return err
+			}
+			results = append(results, res)
+		}
+	} else {
+		resp, err := cl.client.Search.SearchPage(ctx, query, *page, *count)
+		if err != nil {
+			return err
+		}
+		results, total = resp.Results, resp.Total
+	}
+	if results == nil {
+		results = []bookstack.SearchResult{}
+	}
+
+	if cl.json {
+		if total < 0 {
+			return printJSON(cl.stdout, results)
+		}
+		return printJSON(cl.stdout, struct {
+			Total int                      `json:"total"`
+			Data  []bookstack.SearchResult `json:"data"`
+		}{total, results})
+	}
+	rows := make([][]string, len(results))
+	for i, res := range results {
+		rows[i] = []string{res.Type, strconv.Itoa(res.ID), res.Name, res.URL, bookstack.PreviewText(res.PreviewHTML.Content)}
+	}
+	if err := printTable(cl.stdout, []string{"TYPE", "ID", "NAME", "URL", "PREVIEW"}, rows); err != nil {
+		return err
+	}
+	if total >= 0 {
+		_, err = fmt.Fprintf(cl.stdout, "showing %s of %s\n", groupDigits(len(results)), groupDigits(total))
+	}
+	return err
+}
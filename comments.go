@@ -3,6 +3,7 @@ package bookstack
 import (
 	"context"
 	"fmt"
+	"iter"
 )
 
 // CommentsService handles operations on comments.
@@ -20,6 +21,16 @@ func (s *CommentsService) List(ctx context.Context, opts *ListOptions) ([]Commen
 	return resp.Data, nil
 }
 
+// ListAll returns an iterator over all comments, handling pagination automatically.
+func (s *CommentsService) ListAll(ctx context.Context) iter.Seq2[Comment, error] {
+	return listAll[Comment](ctx, s.client, "/api/comments", nil)
+}
+
+// ListAllWith returns an iterator over all comments matching opts, handling pagination automatically.
+func (s *CommentsService) ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Comment, error] {
+	return listAll[Comment](ctx, s.client, "/api/comments", opts)
+}
+
 // Get retrieves a single comment by ID.
 func (s *CommentsService) Get(ctx context.Context, id int) (*Comment, error) {
 	var c Comment
@@ -0,0 +1,251 @@
+package bookstack
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DiffFormat selects the content compared by a Diff.
+type DiffFormat string
+
+// Diff formats.
+const (
+	// DiffMarkdown compares Markdown source, falling back to HTML when
+	// either side was written in the WYSIWYG editor and has no Markdown.
+	DiffMarkdown DiffFormat = "markdown"
+	// DiffHTML compares HTML, split into one line per block element.
+	DiffHTML DiffFormat = "html"
+)
+
+// DiffOp is the kind of a DiffLine.
+type DiffOp int
+
+// Diff operations.
+const (
+	DiffEqual  DiffOp = iota // Line present in both versions
+	DiffDelete               // Line only in the old version
+	DiffInsert               // Line only in the new version
+)
+
+var diffOpNames = [...]string{"equal", "delete", "insert"}
+
+// String returns "equal", "delete" or "insert".
+func (op DiffOp) String() string {
+	if op < 0 || int(op) >= len(diffOpNames) {
+		return fmt.Sprintf("DiffOp(%d)", int(op))
+	}
+	return diffOpNames[op]
+}
+
+// MarshalText encodes the operation by name, e.g. in JSON output.
+func (op DiffOp) MarshalText() ([]byte, error) {
+	return []byte(op.String()), nil
+}
+
+// DiffLine is one line of a Diff. Text excludes the line ending.
+type DiffLine struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// Diff is a line-by-line comparison of two versions of a page.
+type Diff struct {
+	From   string     `json:"from"`   // Label of the old version, e.g. "revision 3"
+	To     string     `json:"to"`     // Label of the new version, e.g. "current"
+	Format DiffFormat `json:"format"` // Content that was compared
+	Lines  []DiffLine `json:"lines"`
+}
+
+// DiffRevisions compares the content of two revisions. The result is
+// labelled with the revision numbers, or "current" for a revision without
+// a number such as one built from the current page.
+func DiffRevisions(from, to *Revision, format DiffFormat) *Diff {
+	d := &Diff{From: from.label(), To: to.label(), Format: format}
+	if format == DiffMarkdown && (from.Markdown == "" || to.Markdown == "") {
+		d.Format = DiffHTML
+	}
+	if d.Format == DiffMarkdown {
+		d.Lines = DiffText(from.Markdown, to.Markdown)
+	} else {
+		d.Lines = DiffText(htmlLines(from.HTML), htmlLines(to.HTML))
+	}
+	return d
+}
+
+func (r *Revision) label() string {
+	if r.Number == 0 {
+		return "current"
+	}
+	return fmt.Sprintf("revision %d", r.Number)
+}
+
+// DiffText compares two texts line by line using a longest common
+// subsequence, so unchanged lines are kept in place.
+func DiffText(from, to string) []DiffLine {
+	a, b := splitLines(from), splitLines(to)
+	m := matchLines(a, b)
+	var out []DiffLine
+	j := 0
+	for i, line := range a {
+		if m[i] < 0 {
+			out = append(out, DiffLine{DiffDelete, line})
+			continue
+		}
+		for ; j < m[i]; j++ {
+			out = append(out, DiffLine{DiffInsert, b[j]})
+		}
+		out = append(out, DiffLine{DiffEqual, line})
+		j++
+	}
+	for ; j < len(b); j++ {
+		out = append(out, DiffLine{DiffInsert, b[j]})
+	}
+	return out
+}
+
+// Changed reports whether the two versions differ.
+func (d *Diff) Changed() bool {
+	return slices.ContainsFunc(d.Lines, func(l DiffLine) bool { return l.Op != DiffEqual })
+}
+
+// Stats returns the number of inserted and deleted lines.
+func (d *Diff) Stats() (inserted, deleted int) {
+	for _, l := range d.Lines {
+		switch l.Op {
+		case DiffInsert:
+			inserted++
+		case DiffDelete:
+			deleted++
+		}
+	}
+	return inserted, deleted
+}
+
+// Unified formats the diff in unified diff format with the given number of
+// context lines around each change. It returns "" if nothing changed.
+func (d *Diff) Unified(context int) string {
+	if !d.Changed() {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", d.From, d.To)
+
+	// Line numbers in the old and new version before each diff line.
+	oldNo, newNo := make([]int, len(d.Lines)+1), make([]int, len(d.Lines)+1)
+	for i, l := range d.Lines {
+		oldNo[i+1], newNo[i+1] = oldNo[i], newNo[i]
+		if l.Op != DiffInsert {
+			oldNo[i+1]++
+		}
+		if l.Op != DiffDelete {
+			newNo[i+1]++
+		}
+	}
+
+	for start := 0; start < len(d.Lines); {
+		first := slices.IndexFunc(d.Lines[start:], func(l DiffLine) bool { return l.Op != DiffEqual })
+		if first < 0 {
+			break
+		}
+		first += start
+		// Extend the hunk while the next change is within 2*context lines.
+		last := first
+		for i := first + 1; i < len(d.Lines) && i <= last+2*context+1; i++ {
+			if d.Lines[i].Op != DiffEqual {
+				last = i
+			}
+		}
+		lo, hi := max(first-context, 0), min(last+context+1, len(d.Lines))
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldNo[lo], oldNo[hi]), hunkRange(newNo[lo], newNo[hi]))
+		for _, l := range d.Lines[lo:hi] {
+			b.WriteByte(" -+"[l.Op])
+			b.WriteString(l.Text)
+			b.WriteByte('\n')
+		}
+		start = hi
+	}
+	return b.String()
+}
+
+// hunkRange formats the line range (start, end] of a hunk header.
+func hunkRange(start, end int) string {
+	n := end - start
+	switch n {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n"), "\n")
+}
+
+var blockEnd = regexp.MustCompile(`(?i)(</(?:p|h[1-6]|li|ul|ol|pre|blockquote|table|tr|div|details|summary)>|<br\s*/?>|<hr\s*/?>)\s*`)
+
+// htmlLines puts every block element of an HTML fragment on its own line,
+// so that edits show up as changed paragraphs rather than one long line.
+func htmlLines(h string) string {
+	var lines []string
+	for _, line := range strings.Split(blockEnd.ReplaceAllString(h, "$1\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matchLines returns, for every line of a, the index of the line of b it
+// is paired with in a longest common subsequence, or -1.
+func matchLines(a, b []string) []int {
+	m := make([]int, len(a))
+	for i := range m {
+		m[i] = -1
+	}
+
+	// Common prefix and suffix are matched directly to keep the table small.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		m[pre] = pre
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		m[len(a)-1-suf] = len(b) - 1 - suf
+		suf++
+	}
+	a, b = a[pre:len(a)-suf], b[pre:len(b)-suf]
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	w := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*w)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+			} else {
+				lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			m[pre+i] = pre + j
+			i++
+			j++
+		case lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return m
+}
@@ -0,0 +1,65 @@
+package bookstack
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffText(t *testing.T) {
+	got := DiffText("a\nb\nc\n", "a\nc\nd\n")
+	want := []DiffLine{
+		{DiffEqual, "a"},
+		{DiffDelete, "b"},
+		{DiffEqual, "c"},
+		{DiffInsert, "d"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffText = %v, want %v", got, want)
+	}
+	if got := DiffText("", "x"); !reflect.DeepEqual(got, []DiffLine{{DiffInsert, "x"}}) {
+		t.Errorf("from empty = %v", got)
+	}
+}
+
+func TestDiff_Unified(t *testing.T) {
+	from := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	to := "1\nTWO\n3\n4\n5\n6\n7\n8\n9\n10\n11\n"
+	d := &Diff{From: "a", To: "b", Lines: DiffText(from, to)}
+
+	want := "--- a\n+++ b\n" +
+		"@@ -1,3 +1,3 @@\n 1\n-2\n+TWO\n 3\n" +
+		"@@ -10 +10,2 @@\n 10\n+11\n"
+	if got := d.Unified(1); got != want {
+		t.Errorf("Unified(1) =\n%s\nwant\n%s", got, want)
+	}
+	if ins, del := d.Stats(); ins != 2 || del != 1 {
+		t.Errorf("Stats = %d, %d", ins, del)
+	}
+
+	same := &Diff{Lines: DiffText(from, from)}
+	if same.Changed() || same.Unified(3) != "" {
+		t.Error("identical texts reported as changed")
+	}
+}
+
+func TestDiffRevisions_HTMLFallback(t *testing.T) {
+	from := &Revision{Number: 1, HTML: `<h1 id="h">Title</h1><p id="a">Old text</p><p id="b">Kept</p>`}
+	to := &Revision{Number: 2, Markdown: "# Title", HTML: `<h1 id="h">Title</h1><p id="a">New text</p><p id="b">Kept</p>`}
+
+	d := DiffRevisions(from, to, DiffMarkdown)
+	if d.Format != DiffHTML {
+		t.Errorf("Format = %q, want html fallback", d.Format)
+	}
+	want := []DiffLine{
+		{DiffEqual, `<h1 id="h">Title</h1>`},
+		{DiffDelete, `<p id="a">Old text</p>`},
+		{DiffInsert, `<p id="a">New text</p>`},
+		{DiffEqual, `<p id="b">Kept</p>`},
+	}
+	if !reflect.DeepEqual(d.Lines, want) {
+		t.Errorf("Lines = %v", d.Lines)
+	}
+	if d.From != "revision 1" || d.To != "revision 2" {
+		t.Errorf("labels = %q, %q", d.From, d.To)
+	}
+}
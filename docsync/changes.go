@@ -0,0 +1,401 @@
+package docsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+// StateFile is the name of the file, in the root of the sync directory,
+// where PullChanges records what it has mirrored.
+const StateFile = ".docsync-state.json"
+
+// State records the items mirrored by PullChanges and the newest update
+// time seen on the instance.
+type State struct {
+	// Watermark is the newest updated_at seen by the last run, in the
+	// instance's clock.
+	Watermark time.Time `json:"watermark"`
+
+	Books    map[int]*Entry `json:"books"`
+	Chapters map[int]*Entry `json:"chapters"`
+	Pages    map[int]*Entry `json:"pages"`
+}
+
+// Entry records where an item was written and which version it was.
+type Entry struct {
+	Path      string    `json:"path"` // Directory for books and chapters, file for pages
+	Revision  int       `json:"revision,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LoadState reads the state file from the sync directory. A missing file
+// yields an empty state.
+func (s *Syncer) LoadState() (*State, error) {
+	st := &State{}
+	data, err := os.ReadFile(filepath.Join(s.dir, StateFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("parsing state: %w", err)
+		}
+	}
+	if st.Books == nil {
+		st.Books = make(map[int]*Entry)
+	}
+	if st.Chapters == nil {
+		st.Chapters = make(map[int]*Entry)
+	}
+	if st.Pages == nil {
+		st.Pages = make(map[int]*Entry)
+	}
+	return st, nil
+}
+
+func (s *Syncer) saveState(st *State) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, StateFile), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing state: %w", err)
+	}
+	return nil
+}
+
+// PullChanges mirrors every book on the instance like PullAll, but only
+// fetches the books, chapters and pages updated since the previous run.
+// Items deleted on the instance are removed locally, and items whose slug
+// or parent changed are moved. Without a state file it performs a full pull.
+//
+// Changed items are found with an updated_at filter; pages that show up
+// again with the revision count and update time already recorded are
+// skipped. Deletions are detected by comparing the listed ID sets with the
+// state, which costs one listing request per 500 items but no content
+// downloads.
+func (s *Syncer) PullChanges(ctx context.Context) (*Report, error) {
+	r := &Report{}
+	st, err := s.LoadState()
+	if err != nil {
+		return r, err
+	}
+	c := &changeRun{Syncer: s, st: st, r: r, watermark: st.Watermark}
+	if err := c.run(ctx); err != nil {
+		return r, err
+	}
+	return r, s.saveState(st)
+}
+
+// changeRun holds the state of one PullChanges or Sync call.
+type changeRun struct {
+	*Syncer
+	st        *State
+	r         *Report
+	watermark time.Time
+
+	sync  *SyncReport    // Set for a two-way Sync
+	links *regexp.Regexp // Instance links, see localizeLinks
+}
+
+// run applies remote deletions and changes to the directory and advances
+// the watermark.
+func (c *changeRun) run(ctx context.Context) error {
+	// Deletions run first so that a new item reusing a deleted item's
+	// path is not removed afterwards.
+	if err := c.removeDeleted(ctx); err != nil {
+		return err
+	}
+	if err := c.pullChangedBooks(ctx); err != nil {
+		return err
+	}
+	if err := c.pullChangedChapters(ctx); err != nil {
+		return err
+	}
+	if err := c.pullChangedPages(ctx); err != nil {
+		return err
+	}
+	c.st.Watermark = c.watermark
+	return nil
+}
+
+// since returns the list options selecting items updated after the last
+// run. Timestamps have one-second precision, so the window starts a second
+// early to catch updates made in the same second as the previous run.
+func (c *changeRun) since() *bookstack.ListOptions {
+	if c.st.Watermark.IsZero() {
+		return nil
+	}
+	t := c.st.Watermark.Add(-time.Second).UTC()
+	return &bookstack.ListOptions{Filter: map[string]string{"updated_at:gt": t.Format(time.RFC3339)}}
+}
+
+func (c *changeRun) seen(t time.Time) {
+	if t.After(c.watermark) {
+		c.watermark = t
+	}
+}
+
+// idListing lists items in the largest pages Bookstack allows, as only
+// their IDs are needed.
+var idListing = &bookstack.ListOptions{Count: 500}
+
+func (c *changeRun) removeDeleted(ctx context.Context) error {
+	if len(c.st.Books)+len(c.st.Chapters)+len(c.st.Pages) == 0 {
+		return nil
+	}
+	books, err := listIDs(c.client.Books.ListAllWith(ctx, idListing))
+	if err != nil {
+		return err
+	}
+	chapters, err := listIDs(c.client.Chapters.ListAllWith(ctx, idListing))
+	if err != nil {
+		return err
+	}
+	pages, err := listIDs(c.client.Pages.ListAllWith(ctx, idListing))
+	if err != nil {
+		return err
+	}
+
+	for id, e := range c.st.Pages {
+		if !pages[id] {
+			if _, err := c.removePage(id, e); err != nil {
+				return err
+			}
+		}
+	}
+	for id, e := range c.st.Chapters {
+		if !chapters[id] {
+			if err := c.removeDir(e.Path); err != nil {
+				return err
+			}
+			delete(c.st.Chapters, id)
+		}
+	}
+	for id, e := range c.st.Books {
+		if !books[id] {
+			if err := c.removeDir(e.Path); err != nil {
+				return err
+			}
+			delete(c.st.Books, id)
+		}
+	}
+	return nil
+}
+
+// listIDs collects the IDs of the items in seq.
+func listIDs[T any, PT interface {
+	*T
+	bookstack.Entity
+}](seq iter.Seq2[T, error]) (map[int]bool, error) {
+	ids := make(map[int]bool)
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		ids[PT(&item).EntityID()] = true
+	}
+	return ids, nil
+}
+
+func (c *changeRun) pullChangedBooks(ctx context.Context) error {
+	for book, err := range c.client.Books.ListAllWith(ctx, c.since()) {
+		if err != nil {
+			return err
+		}
+		c.seen(book.UpdatedAt)
+		prev := c.st.Books[book.ID]
+		if prev != nil && book.UpdatedAt.Equal(prev.UpdatedAt) {
+			continue
+		}
+		dir := safeName(book.Slug)
+		if prev != nil && prev.Path != dir {
+			if err := c.move(prev.Path, dir); err != nil {
+				return err
+			}
+		}
+		b, _, err := c.pullBookIndex(ctx, book.ID, c.r)
+		if err != nil {
+			return err
+		}
+		c.st.Books[b.ID] = &Entry{Path: dir, UpdatedAt: b.UpdatedAt}
+	}
+	return nil
+}
+
+func (c *changeRun) pullChangedChapters(ctx context.Context) error {
+	for ch, err := range c.client.Chapters.ListAllWith(ctx, c.since()) {
+		if err != nil {
+			return err
+		}
+		c.seen(ch.UpdatedAt)
+		prev := c.st.Chapters[ch.ID]
+		if prev != nil && ch.UpdatedAt.Equal(prev.UpdatedAt) {
+			continue
+		}
+		book := c.st.Books[ch.BookID]
+		if book == nil {
+			continue // Book not visible; its chapters are skipped as in PullAll
+		}
+		dir := path.Join(book.Path, safeName(ch.Slug))
+		if prev != nil && prev.Path != dir {
+			if err := c.move(prev.Path, dir); err != nil {
+				return err
+			}
+		}
+		full, _, err := c.pullChapter(ctx, filepath.FromSlash(book.Path), ch.ID, c.r)
+		if err != nil {
+			return err
+		}
+		c.st.Chapters[ch.ID] = &Entry{Path: dir, UpdatedAt: full.UpdatedAt}
+	}
+	return nil
+}
+
+func (c *changeRun) pullChangedPages(ctx context.Context) error {
+	for page, err := range c.client.Pages.ListAllWith(ctx, c.since()) {
+		if err != nil {
+			return err
+		}
+		if page.Draft {
+			continue
+		}
+		c.seen(page.UpdatedAt)
+		prev := c.st.Pages[page.ID]
+		if prev != nil && page.Revision == prev.Revision && page.UpdatedAt.Equal(prev.UpdatedAt) {
+			continue
+		}
+		dir := ""
+		if ch := c.st.Chapters[page.ChapterID]; page.ChapterID != 0 && ch != nil {
+			dir = ch.Path
+		} else if book := c.st.Books[page.BookID]; book != nil {
+			dir = book.Path
+		} else {
+			continue
+		}
+
+		var full *bookstack.Page
+		var file string
+		if c.sync != nil {
+			full, file, err = c.syncPage(ctx, filepath.FromSlash(dir), prev, page.ID)
+		} else {
+			full, file, err = c.pullPage(ctx, filepath.FromSlash(dir), page.ID, c.r)
+		}
+		if err != nil {
+			return err
+		}
+		file = filepath.ToSlash(file)
+		if prev != nil && prev.Path != file {
+			if err := c.removeFile(prev.Path); err != nil {
+				return err
+			}
+		}
+		c.st.Pages[page.ID] = &Entry{Path: file, Revision: full.Revision, UpdatedAt: full.UpdatedAt}
+	}
+	return nil
+}
+
+// move renames a book or chapter directory and updates the paths of the
+// items recorded below it.
+func (c *changeRun) move(from, to string) error {
+	src := filepath.Join(c.dir, filepath.FromSlash(from))
+	dst := filepath.Join(c.dir, filepath.FromSlash(to))
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("moving %s: %w", from, err)
+	}
+	for _, entries := range []map[int]*Entry{c.st.Books, c.st.Chapters, c.st.Pages} {
+		for _, e := range entries {
+			if e.Path == from {
+				e.Path = to
+			} else if rest, ok := strings.CutPrefix(e.Path, from+"/"); ok {
+				e.Path = path.Join(to, rest)
+			}
+		}
+	}
+	return nil
+}
+
+// removePage removes a page deleted on the instance. In a two-way sync,
+// pages with local edits are kept instead, and kept is true.
+func (c *changeRun) removePage(id int, e *Entry) (kept bool, err error) {
+	delete(c.st.Pages, id)
+	if c.sync != nil {
+		modified := c.locallyModified(id, e)
+		c.removeBase(id)
+		if modified {
+			c.sync.Kept = append(c.sync.Kept, e.Path)
+			return true, nil
+		}
+	}
+	if err := c.removeFile(e.Path); err != nil {
+		return false, err
+	}
+	c.r.Deleted = append(c.r.Deleted, e.Path)
+	return false, nil
+}
+
+// removeFile deletes a page file and its assets directory.
+func (c *changeRun) removeFile(rel string) error {
+	file := filepath.Join(c.dir, filepath.FromSlash(rel))
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing %s: %w", rel, err)
+	}
+	if err := os.RemoveAll(strings.TrimSuffix(file, ".md") + assetsSuffix); err != nil {
+		return fmt.Errorf("removing assets of %s: %w", rel, err)
+	}
+	return nil
+}
+
+// removeDir deletes a book or chapter directory and forgets the items in
+// it. In a two-way sync, pages with local edits are kept along with the
+// directory.
+func (c *changeRun) removeDir(rel string) error {
+	for id, e := range c.st.Chapters {
+		if strings.HasPrefix(e.Path, rel+"/") {
+			delete(c.st.Chapters, id)
+		}
+	}
+	kept := false
+	for id, e := range c.st.Pages {
+		if !strings.HasPrefix(e.Path, rel+"/") {
+			continue
+		}
+		if c.sync == nil {
+			delete(c.st.Pages, id)
+			continue
+		}
+		k, err := c.removePage(id, e)
+		if err != nil {
+			return err
+		}
+		kept = kept || k
+	}
+	if kept {
+		return nil
+	}
+	if err := os.RemoveAll(filepath.Join(c.dir, filepath.FromSlash(rel))); err != nil {
+		return fmt.Errorf("removing %s: %w", rel, err)
+	}
+	c.r.Deleted = append(c.r.Deleted, rel)
+	return nil
+}
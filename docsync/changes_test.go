@@ -0,0 +1,126 @@
+package docsync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/bookstacktest"
+)
+
+func TestSyncer_PullChanges(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	srv.SetClock(func() time.Time { return now })
+
+	book := srv.AddBook(bookstack.Book{Name: "Ops"})
+	ch := srv.AddChapter(bookstack.Chapter{BookID: book.ID, Name: "Runbooks"})
+	deploy := srv.AddPage(bookstack.Page{ChapterID: ch.ID, Name: "Deploy", Markdown: "v1"})
+	rollback := srv.AddPage(bookstack.Page{ChapterID: ch.ID, Name: "Rollback", Markdown: "v1"})
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := New(srv.Client(), dir)
+	r, err := s.PullChanges(ctx)
+	if err != nil {
+		t.Fatalf("first PullChanges: %v", err)
+	}
+	if r.Books != 1 || r.Chapters != 1 || r.Pages != 2 {
+		t.Errorf("first report = %+v", r)
+	}
+	st, err := s.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if !st.Watermark.Equal(now) || st.Pages[deploy.ID].Path != "ops/runbooks/deploy.md" {
+		t.Errorf("state = %+v, page = %+v", st, st.Pages[deploy.ID])
+	}
+
+	srv.ResetRequests()
+	r, err = s.PullChanges(ctx)
+	if err != nil {
+		t.Fatalf("second PullChanges: %v", err)
+	}
+	if r.Books != 0 || r.Chapters != 0 || r.Pages != 0 || len(r.Deleted) != 0 {
+		t.Errorf("unchanged instance produced %+v", r)
+	}
+	if got := "GET /api/pages/" + strconv.Itoa(deploy.ID); slices.Contains(srv.Requests(), got) {
+		t.Error("unchanged page was fetched")
+	}
+	if !slices.Contains(srv.Requests(), "GET /api/pages?count=500&offset=0") {
+		t.Errorf("deleted pages not detected with 500-item listings: %v", srv.Requests())
+	}
+
+	now = now.Add(time.Hour)
+	c := srv.Client()
+	if _, err := c.Pages.Update(ctx, deploy.ID, &bookstack.PageUpdateRequest{Markdown: "v2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Pages.Delete(ctx, rollback.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Chapters.Update(ctx, ch.ID, &bookstack.ChapterUpdateRequest{Name: "Playbooks"}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = s.PullChanges(ctx)
+	if err != nil {
+		t.Fatalf("third PullChanges: %v", err)
+	}
+	if r.Pages != 1 || r.Chapters != 1 {
+		t.Errorf("third report = %+v", r)
+	}
+	if !slices.Equal(r.Deleted, []string{"ops/runbooks/rollback.md"}) {
+		t.Errorf("Deleted = %v", r.Deleted)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "ops", "playbooks", "deploy.md"))
+	if err != nil {
+		t.Fatalf("page not moved with its chapter: %v", err)
+	}
+	doc, err := ParseDocument(data)
+	if err != nil || strings.TrimSpace(doc.Body) != "v2" {
+		t.Errorf("deploy = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ops", "runbooks")); !os.IsNotExist(err) {
+		t.Error("old chapter directory still exists")
+	}
+}
+
+func TestSyncer_PullChangesRemovesBook(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	a := srv.AddBook(bookstack.Book{Name: "A"})
+	srv.AddPage(bookstack.Page{BookID: a.ID, Name: "Intro", Markdown: "hi"})
+	srv.AddBook(bookstack.Book{Name: "B"})
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := New(srv.Client(), dir)
+	if _, err := s.PullChanges(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Client().Books.Delete(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	r, err := s.PullChanges(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a")); !os.IsNotExist(err) {
+		t.Error("deleted book directory still exists")
+	}
+	if !slices.Contains(r.Deleted, "a") {
+		t.Errorf("Deleted = %v", r.Deleted)
+	}
+	st, _ := s.LoadState()
+	if len(st.Books) != 1 || len(st.Pages) != 0 {
+		t.Errorf("state = %+v", st)
+	}
+}
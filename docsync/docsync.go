@@ -0,0 +1,109 @@
+// Package docsync mirrors Bookstack content to and from a local directory
+// of Markdown files.
+//
+// A pull writes one directory per book and chapter and one .md file per page:
+//
+//	<dir>/
+//	    <book-slug>/
+//	        _index.md                  book metadata and description
+//	        <page-slug>.md             page directly in the book
+//	        <page-slug>.assets/        attachments and images of that page
+//	        <chapter-slug>/
+//	            _index.md              chapter metadata and description
+//	            <page-slug>.md
+//
+// Each file starts with YAML front matter (see FrontMatter) recording the
+// item's ID, slug, tags, revision count and last update time. PullChanges
+// keeps a state file next to the tree so repeated pulls only fetch what
+// changed. A push reads
+// the same layout back and publishes it, so a docs-as-code repository can be
+// the source of truth for a book. Sync does both, merging concurrent edits.
+package docsync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+// indexFile holds the metadata of a book or chapter directory.
+const indexFile = "_index.md"
+
+// assetsSuffix is appended to a page's slug to name its assets directory.
+const assetsSuffix = ".assets"
+
+// Syncer synchronizes Bookstack content with a local directory.
+type Syncer struct {
+	client *bookstack.Client
+	dir    string
+
+	// ConvertLocally converts pages written in the WYSIWYG editor with
+	// markup.ToMarkdown instead of Bookstack's Markdown export, saving a
+	// request per page. The Markdown differs from the export's, so turning
+	// it on for an existing mirror rewrites those pages on the next pull.
+	ConvertLocally bool
+}
+
+// New creates a Syncer that mirrors content between client and dir.
+func New(client *bookstack.Client, dir string) *Syncer {
+	return &Syncer{client: client, dir: dir}
+}
+
+// Report summarizes the work done by a sync operation. For a push, the
+// counts cover items created or changed in Bookstack.
+type Report struct {
+	Books       int
+	Chapters    int
+	Pages       int
+	Attachments int
+	Images      int
+
+	// Written lists the files created or changed, relative to the sync directory.
+	Written []string
+
+	// Deleted lists the files and directories removed by PullChanges
+	// because their items no longer exist on the instance.
+	Deleted []string
+
+	// Conflicts lists the files Push skipped because they contain
+	// unresolved conflict markers or because their page changed on
+	// Bookstack while it was being pushed.
+	Conflicts []string
+}
+
+// writeFile writes data to the path relative to the sync directory,
+// creating parent directories. Unchanged files are left alone.
+func (s *Syncer) writeFile(rel string, data []byte, r *Report) error {
+	path := filepath.Join(s.dir, rel)
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, data) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", rel, err)
+	}
+	r.Written = append(r.Written, filepath.ToSlash(rel))
+	return nil
+}
+
+// safeName makes s usable as a single path element.
+func safeName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', 0:
+			return '-'
+		}
+		return r
+	}, s)
+	s = strings.Trim(s, ". ")
+	if s == "" {
+		return "untitled"
+	}
+	return s
+}
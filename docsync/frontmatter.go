@@ -0,0 +1,269 @@
+package docsync
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+// Document types recorded in front matter.
+const (
+	TypeBook    = "book"
+	TypeChapter = "chapter"
+	TypePage    = "page"
+)
+
+// FrontMatter is the metadata block at the top of a mirrored Markdown file.
+// It is written as a small subset of YAML: scalar keys plus the tags and
+// attachments lists.
+type FrontMatter struct {
+	ID          int
+	Type        string // TypeBook, TypeChapter or TypePage; pages when empty
+	Name        string
+	Slug        string
+	BookID      int
+	ChapterID   int
+	Priority    int
+	Revision    int
+	UpdatedAt   time.Time
+	Tags        []bookstack.Tag
+	Attachments []AttachmentRef
+}
+
+// AttachmentRef records a page attachment in front matter. File is a path
+// relative to the page file for uploaded files; Link is set for external links.
+type AttachmentRef struct {
+	ID   int
+	Name string
+	File string
+	Link string
+}
+
+// Document is a Markdown file with front matter.
+type Document struct {
+	FrontMatter
+	Body string
+}
+
+const fence = "---"
+
+// Bytes encodes the document with its front matter.
+func (d *Document) Bytes() []byte {
+	var b bytes.Buffer
+	fm := &d.FrontMatter
+	b.WriteString(fence + "\n")
+	writeInt(&b, "id", fm.ID)
+	writeString(&b, "type", fm.Type)
+	writeString(&b, "name", fm.Name)
+	writeString(&b, "slug", fm.Slug)
+	writeInt(&b, "book_id", fm.BookID)
+	writeInt(&b, "chapter_id", fm.ChapterID)
+	writeInt(&b, "priority", fm.Priority)
+	writeInt(&b, "revision", fm.Revision)
+	if !fm.UpdatedAt.IsZero() {
+		fmt.Fprintf(&b, "updated_at: %s\n", fm.UpdatedAt.UTC().Format(time.RFC3339))
+	}
+	if len(fm.Tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, t := range fm.Tags {
+			fmt.Fprintf(&b, "  - name: %s\n", strconv.Quote(t.Name))
+			if t.Value != "" {
+				fmt.Fprintf(&b, "    value: %s\n", strconv.Quote(t.Value))
+			}
+		}
+	}
+	if len(fm.Attachments) > 0 {
+		b.WriteString("attachments:\n")
+		for _, a := range fm.Attachments {
+			fmt.Fprintf(&b, "  - id: %d\n", a.ID)
+			fmt.Fprintf(&b, "    name: %s\n", strconv.Quote(a.Name))
+			if a.File != "" {
+				fmt.Fprintf(&b, "    file: %s\n", strconv.Quote(a.File))
+			}
+			if a.Link != "" {
+				fmt.Fprintf(&b, "    link: %s\n", strconv.Quote(a.Link))
+			}
+		}
+	}
+	b.WriteString(fence + "\n\n")
+	b.WriteString(strings.TrimLeft(d.Body, "\n"))
+	if !strings.HasSuffix(d.Body, "\n") {
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+func writeInt(b *bytes.Buffer, key string, v int) {
+	if v != 0 {
+		fmt.Fprintf(b, "%s: %d\n", key, v)
+	}
+}
+
+func writeString(b *bytes.Buffer, key, v string) {
+	if v != "" {
+		fmt.Fprintf(b, "%s: %s\n", key, strconv.Quote(v))
+	}
+}
+
+// ParseDocument splits data into front matter and body. Files without a
+// front matter block are returned with an empty FrontMatter.
+// "title" is accepted as an alias for "name".
+func ParseDocument(data []byte) (*Document, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	doc := &Document{}
+	if !strings.HasPrefix(text, fence+"\n") {
+		doc.Body = text
+		return doc, nil
+	}
+	header, body, ok := strings.Cut(text[len(fence)+1:], "\n"+fence+"\n")
+	if !ok {
+		if h, found := strings.CutSuffix(text[len(fence)+1:], "\n"+fence); found {
+			header, body, ok = h, "", true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unterminated front matter")
+	}
+	if err := parseFrontMatter(header, &doc.FrontMatter); err != nil {
+		return nil, err
+	}
+	doc.Body = strings.TrimLeft(body, "\n")
+	return doc, nil
+}
+
+func parseFrontMatter(header string, fm *FrontMatter) error {
+	var (
+		list string         // current list key, if inside a list
+		item map[string]any // current list item
+	)
+	flush := func() {
+		if item == nil {
+			return
+		}
+		switch list {
+		case "tags":
+			fm.Tags = append(fm.Tags, bookstack.Tag{Name: str(item["name"]), Value: str(item["value"]), Order: len(fm.Tags)})
+		case "attachments":
+			id, _ := item["id"].(int)
+			fm.Attachments = append(fm.Attachments, AttachmentRef{ID: id, Name: str(item["name"]), File: str(item["file"]), Link: str(item["link"])})
+		}
+		item = nil
+	}
+
+	sc := bufio.NewScanner(strings.NewReader(header))
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indented := line[0] == ' ' || line[0] == '\t'
+		if indented && list != "" {
+			entry := trimmed
+			if rest, ok := strings.CutPrefix(entry, "-"); ok {
+				flush()
+				item = map[string]any{}
+				entry = strings.TrimSpace(rest)
+				if entry == "" {
+					continue
+				}
+			}
+			if item == nil {
+				return fmt.Errorf("front matter line %d: list entry outside item", n)
+			}
+			key, val, err := parseKeyValue(entry)
+			if err != nil {
+				return fmt.Errorf("front matter line %d: %w", n, err)
+			}
+			item[key] = val
+			continue
+		}
+
+		flush()
+		list = ""
+		key, val, err := parseKeyValue(trimmed)
+		if err != nil {
+			return fmt.Errorf("front matter line %d: %w", n, err)
+		}
+		switch key {
+		case "tags", "attachments":
+			list = key
+			continue
+		case "id":
+			fm.ID = num(val)
+		case "type":
+			fm.Type = str(val)
+		case "name", "title":
+			fm.Name = str(val)
+		case "slug":
+			fm.Slug = str(val)
+		case "book_id":
+			fm.BookID = num(val)
+		case "chapter_id":
+			fm.ChapterID = num(val)
+		case "priority":
+			fm.Priority = num(val)
+		case "revision":
+			fm.Revision = num(val)
+		case "updated_at":
+			t, err := time.Parse(time.RFC3339, str(val))
+			if err != nil {
+				return fmt.Errorf("front matter line %d: invalid updated_at: %w", n, err)
+			}
+			fm.UpdatedAt = t
+		}
+	}
+	flush()
+	return sc.Err()
+}
+
+// parseKeyValue parses "key: value", unquoting double- or single-quoted
+// strings and converting bare integers. A value of [] yields nil.
+func parseKeyValue(s string) (string, any, error) {
+	key, val, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", nil, fmt.Errorf("expected key: value, got %q", s)
+	}
+	key = strings.TrimSpace(key)
+	val = strings.TrimSpace(val)
+	switch {
+	case val == "" || val == "[]":
+		return key, nil, nil
+	case strings.HasPrefix(val, `"`):
+		u, err := strconv.Unquote(val)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid quoted value for %s: %w", key, err)
+		}
+		return key, u, nil
+	case strings.HasPrefix(val, "'") && strings.HasSuffix(val, "'") && len(val) >= 2:
+		return key, strings.ReplaceAll(val[1:len(val)-1], "''", "'"), nil
+	}
+	if i := strings.Index(val, " #"); i >= 0 {
+		val = strings.TrimSpace(val[:i])
+	}
+	if n, err := strconv.Atoi(val); err == nil {
+		return key, n, nil
+	}
+	return key, val, nil
+}
+
+func str(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	}
+	return ""
+}
+
+func num(v any) int {
+	n, _ := v.(int)
+	return n
+}
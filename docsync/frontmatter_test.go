@@ -0,0 +1,76 @@
+package docsync
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+func TestDocument_RoundTrip(t *testing.T) {
+	doc := &Document{
+		FrontMatter: FrontMatter{
+			ID:        42,
+			Type:      TypePage,
+			Name:      `Deploy "Guide": v2`,
+			Slug:      "deploy-guide",
+			BookID:    1,
+			ChapterID: 3,
+			Priority:  2,
+			Revision:  5,
+			UpdatedAt: time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC),
+			Tags: []bookstack.Tag{
+				{Name: "Category", Value: "Ops", Order: 0},
+				{Name: "reviewed", Order: 1},
+			},
+			Attachments: []AttachmentRef{
+				{ID: 7, Name: "diagram", File: "deploy-guide.assets/diagram.pdf"},
+				{ID: 8, Name: "Runbook", Link: "https://example.com/runbook"},
+			},
+		},
+		Body: "# Deploy\n\nRun it.\n",
+	}
+
+	got, err := ParseDocument(doc.Bytes())
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if !reflect.DeepEqual(got, doc) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", got, doc)
+	}
+}
+
+func TestParseDocument_HandWritten(t *testing.T) {
+	data := "---\ntitle: 'It''s here'\ntags:\n  - name: team\n    value: platform # owner\n---\nBody text\n"
+	doc, err := ParseDocument([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if doc.Name != "It's here" {
+		t.Errorf("Name = %q", doc.Name)
+	}
+	if len(doc.Tags) != 1 || doc.Tags[0].Value != "platform" {
+		t.Errorf("Tags = %+v", doc.Tags)
+	}
+	if doc.Body != "Body text\n" {
+		t.Errorf("Body = %q", doc.Body)
+	}
+}
+
+func TestParseDocument_NoFrontMatter(t *testing.T) {
+	doc, err := ParseDocument([]byte("# Just markdown\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if doc.ID != 0 || !strings.HasPrefix(doc.Body, "# Just") {
+		t.Errorf("got %+v", doc)
+	}
+}
+
+func TestParseDocument_Unterminated(t *testing.T) {
+	if _, err := ParseDocument([]byte("---\nid: 1\n")); err == nil {
+		t.Error("expected error")
+	}
+}
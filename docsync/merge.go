@@ -0,0 +1,130 @@
+package docsync
+
+import (
+	"slices"
+	"strings"
+)
+
+// Conflict markers written around overlapping edits.
+const (
+	markerLocal  = "<<<<<<< local"
+	markerSep    = "======="
+	markerRemote = ">>>>>>> bookstack"
+)
+
+// merge3 merges the line-based changes made in local and remote relative to
+// base. Regions changed on only one side take that side's version; regions
+// changed differently on both sides are wrapped in conflict markers, and
+// conflict is reported as true.
+func merge3(base, local, remote string) (merged string, conflict bool) {
+	b, l, r := splitLines(base), splitLines(local), splitLines(remote)
+	ml, mr := matchLines(b, l), matchLines(b, r)
+
+	var out []string
+	i, j, k := 0, 0, 0
+	for {
+		// Find the next base line kept unchanged on both sides.
+		next := i
+		for next < len(b) && (ml[next] < 0 || mr[next] < 0) {
+			next++
+		}
+		endL, endR := len(l), len(r)
+		if next < len(b) {
+			endL, endR = ml[next], mr[next]
+		}
+
+		bc, lc, rc := b[i:next], l[j:endL], r[k:endR]
+		switch {
+		case slices.Equal(lc, bc) || slices.Equal(lc, rc):
+			out = append(out, rc...)
+		case slices.Equal(rc, bc):
+			out = append(out, lc...)
+		default:
+			conflict = true
+			out = append(out, markerLocal+"\n")
+			out = appendTerminated(out, lc)
+			out = append(out, markerSep+"\n")
+			out = appendTerminated(out, rc)
+			out = append(out, markerRemote+"\n")
+		}
+
+		if next == len(b) {
+			break
+		}
+		out = append(out, b[next])
+		i, j, k = next+1, endL+1, endR+1
+	}
+	return strings.Join(out, ""), conflict
+}
+
+// appendTerminated appends lines, making sure the last one ends in a newline.
+func appendTerminated(out, lines []string) []string {
+	out = append(out, lines...)
+	if n := len(out); n > 0 && !strings.HasSuffix(out[n-1], "\n") {
+		out[n-1] += "\n"
+	}
+	return out
+}
+
+// hasConflictMarkers reports whether body contains an unresolved conflict.
+func hasConflictMarkers(body string) bool {
+	for line := range strings.Lines(body) {
+		if line = strings.TrimSuffix(line, "\n"); line == markerLocal || line == markerRemote {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLines splits s into lines, each keeping its trailing newline.
+func splitLines(s string) []string {
+	return slices.Collect(strings.Lines(s))
+}
+
+// matchLines returns, for every line of a, the index of the line of b it
+// is paired with in a longest common subsequence, or -1.
+func matchLines(a, b []string) []int {
+	m := make([]int, len(a))
+	for i := range m {
+		m[i] = -1
+	}
+
+	// Common prefix and suffix are matched directly to keep the table small.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		m[pre] = pre
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		m[len(a)-1-suf] = len(b) - 1 - suf
+		suf++
+	}
+	a, b = a[pre:len(a)-suf], b[pre:len(b)-suf]
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	w := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*w)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+			} else {
+				lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			m[pre+i] = pre + j
+			i++
+			j++
+		case lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return m
+}
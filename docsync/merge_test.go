@@ -0,0 +1,58 @@
+package docsync
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name                string
+		base, local, remote string
+		want                string
+		wantConflict        bool
+	}{
+		{
+			name: "unchanged", base: "a\nb\n", local: "a\nb\n", remote: "a\nb\n",
+			want: "a\nb\n",
+		},
+		{
+			name: "local only", base: "a\nb\n", local: "a\nB\n", remote: "a\nb\n",
+			want: "a\nB\n",
+		},
+		{
+			name: "remote only", base: "a\nb\n", local: "a\nb\n", remote: "a\nb\nc\n",
+			want: "a\nb\nc\n",
+		},
+		{
+			name: "separate lines", base: "a\nb\nc\nd\n", local: "A\nb\nc\nd\n", remote: "a\nb\nc\nD\n",
+			want: "A\nb\nc\nD\n",
+		},
+		{
+			name: "same change", base: "a\nb\n", local: "a\nx\n", remote: "a\nx\n",
+			want: "a\nx\n",
+		},
+		{
+			name: "insert and delete", base: "a\nb\nc\n", local: "a\nnew\nb\nc\n", remote: "a\nb\n",
+			want: "a\nnew\nb\n",
+		},
+		{
+			name: "overlap", base: "a\nb\nc\n", local: "a\nlocal\nc\n", remote: "a\nremote\nc\n",
+			want:         "a\n" + markerLocal + "\nlocal\n" + markerSep + "\nremote\n" + markerRemote + "\nc\n",
+			wantConflict: true,
+		},
+		{
+			name: "no base", base: "", local: "x\n", remote: "y\n",
+			want:         markerLocal + "\nx\n" + markerSep + "\ny\n" + markerRemote + "\n",
+			wantConflict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflict := merge3(tt.base, tt.local, tt.remote)
+			if got != tt.want || conflict != tt.wantConflict {
+				t.Errorf("merge3 = %q, %v; want %q, %v", got, conflict, tt.want, tt.wantConflict)
+			}
+			if hasConflictMarkers(got) != tt.wantConflict {
+				t.Errorf("hasConflictMarkers = %v", !tt.wantConflict)
+			}
+		})
+	}
+}
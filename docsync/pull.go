@@ -0,0 +1,336 @@
+package docsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+// PullAll mirrors every book on the instance.
+func (s *Syncer) PullAll(ctx context.Context) (*Report, error) {
+	r := &Report{}
+	for book, err := range s.client.Books.ListAll(ctx) {
+		if err != nil {
+			return r, err
+		}
+		if err := s.pullBook(ctx, book.ID, r); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// PullShelf mirrors every book on a shelf.
+func (s *Syncer) PullShelf(ctx context.Context, shelfID int) (*Report, error) {
+	r := &Report{}
+	shelf, err := s.client.Shelves.Get(ctx, shelfID)
+	if err != nil {
+		return r, err
+	}
+	for _, book := range shelf.Books {
+		if err := s.pullBook(ctx, book.ID, r); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// PullBook mirrors a single book.
+func (s *Syncer) PullBook(ctx context.Context, bookID int) (*Report, error) {
+	r := &Report{}
+	return r, s.pullBook(ctx, bookID, r)
+}
+
+func (s *Syncer) pullBook(ctx context.Context, bookID int, r *Report) error {
+	book, bookDir, err := s.pullBookIndex(ctx, bookID, r)
+	if err != nil {
+		return err
+	}
+
+	byBook := &bookstack.ListOptions{Filter: map[string]string{"book_id": strconv.Itoa(book.ID)}}
+	chapterDirs := make(map[int]string)
+	for ch, err := range s.client.Chapters.ListAllWith(ctx, byBook) {
+		if err != nil {
+			return err
+		}
+		_, dir, err := s.pullChapter(ctx, bookDir, ch.ID, r)
+		if err != nil {
+			return err
+		}
+		chapterDirs[ch.ID] = dir
+	}
+
+	for page, err := range s.client.Pages.ListAllWith(ctx, byBook) {
+		if err != nil {
+			return err
+		}
+		if page.Draft {
+			continue
+		}
+		dir := bookDir
+		if page.ChapterID != 0 {
+			if d, ok := chapterDirs[page.ChapterID]; ok {
+				dir = d
+			}
+		}
+		if _, _, err := s.pullPage(ctx, dir, page.ID, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pullBookIndex writes a book's index file and returns the book and its directory.
+func (s *Syncer) pullBookIndex(ctx context.Context, bookID int, r *Report) (*bookstack.Book, string, error) {
+	book, err := s.client.Books.Get(ctx, bookID)
+	if err != nil {
+		return nil, "", fmt.Errorf("getting book %d: %w", bookID, err)
+	}
+	bookDir := safeName(book.Slug)
+	doc := &Document{
+		FrontMatter: FrontMatter{
+			ID:        book.ID,
+			Type:      TypeBook,
+			Name:      book.Name,
+			Slug:      book.Slug,
+			UpdatedAt: book.UpdatedAt,
+			Tags:      book.Tags,
+		},
+		Body: book.Description,
+	}
+	if err := s.writeFile(filepath.Join(bookDir, indexFile), doc.Bytes(), r); err != nil {
+		return nil, "", err
+	}
+	r.Books++
+	return book, bookDir, nil
+}
+
+// pullChapter writes a chapter's index file and returns the chapter and its directory.
+func (s *Syncer) pullChapter(ctx context.Context, bookDir string, chapterID int, r *Report) (*bookstack.Chapter, string, error) {
+	ch, err := s.client.Chapters.Get(ctx, chapterID)
+	if err != nil {
+		return nil, "", fmt.Errorf("getting chapter %d: %w", chapterID, err)
+	}
+	dir := filepath.Join(bookDir, safeName(ch.Slug))
+	doc := &Document{
+		FrontMatter: FrontMatter{
+			ID:        ch.ID,
+			Type:      TypeChapter,
+			Name:      ch.Name,
+			Slug:      ch.Slug,
+			BookID:    ch.BookID,
+			Priority:  ch.Priority,
+			UpdatedAt: ch.UpdatedAt,
+			Tags:      ch.Tags,
+		},
+		Body: ch.Description,
+	}
+	if err := s.writeFile(filepath.Join(dir, indexFile), doc.Bytes(), r); err != nil {
+		return nil, "", err
+	}
+	r.Chapters++
+	return ch, dir, nil
+}
+
+// pullPage writes a page's Markdown file along with its attachments and
+// images, returning the page and the file's path.
+func (s *Syncer) pullPage(ctx context.Context, dir string, pageID int, r *Report) (*bookstack.Page, string, error) {
+	page, doc, file, err := s.renderPage(ctx, dir, pageID, r)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.writeFile(file, doc.Bytes(), r); err != nil {
+		return nil, "", err
+	}
+	r.Pages++
+	return page, file, nil
+}
+
+// pageMarkdown returns the Markdown of a page fetched with its content:
+// the source of Markdown pages and, for WYSIWYG pages, Bookstack's export
+// or the HTML converted locally if ConvertLocally is set.
+func (s *Syncer) pageMarkdown(ctx context.Context, page *bookstack.Page) (string, error) {
+	if page.Markdown != "" || s.ConvertLocally {
+		return page.MarkdownContent(), nil
+	}
+	data, err := s.client.Pages.ExportMarkdown(ctx, page.ID)
+	if err != nil {
+		return "", fmt.Errorf("exporting page %d: %w", page.ID, err)
+	}
+	return string(data), nil
+}
+
+// renderPage fetches a page and builds its local document, saving its
+// attachments and images. It returns the page, the document and the path
+// the document belongs at.
+func (s *Syncer) renderPage(ctx context.Context, dir string, pageID int, r *Report) (*bookstack.Page, *Document, string, error) {
+	page, err := s.client.Pages.Get(ctx, pageID)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("getting page %d: %w", pageID, err)
+	}
+
+	body, err := s.pageMarkdown(ctx, page)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	name := safeName(page.Slug)
+	assets := name + assetsSuffix
+	fm := FrontMatter{
+		ID:        page.ID,
+		Type:      TypePage,
+		Name:      page.Name,
+		Slug:      page.Slug,
+		BookID:    page.BookID,
+		ChapterID: page.ChapterID,
+		Priority:  page.Priority,
+		Revision:  page.Revision,
+		UpdatedAt: page.UpdatedAt,
+		Tags:      page.Tags,
+	}
+
+	byPage := &bookstack.ListOptions{Filter: map[string]string{"uploaded_to": strconv.Itoa(page.ID)}}
+	for a, err := range s.client.Attachments.ListAllWith(ctx, byPage) {
+		if err != nil {
+			return nil, nil, "", err
+		}
+		ref, err := s.pullAttachment(ctx, dir, assets, a.ID, r)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		fm.Attachments = append(fm.Attachments, ref)
+	}
+
+	body, err = s.pullImages(ctx, dir, assets, body, r)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	doc := &Document{FrontMatter: fm, Body: body}
+	return page, doc, filepath.Join(dir, name+".md"), nil
+}
+
+func (s *Syncer) pullAttachment(ctx context.Context, dir, assets string, id int, r *Report) (AttachmentRef, error) {
+	a, err := s.client.Attachments.Get(ctx, id)
+	if err != nil {
+		return AttachmentRef{}, fmt.Errorf("getting attachment %d: %w", id, err)
+	}
+	ref := AttachmentRef{ID: a.ID, Name: a.Name}
+	if a.External {
+		ref.Link = a.Content
+		return ref, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(a.Content)
+	if err != nil {
+		return ref, fmt.Errorf("decoding attachment %d: %w", a.ID, err)
+	}
+	file := safeName(a.Name)
+	if a.Extension != "" && path.Ext(file) != "."+a.Extension {
+		file += "." + a.Extension
+	}
+	ref.File = path.Join(assets, file)
+	if err := s.writeFile(filepath.Join(dir, filepath.FromSlash(ref.File)), data, r); err != nil {
+		return ref, err
+	}
+	r.Attachments++
+	return ref, nil
+}
+
+// imageURL matches image upload URLs, absolute or root-relative.
+var imageURL = regexp.MustCompile(`(?:https?://[^\s"'()<>]+)?/uploads/images/[^\s"'()<>]+`)
+
+// pullImages downloads images hosted on the instance that body references,
+// saving them in the assets directory and rewriting the references to
+// relative paths.
+func (s *Syncer) pullImages(ctx context.Context, dir, assets, body string, r *Report) (string, error) {
+	base := s.client.BaseURL()
+	local := make(map[string]string)
+	used := make(map[string]bool)
+	existing := &assetIndex{dir: filepath.Join(s.dir, dir, assets), rel: assets}
+	var firstErr error
+
+	out := imageURL.ReplaceAllStringFunc(body, func(u string) string {
+		if firstErr != nil {
+			return u
+		}
+		if p, ok := local[u]; ok {
+			return p
+		}
+		if isAbsURL(u) && !hasPrefixFold(u, base) {
+			return u
+		}
+		data, err := s.client.Download(ctx, u)
+		if err != nil {
+			firstErr = fmt.Errorf("downloading image %s: %w", u, err)
+			return u
+		}
+		if rel, ok := existing.find(data); ok {
+			local[u] = rel
+			return rel
+		}
+		name := safeName(path.Base(u))
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%d-%s", i, safeName(path.Base(u)))
+		}
+		used[name] = true
+		rel := path.Join(assets, name)
+		if err := s.writeFile(filepath.Join(dir, filepath.FromSlash(rel)), data, r); err != nil {
+			firstErr = err
+			return u
+		}
+		r.Images++
+		local[u] = rel
+		return rel
+	})
+	return out, firstErr
+}
+
+// assetIndex finds files already in a page's assets directory, so that
+// images pushed from the local tree keep their local names when pulled.
+type assetIndex struct {
+	dir, rel string
+	loaded   bool
+	names    []string
+	data     [][]byte
+}
+
+// find returns the relative path of an existing asset with the given content.
+func (a *assetIndex) find(data []byte) (string, bool) {
+	if !a.loaded {
+		a.loaded = true
+		entries, _ := os.ReadDir(a.dir)
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			if b, err := os.ReadFile(filepath.Join(a.dir, e.Name())); err == nil {
+				a.names = append(a.names, e.Name())
+				a.data = append(a.data, b)
+			}
+		}
+	}
+	for i, b := range a.data {
+		if bytes.Equal(b, data) {
+			return path.Join(a.rel, a.names[i]), true
+		}
+	}
+	return "", false
+}
+
+func isAbsURL(u string) bool {
+	return len(u) > 7 && (hasPrefixFold(u, "http://") || hasPrefixFold(u, "https://"))
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,148 @@
+package docsync
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/bookstacktest"
+)
+
+func TestSyncer_PullBook(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Operations", Description: "Ops docs"})
+	ch := srv.AddChapter(bookstack.Chapter{BookID: book.ID, Name: "Runbooks"})
+	page := srv.AddPage(bookstack.Page{
+		ChapterID: ch.ID,
+		Name:      "Deploy",
+		Markdown:  "# Deploy\n\n![arch](" + srv.URL + "/uploads/images/gallery/arch.png)",
+		Tags:      []bookstack.Tag{{Name: "team", Value: "platform"}},
+	})
+	srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Overview", HTML: "<p>Welcome</p>"})
+	srv.AddUpload("/uploads/images/gallery/arch.png", []byte("PNG"))
+	srv.AddAttachment(bookstack.Attachment{
+		Name: "config", Extension: "yaml", UploadedTo: page.ID,
+		Content: base64.StdEncoding.EncodeToString([]byte("key: value")),
+	})
+	srv.AddAttachment(bookstack.Attachment{Name: "Dashboard", UploadedTo: page.ID, External: true, Content: "https://grafana"})
+
+	dir := t.TempDir()
+	r, err := New(srv.Client(), dir).PullBook(context.Background(), book.ID)
+	if err != nil {
+		t.Fatalf("PullBook: %v", err)
+	}
+	if r.Books != 1 || r.Chapters != 1 || r.Pages != 2 || r.Attachments != 1 || r.Images != 1 {
+		t.Errorf("report = %+v", r)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "operations", "runbooks", "deploy.md"))
+	if err != nil {
+		t.Fatalf("reading page: %v", err)
+	}
+	doc, err := ParseDocument(data)
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if doc.ID != page.ID || doc.Revision != 1 || len(doc.Tags) != 1 {
+		t.Errorf("front matter = %+v", doc.FrontMatter)
+	}
+	if !strings.Contains(doc.Body, "](deploy.assets/arch.png)") {
+		t.Errorf("image not rewritten: %q", doc.Body)
+	}
+	if len(doc.Attachments) != 2 || doc.Attachments[0].File != "deploy.assets/config.yaml" || doc.Attachments[1].Link != "https://grafana" {
+		t.Errorf("attachments = %+v", doc.Attachments)
+	}
+
+	for file, want := range map[string]string{
+		"operations/runbooks/deploy.assets/arch.png":    "PNG",
+		"operations/runbooks/deploy.assets/config.yaml": "key: value",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", file, got, err, want)
+		}
+	}
+
+	overview, err := os.ReadFile(filepath.Join(dir, "operations", "overview.md"))
+	if err != nil {
+		t.Fatalf("reading overview: %v", err)
+	}
+	if !strings.Contains(string(overview), "Welcome") {
+		t.Errorf("overview = %q", overview)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "operations", indexFile)); err != nil {
+		t.Errorf("book index missing: %v", err)
+	}
+
+	r, err = New(srv.Client(), dir).PullBook(context.Background(), book.ID)
+	if err != nil {
+		t.Fatalf("second PullBook: %v", err)
+	}
+	if len(r.Written) != 0 {
+		t.Errorf("second pull rewrote %v", r.Written)
+	}
+}
+
+func TestSyncer_PullShelf(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	a := srv.AddBook(bookstack.Book{Name: "A"})
+	srv.AddBook(bookstack.Book{Name: "B"})
+	shelf := srv.AddShelf(bookstack.Shelf{Name: "Team"}, a.ID)
+
+	dir := t.TempDir()
+	r, err := New(srv.Client(), dir).PullShelf(context.Background(), shelf.ID)
+	if err != nil {
+		t.Fatalf("PullShelf: %v", err)
+	}
+	if r.Books != 1 {
+		t.Errorf("Books = %d, want 1", r.Books)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b")); !os.IsNotExist(err) {
+		t.Error("book not on shelf was pulled")
+	}
+}
+
+func TestSyncer_PullConvertLocally(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Ops"})
+	page := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Notes", HTML: "<p>Some <strong>notes</strong>.</p>"})
+	export := "GET /api/pages/" + strconv.Itoa(page.ID) + "/export/markdown"
+	ctx := context.Background()
+
+	for _, local := range []bool{false, true} {
+		dir := t.TempDir()
+		s := New(srv.Client(), dir)
+		s.ConvertLocally = local
+		srv.ResetRequests()
+		if _, err := s.PullBook(ctx, book.ID); err != nil {
+			t.Fatalf("PullBook: %v", err)
+		}
+		if exported := slices.Contains(srv.Requests(), export); exported == local {
+			t.Errorf("ConvertLocally = %v: export requested = %v", local, exported)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "ops", "notes.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if converted := strings.Contains(string(data), "Some **notes**."); converted != local {
+			t.Errorf("ConvertLocally = %v: page = %q", local, data)
+		}
+
+		// Pushing the unchanged mirror leaves the page alone either way.
+		if _, err := s.Push(ctx); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+		if got, _ := srv.Page(page.ID); got.Markdown != "" || got.Revision != 1 {
+			t.Errorf("ConvertLocally = %v: page rewritten by push: %+v", local, got)
+		}
+	}
+}
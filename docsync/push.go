@@ -0,0 +1,667 @@
+package docsync
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+// localPage is a page file found in the sync directory.
+type localPage struct {
+	rel       string // slash-separated path relative to the sync directory
+	doc       *Document
+	original  []byte // file content as read, to detect front matter changes
+	bookID    int
+	bookSlug  string
+	chapterID int
+	page      *bookstack.Page
+	created   bool
+}
+
+// pushState tracks a single Push run.
+type pushState struct {
+	report *Report
+	pages  []*localPage
+	urls   map[string]string // local path (file or directory) to Bookstack URL
+
+	// unchanged reports pages known to match Bookstack, which are linked
+	// to but not fetched or pushed. Nil pushes every page.
+	unchanged func(doc *Document) bool
+
+	// guarded makes pages whose revision on Bookstack differs from their
+	// front matter conflicts instead of overwriting them.
+	guarded bool
+}
+
+// Push publishes the sync directory to Bookstack, the reverse of a pull.
+//
+// Top-level directories become books, their subdirectories chapters, and
+// every other .md file a page; _index.md files supply book and chapter
+// metadata. Items are matched by the id in their front matter, then by slug,
+// and are created when no match exists. Pages whose name, tags and Markdown
+// already match are left untouched so no empty revisions are created.
+//
+// Images referenced with relative paths are uploaded to the image gallery,
+// relative links to other files in the tree are rewritten to Bookstack URLs,
+// and other linked local files and front matter attachments are uploaded as
+// attachments. Newly assigned IDs are written back to each file's front
+// matter so later pushes update rather than duplicate content. Files with
+// unresolved conflict markers (see Sync) are skipped and reported.
+func (s *Syncer) Push(ctx context.Context) (*Report, error) {
+	return s.push(ctx, nil)
+}
+
+// push implements Push. If unchanged is not nil, the pages for which it
+// reports true are skipped, and pages changed on Bookstack since the
+// revision in their front matter are reported as conflicts, not pushed.
+func (s *Syncer) push(ctx context.Context, unchanged func(doc *Document) bool) (*Report, error) {
+	st := &pushState{report: &Report{}, urls: make(map[string]string), unchanged: unchanged, guarded: unchanged != nil}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return st.report, fmt.Errorf("reading sync directory: %w", err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() || skipDir(e.Name()) {
+			continue
+		}
+		if err := s.pushBookDir(ctx, e.Name(), st); err != nil {
+			return st.report, err
+		}
+	}
+
+	for _, lp := range st.pages {
+		if err := s.pushPageContent(ctx, lp, st); err != nil {
+			return st.report, fmt.Errorf("%s: %w", lp.rel, err)
+		}
+	}
+	return st.report, nil
+}
+
+// skipDir reports whether a directory is not part of the content tree.
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, assetsSuffix)
+}
+
+func (s *Syncer) pushBookDir(ctx context.Context, dirName string, st *pushState) error {
+	idx, err := s.readIndex(dirName)
+	if err != nil {
+		return err
+	}
+	book, err := s.ensureBook(ctx, dirName, idx, st)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dirName, err)
+	}
+	st.urls[dirName] = s.client.BaseURL() + "/books/" + book.Slug
+	st.urls[path.Join(dirName, indexFile)] = st.urls[dirName]
+
+	entries, err := os.ReadDir(filepath.Join(s.dir, dirName))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", dirName, err)
+	}
+	for _, e := range entries {
+		rel := path.Join(dirName, e.Name())
+		switch {
+		case e.IsDir() && !skipDir(e.Name()):
+			if err := s.pushChapterDir(ctx, rel, book, st); err != nil {
+				return err
+			}
+		case isPageFile(e):
+			if err := s.loadPage(ctx, rel, book, 0, st); err != nil {
+				return fmt.Errorf("%s: %w", rel, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Syncer) pushChapterDir(ctx context.Context, rel string, book *bookstack.Book, st *pushState) error {
+	idx, err := s.readIndex(rel)
+	if err != nil {
+		return err
+	}
+	ch, err := s.ensureChapter(ctx, rel, book, idx, st)
+	if err != nil {
+		return fmt.Errorf("%s: %w", rel, err)
+	}
+	st.urls[rel] = s.client.BaseURL() + "/books/" + book.Slug + "/chapter/" + ch.Slug
+	st.urls[path.Join(rel, indexFile)] = st.urls[rel]
+
+	entries, err := os.ReadDir(filepath.Join(s.dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", rel, err)
+	}
+	for _, e := range entries {
+		if isPageFile(e) {
+			p := path.Join(rel, e.Name())
+			if err := s.loadPage(ctx, p, book, ch.ID, st); err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+		}
+	}
+	return nil
+}
+
+func isPageFile(e os.DirEntry) bool {
+	return !e.IsDir() && strings.HasSuffix(e.Name(), ".md") && e.Name() != indexFile && !strings.HasPrefix(e.Name(), ".")
+}
+
+// indexDoc is a book or chapter _index.md file, which may not exist.
+type indexDoc struct {
+	rel      string
+	doc      *Document
+	original []byte
+}
+
+func (s *Syncer) readIndex(dirRel string) (*indexDoc, error) {
+	rel := path.Join(dirRel, indexFile)
+	idx := &indexDoc{rel: rel, doc: &Document{}}
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(rel)))
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", rel, err)
+	}
+	idx.doc, idx.original = doc, data
+	return idx, nil
+}
+
+// saveIndex writes back an index file when its front matter changed.
+func (s *Syncer) saveIndex(idx *indexDoc, r *Report) error {
+	data := idx.doc.Bytes()
+	if idx.original != nil && bytes.Equal(data, idx.original) {
+		return nil
+	}
+	return s.writeFile(filepath.FromSlash(idx.rel), data, r)
+}
+
+func (s *Syncer) ensureBook(ctx context.Context, dirName string, idx *indexDoc, st *pushState) (*bookstack.Book, error) {
+	fm := &idx.doc.FrontMatter
+	name := cmp.Or(fm.Name, humanize(dirName))
+	desc := strings.TrimSpace(idx.doc.Body)
+
+	book, err := s.findBook(ctx, fm.ID, cmp.Or(fm.Slug, dirName))
+	if err != nil {
+		return nil, err
+	}
+	if book == nil {
+		book, err = s.client.Books.Create(ctx, &bookstack.BookCreateRequest{Name: name, Description: desc, Tags: fm.Tags})
+		if err != nil {
+			return nil, fmt.Errorf("creating book: %w", err)
+		}
+		st.report.Books++
+	} else if book.Name != name || strings.TrimSpace(book.Description) != desc || !sameTags(book.Tags, fm.Tags) {
+		book, err = s.client.Books.Update(ctx, book.ID, &bookstack.BookUpdateRequest{Name: name, Description: &desc, Tags: tagsOrEmpty(fm.Tags)})
+		if err != nil {
+			return nil, fmt.Errorf("updating book: %w", err)
+		}
+		st.report.Books++
+	}
+
+	fm.ID, fm.Type, fm.Name, fm.Slug = book.ID, TypeBook, name, book.Slug
+	return book, s.saveIndex(idx, st.report)
+}
+
+// findBook looks up a book by ID, falling back to its slug. It returns nil
+// if neither matches.
+func (s *Syncer) findBook(ctx context.Context, id int, slug string) (*bookstack.Book, error) {
+	if id > 0 {
+		book, err := s.client.Books.Get(ctx, id)
+		if err == nil {
+			return book, nil
+		}
+		if !errors.Is(err, bookstack.ErrNotFound) {
+			return nil, err
+		}
+	}
+	book, err := s.client.Books.GetBySlug(ctx, slug)
+	if errors.Is(err, bookstack.ErrNotFound) {
+		return nil, nil
+	}
+	return book, err
+}
+
+func (s *Syncer) ensureChapter(ctx context.Context, rel string, book *bookstack.Book, idx *indexDoc, st *pushState) (*bookstack.Chapter, error) {
+	fm := &idx.doc.FrontMatter
+	dirName := path.Base(rel)
+	name := cmp.Or(fm.Name, humanize(dirName))
+	desc := strings.TrimSpace(idx.doc.Body)
+
+	var ch *bookstack.Chapter
+	if fm.ID > 0 {
+		c, err := s.client.Chapters.Get(ctx, fm.ID)
+		if err != nil && !errors.Is(err, bookstack.ErrNotFound) {
+			return nil, err
+		}
+		if c != nil && c.BookID == book.ID {
+			ch = c
+		}
+	}
+	if ch == nil {
+		c, err := s.client.Chapters.GetBySlug(ctx, book.Slug, cmp.Or(fm.Slug, dirName))
+		if err != nil && !errors.Is(err, bookstack.ErrNotFound) {
+			return nil, err
+		}
+		ch = c
+	}
+
+	var err error
+	if ch == nil {
+		ch, err = s.client.Chapters.Create(ctx, &bookstack.ChapterCreateRequest{
+			BookID: book.ID, Name: name, Description: desc, Priority: fm.Priority, Tags: fm.Tags,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating chapter: %w", err)
+		}
+		st.report.Chapters++
+	} else if ch.Name != name || strings.TrimSpace(ch.Description) != desc || !sameTags(ch.Tags, fm.Tags) ||
+		(fm.Priority != 0 && fm.Priority != ch.Priority) {
+		ch, err = s.client.Chapters.Update(ctx, ch.ID, &bookstack.ChapterUpdateRequest{
+			Name: name, Description: &desc, Priority: fm.Priority, Tags: tagsOrEmpty(fm.Tags),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("updating chapter: %w", err)
+		}
+		st.report.Chapters++
+	}
+
+	fm.ID, fm.Type, fm.Name, fm.Slug, fm.BookID = ch.ID, TypeChapter, name, ch.Slug, book.ID
+	return ch, s.saveIndex(idx, st.report)
+}
+
+// loadPage reads a page file and makes sure the page exists in Bookstack,
+// creating it if needed. Content is pushed later by pushPageContent, once
+// every page has an ID that links can point to.
+func (s *Syncer) loadPage(ctx context.Context, rel string, book *bookstack.Book, chapterID int, st *pushState) error {
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return err
+	}
+	if hasConflictMarkers(doc.Body) {
+		st.report.Conflicts = append(st.report.Conflicts, rel)
+		if doc.ID > 0 {
+			st.urls[rel] = s.client.PageLink(doc.ID)
+		}
+		return nil
+	}
+	if doc.ID > 0 && st.unchanged != nil && st.unchanged(doc) {
+		st.urls[rel] = s.client.PageLink(doc.ID)
+		return nil
+	}
+	lp := &localPage{rel: rel, doc: doc, original: data, bookID: book.ID, bookSlug: book.Slug, chapterID: chapterID}
+	fm := &doc.FrontMatter
+	slug := cmp.Or(fm.Slug, strings.TrimSuffix(path.Base(rel), ".md"))
+	name := cmp.Or(fm.Name, humanize(slug))
+
+	if fm.ID > 0 {
+		p, err := s.client.Pages.Get(ctx, fm.ID)
+		if err != nil && !errors.Is(err, bookstack.ErrNotFound) {
+			return err
+		}
+		lp.page = p
+	}
+	if lp.page == nil {
+		p, err := s.client.Pages.GetBySlug(ctx, book.Slug, slug)
+		if err != nil && !errors.Is(err, bookstack.ErrNotFound) {
+			return err
+		}
+		lp.page = p
+	}
+	if lp.page == nil {
+		p, err := s.client.Pages.Create(ctx, &bookstack.PageCreateRequest{
+			BookID:    book.ID,
+			ChapterID: chapterID,
+			Name:      name,
+			Markdown:  cmp.Or(strings.TrimSpace(doc.Body), name),
+			Priority:  fm.Priority,
+			Tags:      fm.Tags,
+		})
+		if err != nil {
+			return fmt.Errorf("creating page: %w", err)
+		}
+		lp.page, lp.created = p, true
+	}
+
+	st.urls[rel] = s.client.PageLink(lp.page.ID)
+	st.pages = append(st.pages, lp)
+	return nil
+}
+
+// pushPageContent uploads a page's assets, rewrites its links and updates
+// the page if anything differs from Bookstack.
+func (s *Syncer) pushPageContent(ctx context.Context, lp *localPage, st *pushState) error {
+	fm := &lp.doc.FrontMatter
+	slug := cmp.Or(fm.Slug, strings.TrimSuffix(path.Base(lp.rel), ".md"))
+	name := cmp.Or(fm.Name, humanize(slug))
+	pageID := lp.page.ID
+
+	if err := s.pushAttachments(ctx, lp, st); err != nil {
+		return err
+	}
+	body, err := s.rewriteLinks(ctx, lp, st)
+	if err != nil {
+		return err
+	}
+
+	// Pages listed by ID keep their content; Get returns the stored markdown.
+	remote, err := s.client.Pages.Get(ctx, pageID)
+	if err != nil {
+		return err
+	}
+	if st.guarded && !lp.created && fm.Revision > 0 && remote.Revision != fm.Revision {
+		st.report.Conflicts = append(st.report.Conflicts, lp.rel)
+		return nil
+	}
+	// A page matched by ID may have been moved to another directory locally.
+	moved := remote.BookID != lp.bookID || remote.ChapterID != lp.chapterID
+	if moved {
+		remote, err = s.client.Pages.Move(ctx, pageID, bookstack.MoveTarget{BookID: lp.bookID, ChapterID: lp.chapterID})
+		if err != nil {
+			return fmt.Errorf("moving page: %w", err)
+		}
+	}
+	// WYSIWYG pages have no Markdown; compare with the Markdown a pull
+	// writes so they are only rewritten as Markdown pages when the content
+	// changed.
+	remoteBody, err := s.pageMarkdown(ctx, remote)
+	if err != nil {
+		return err
+	}
+	changed := remote.Name != name || !sameTags(remote.Tags, fm.Tags) ||
+		strings.TrimSpace(remoteBody) != strings.TrimSpace(body) ||
+		(fm.Priority != 0 && fm.Priority != remote.Priority)
+	if changed {
+		remote, err = s.client.Pages.UpdateIfUnchanged(ctx, remote, &bookstack.PageUpdateRequest{
+			Name:     name,
+			Markdown: body,
+			Priority: fm.Priority,
+			Tags:     tagsOrEmpty(fm.Tags),
+		})
+		var conflict *bookstack.ConflictError
+		if errors.As(err, &conflict) {
+			st.report.Conflicts = append(st.report.Conflicts, lp.rel)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("updating page: %w", err)
+		}
+	}
+	if changed || moved || lp.created {
+		st.report.Pages++
+	}
+
+	fm.ID, fm.Type, fm.Name, fm.Slug = remote.ID, TypePage, name, remote.Slug
+	fm.BookID, fm.ChapterID = remote.BookID, remote.ChapterID
+	fm.Priority, fm.Revision, fm.UpdatedAt = remote.Priority, remote.Revision, remote.UpdatedAt
+	lp.page = remote
+
+	data := lp.doc.Bytes()
+	if bytes.Equal(data, lp.original) {
+		return nil
+	}
+	return s.writeFile(filepath.FromSlash(lp.rel), data, st.report)
+}
+
+// pushAttachments uploads front matter attachments that are new or whose
+// local file differs from the stored one, and creates or updates link attachments.
+func (s *Syncer) pushAttachments(ctx context.Context, lp *localPage, st *pushState) error {
+	fm := &lp.doc.FrontMatter
+	dir := path.Dir(lp.rel)
+	for i := range fm.Attachments {
+		a := &fm.Attachments[i]
+		var remote *bookstack.Attachment
+		if a.ID > 0 {
+			r, err := s.client.Attachments.Get(ctx, a.ID)
+			if err != nil && !errors.Is(err, bookstack.ErrNotFound) {
+				return err
+			}
+			if r != nil && r.UploadedTo == lp.page.ID {
+				remote = r
+			}
+		}
+
+		if a.Link != "" {
+			switch {
+			case remote == nil:
+				r, err := s.client.Attachments.Create(ctx, &bookstack.AttachmentCreateRequest{Name: a.Name, UploadedTo: lp.page.ID, Link: a.Link})
+				if err != nil {
+					return fmt.Errorf("creating attachment %q: %w", a.Name, err)
+				}
+				a.ID = r.ID
+				st.report.Attachments++
+			case remote.Name != a.Name || remote.Content != a.Link:
+				if _, err := s.client.Attachments.Update(ctx, remote.ID, &bookstack.AttachmentUpdateRequest{Name: a.Name, Link: a.Link}); err != nil {
+					return fmt.Errorf("updating attachment %q: %w", a.Name, err)
+				}
+				st.report.Attachments++
+			}
+			continue
+		}
+		if a.File == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(path.Join(dir, a.File))))
+		if err != nil {
+			return fmt.Errorf("reading attachment: %w", err)
+		}
+		if remote != nil && !remote.External && remote.Content == base64.StdEncoding.EncodeToString(data) {
+			continue
+		}
+		if remote != nil {
+			if err := s.client.Attachments.Delete(ctx, remote.ID); err != nil {
+				return fmt.Errorf("replacing attachment %q: %w", a.Name, err)
+			}
+		}
+		r, err := s.client.Attachments.Upload(ctx, &bookstack.AttachmentUploadRequest{
+			Name:       cmp.Or(a.Name, path.Base(a.File)),
+			UploadedTo: lp.page.ID,
+			Filename:   path.Base(a.File),
+			Data:       data,
+		})
+		if err != nil {
+			return fmt.Errorf("uploading attachment %q: %w", a.File, err)
+		}
+		a.ID, a.Name = r.ID, r.Name
+		st.report.Attachments++
+	}
+	return nil
+}
+
+// markdownLink matches inline Markdown links and images.
+var markdownLink = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)\s]+)((?:\s+"[^"]*")?)\)`)
+
+// htmlImage matches the src attribute of HTML img tags embedded in Markdown.
+var htmlImage = regexp.MustCompile(`(<img\b[^>]*?\bsrc=")([^"]+)(")`)
+
+// rewriteLinks returns the page body with relative references replaced by
+// Bookstack URLs, uploading images and linked files as needed.
+func (s *Syncer) rewriteLinks(ctx context.Context, lp *localPage, st *pushState) (string, error) {
+	var firstErr error
+	images := &imageUploader{syncer: s, page: lp, report: st.report}
+
+	body := markdownLink.ReplaceAllStringFunc(lp.doc.Body, func(m string) string {
+		if firstErr != nil {
+			return m
+		}
+		sub := markdownLink.FindStringSubmatch(m)
+		target, err := s.resolveTarget(ctx, lp, sub[3], sub[1] == "!", images, st)
+		if err != nil {
+			firstErr = err
+			return m
+		}
+		return sub[1] + "[" + sub[2] + "](" + target + sub[4] + ")"
+	})
+	body = htmlImage.ReplaceAllStringFunc(body, func(m string) string {
+		if firstErr != nil {
+			return m
+		}
+		sub := htmlImage.FindStringSubmatch(m)
+		target, err := s.resolveTarget(ctx, lp, sub[2], true, images, st)
+		if err != nil {
+			firstErr = err
+			return m
+		}
+		return sub[1] + target + sub[3]
+	})
+	return body, firstErr
+}
+
+// resolveTarget maps a link target in a page to its Bookstack equivalent.
+// Absolute URLs, root-relative paths and fragments are returned unchanged.
+func (s *Syncer) resolveTarget(ctx context.Context, lp *localPage, target string, image bool, images *imageUploader, st *pushState) (string, error) {
+	if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") || strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+		return target, nil
+	}
+	file, fragment, _ := strings.Cut(target, "#")
+	rel := path.Clean(path.Join(path.Dir(lp.rel), file))
+	if strings.HasPrefix(rel, "../") || rel == ".." {
+		return target, nil
+	}
+
+	if image {
+		u, err := images.upload(ctx, rel)
+		if err != nil {
+			return "", err
+		}
+		return u, nil
+	}
+	if u, ok := st.urls[strings.TrimSuffix(rel, "/")]; ok {
+		if fragment != "" {
+			u += "#" + fragment
+		}
+		return u, nil
+	}
+
+	// Any other existing local file is published as an attachment.
+	info, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(rel)))
+	if err != nil || info.IsDir() {
+		return target, nil
+	}
+	fm := &lp.doc.FrontMatter
+	relToPage := strings.TrimPrefix(rel, path.Dir(lp.rel)+"/")
+	i := slices.IndexFunc(fm.Attachments, func(a AttachmentRef) bool { return a.File == relToPage })
+	if i < 0 {
+		data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return "", err
+		}
+		a, err := s.client.Attachments.Upload(ctx, &bookstack.AttachmentUploadRequest{
+			Name:       path.Base(rel),
+			UploadedTo: lp.page.ID,
+			Filename:   path.Base(rel),
+			Data:       data,
+		})
+		if err != nil {
+			return "", fmt.Errorf("uploading attachment %q: %w", rel, err)
+		}
+		st.report.Attachments++
+		fm.Attachments = append(fm.Attachments, AttachmentRef{ID: a.ID, Name: a.Name, File: relToPage})
+		i = len(fm.Attachments) - 1
+	}
+	return s.client.BaseURL() + "/attachments/" + strconv.Itoa(fm.Attachments[i].ID), nil
+}
+
+// imageUploader uploads a page's local images, reusing gallery images of
+// the page that already have the same name and content.
+type imageUploader struct {
+	syncer   *Syncer
+	page     *localPage
+	report   *Report
+	existing []bookstack.Image
+	loaded   bool
+	done     map[string]string
+}
+
+func (u *imageUploader) upload(ctx context.Context, rel string) (string, error) {
+	if url, ok := u.done[rel]; ok {
+		return url, nil
+	}
+	c := u.syncer.client
+	data, err := os.ReadFile(filepath.Join(u.syncer.dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", fmt.Errorf("reading image: %w", err)
+	}
+	name := path.Base(rel)
+
+	if !u.loaded {
+		opts := &bookstack.ListOptions{Filter: map[string]string{"uploaded_to": strconv.Itoa(u.page.page.ID)}}
+		for img, err := range c.Images.ListAllWith(ctx, opts) {
+			if err != nil {
+				return "", err
+			}
+			u.existing = append(u.existing, img)
+		}
+		u.loaded = true
+		u.done = make(map[string]string)
+	}
+
+	url := ""
+	for _, img := range u.existing {
+		if img.Name != name && path.Base(img.URL) != name {
+			continue
+		}
+		remote, err := c.Download(ctx, img.URL)
+		if err == nil && bytes.Equal(remote, data) {
+			url = img.URL
+			break
+		}
+	}
+	if url == "" {
+		img, err := c.Images.Create(ctx, &bookstack.ImageUploadRequest{UploadedTo: u.page.page.ID, Filename: name, Data: data})
+		if err != nil {
+			return "", fmt.Errorf("uploading image %q: %w", rel, err)
+		}
+		u.existing = append(u.existing, *img)
+		u.report.Images++
+		url = img.URL
+	}
+	u.done[rel] = url
+	return url, nil
+}
+
+// sameTags reports whether two tag lists have the same names and values in order.
+func sameTags(a, b []bookstack.Tag) bool {
+	return slices.EqualFunc(a, b, func(x, y bookstack.Tag) bool {
+		return x.Name == y.Name && x.Value == y.Value
+	})
+}
+
+// tagsOrEmpty returns tags, or an empty list if it is nil, so that an
+// update removes tags deleted from the front matter.
+func tagsOrEmpty(tags []bookstack.Tag) []bookstack.Tag {
+	if tags == nil {
+		return []bookstack.Tag{}
+	}
+	return tags
+}
+
+// humanize turns a slug or directory name into a display name.
+func humanize(s string) string {
+	s = strings.Join(strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' }), " ")
+	if s == "" {
+		return "Untitled"
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,233 @@
+package docsync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/bookstacktest"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSyncer_Push(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"handbook/_index.md":                          "---\nname: Team Handbook\n---\nHow we work.\n",
+		"handbook/intro.md":                           "# Intro\n\nSee [deploys](runbooks/deploy.md#steps) and [runbooks](runbooks/).\n",
+		"handbook/runbooks/deploy.md":                 "---\nname: Deploy\ntags:\n  - name: team\n    value: platform\n---\n![arch](deploy.assets/arch.png)\n\nDownload [config](deploy.assets/config.yaml).\n",
+		"handbook/runbooks/deploy.assets/arch.png":    "PNG",
+		"handbook/runbooks/deploy.assets/config.yaml": "key: value",
+	})
+
+	ctx := context.Background()
+	r, err := New(srv.Client(), dir).Push(ctx)
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if r.Books != 1 || r.Chapters != 1 || r.Pages != 2 || r.Images != 1 || r.Attachments != 1 {
+		t.Errorf("report = %+v", r)
+	}
+
+	book, err := srv.Client().Books.GetBySlug(ctx, "team-handbook")
+	if err != nil {
+		t.Fatalf("book not created: %v", err)
+	}
+	if book.Name != "Team Handbook" || book.Description != "How we work." {
+		t.Errorf("book = %+v", book)
+	}
+	deploy, err := srv.Client().Pages.GetBySlug(ctx, "team-handbook", "deploy")
+	if err != nil {
+		t.Fatalf("deploy page not created: %v", err)
+	}
+	if deploy.ChapterID == 0 || len(deploy.Tags) != 1 {
+		t.Errorf("deploy = %+v", deploy)
+	}
+	if !strings.Contains(deploy.Markdown, "]("+srv.URL+"/uploads/images/gallery/") {
+		t.Errorf("image not rewritten: %q", deploy.Markdown)
+	}
+	if !strings.Contains(deploy.Markdown, "]("+srv.URL+"/attachments/") {
+		t.Errorf("attachment link not rewritten: %q", deploy.Markdown)
+	}
+	intro, err := srv.Client().Pages.GetBySlug(ctx, "team-handbook", "intro")
+	if err != nil {
+		t.Fatalf("intro page not created: %v", err)
+	}
+	if want := srv.URL + "/link/" + strconv.Itoa(deploy.ID) + "#steps"; !strings.Contains(intro.Markdown, want) {
+		t.Errorf("intro = %q, want link %s", intro.Markdown, want)
+	}
+	if want := srv.URL + "/books/team-handbook/chapter/runbooks"; !strings.Contains(intro.Markdown, want) {
+		t.Errorf("intro = %q, want link %s", intro.Markdown, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "handbook", "runbooks", "deploy.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ParseDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != deploy.ID || len(doc.Attachments) != 1 || doc.Attachments[0].File != "deploy.assets/config.yaml" {
+		t.Errorf("front matter not written back: %+v", doc.FrontMatter)
+	}
+	if !strings.Contains(doc.Body, "](deploy.assets/arch.png)") {
+		t.Errorf("local body was rewritten: %q", doc.Body)
+	}
+
+	r, err = New(srv.Client(), dir).Push(ctx)
+	if err != nil {
+		t.Fatalf("second Push: %v", err)
+	}
+	if r.Books != 0 || r.Chapters != 0 || r.Pages != 0 || r.Images != 0 || r.Attachments != 0 || len(r.Written) != 0 {
+		t.Errorf("second push changed something: %+v", r)
+	}
+	if n := srv.Count("pages"); n != 2 {
+		t.Errorf("pages = %d, want 2", n)
+	}
+}
+
+func TestSyncer_PushUpdatesExisting(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Ops"})
+	page := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Oncall", Markdown: "old"})
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"ops/_index.md": "---\nname: Ops\n---\n",
+		"ops/oncall.md": "---\nname: Oncall\n---\nnew\n",
+	})
+
+	r, err := New(srv.Client(), dir).Push(context.Background())
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if r.Pages != 1 || r.Books != 0 {
+		t.Errorf("report = %+v", r)
+	}
+	got, _ := srv.Page(page.ID)
+	if strings.TrimSpace(got.Markdown) != "new" {
+		t.Errorf("Markdown = %q", got.Markdown)
+	}
+	if srv.Count("pages") != 1 {
+		t.Error("page was duplicated instead of matched by slug")
+	}
+}
+
+func TestSyncer_PullThenPush(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Ops", Description: "Ops docs"})
+	ch := srv.AddChapter(bookstack.Chapter{BookID: book.ID, Name: "Runbooks"})
+	srv.AddPage(bookstack.Page{ChapterID: ch.ID, Name: "Deploy", Markdown: "# Deploy"})
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	if _, err := New(srv.Client(), dir).PullBook(ctx, book.ID); err != nil {
+		t.Fatalf("PullBook: %v", err)
+	}
+	r, err := New(srv.Client(), dir).Push(ctx)
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if r.Books != 0 || r.Chapters != 0 || r.Pages != 0 {
+		t.Errorf("push after pull changed content: %+v", r)
+	}
+}
+
+func TestSyncer_PushClearsTagsAndDescription(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Ops", Description: "Ops docs", Tags: []bookstack.Tag{{Name: "team", Value: "sre"}}})
+	page := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", Markdown: "# Deploy", Tags: []bookstack.Tag{{Name: "kind", Value: "runbook"}}})
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	if _, err := New(srv.Client(), dir).PullBook(ctx, book.ID); err != nil {
+		t.Fatalf("PullBook: %v", err)
+	}
+	writeTree(t, dir, map[string]string{
+		"ops/_index.md": "---\nid: " + strconv.Itoa(book.ID) + "\nname: Ops\n---\n",
+		"ops/deploy.md": "---\nid: " + strconv.Itoa(page.ID) + "\nname: Deploy\n---\n# Deploy\n",
+	})
+
+	r, err := New(srv.Client(), dir).Push(ctx)
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if r.Books != 1 || r.Pages != 1 {
+		t.Errorf("report = %+v", r)
+	}
+	gotBook, _ := srv.Client().Books.Get(ctx, book.ID)
+	if gotBook.Description != "" || len(gotBook.Tags) != 0 {
+		t.Errorf("book after push = %+v", gotBook)
+	}
+	gotPage, _ := srv.Page(page.ID)
+	if len(gotPage.Tags) != 0 {
+		t.Errorf("page tags after push = %+v", gotPage.Tags)
+	}
+
+	r, err = New(srv.Client(), dir).Push(ctx)
+	if err != nil {
+		t.Fatalf("second Push: %v", err)
+	}
+	if r.Books != 0 || r.Pages != 0 {
+		t.Errorf("second push changed something: %+v", r)
+	}
+}
+
+func TestSyncer_PushMovesPages(t *testing.T) {
+	srv := bookstacktest.NewServer()
+	defer srv.Close()
+	book := srv.AddBook(bookstack.Book{Name: "Ops"})
+	ch := srv.AddChapter(bookstack.Chapter{BookID: book.ID, Name: "Runbooks"})
+	page := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", Markdown: "# Deploy"})
+	wysiwyg := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Notes", HTML: "<p>Some <strong>notes</strong>.</p>"})
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	if _, err := New(srv.Client(), dir).PullBook(ctx, book.ID); err != nil {
+		t.Fatalf("PullBook: %v", err)
+	}
+	if err := os.Rename(filepath.Join(dir, "ops", "deploy.md"), filepath.Join(dir, "ops", "runbooks", "deploy.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(srv.Client(), dir).Push(ctx)
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if r.Pages != 1 {
+		t.Errorf("report = %+v", r)
+	}
+	got, _ := srv.Page(page.ID)
+	if got.ChapterID != ch.ID {
+		t.Errorf("page chapter = %d, want %d", got.ChapterID, ch.ID)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "ops", "runbooks", "deploy.md"))
+	if err != nil || !strings.Contains(string(data), "chapter_id: "+strconv.Itoa(ch.ID)) {
+		t.Errorf("front matter not updated: %s, %v", data, err)
+	}
+	if notes, _ := srv.Page(wysiwyg.ID); notes.Markdown != "" || notes.Revision != 1 {
+		t.Errorf("unchanged WYSIWYG page was rewritten: %+v", notes)
+	}
+}
@@ -0,0 +1,238 @@
+package docsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+// baseDir holds the page bodies as of the last Sync, named <page-id>.md.
+// They are the common ancestor for three-way merges.
+const baseDir = ".docsync-base"
+
+// SyncReport summarizes a two-way sync.
+type SyncReport struct {
+	Pulled *Report // Changes from Bookstack applied to the directory
+	Pushed *Report // Local changes published to Bookstack
+
+	// Merged lists pages edited on both sides whose changes did not
+	// overlap and were combined.
+	Merged []string
+
+	// Conflicts lists pages holding conflict markers, which are not pushed
+	// until the markers are resolved, and edited pages that changed on
+	// Bookstack after they were pulled, which the next Sync merges.
+	Conflicts []string
+
+	// Kept lists pages deleted on the instance that had local edits. They
+	// are left in place and re-created by the push.
+	Kept []string
+}
+
+// Sync reconciles the directory with Bookstack in both directions.
+//
+// For every page Sync keeps the body as of the previous sync. Remote
+// changes are pulled as with PullChanges; when the local file was edited
+// too, the two versions are merged line by line against that base.
+// Overlapping edits are written with conflict markers and reported instead
+// of being pushed. Local changes are then published as with Push, except
+// that pages whose body matches the base are skipped without fetching
+// them, and pages whose revision on Bookstack no longer matches their
+// front matter are reported as conflicts rather than overwritten; changes
+// to a page's front matter alone are published by Push.
+//
+// Pages pulled before the first Sync have no base; if such a page changed
+// on both sides, the whole body is treated as a conflict.
+func (s *Syncer) Sync(ctx context.Context) (*SyncReport, error) {
+	sr := &SyncReport{Pulled: &Report{}}
+	st, err := s.LoadState()
+	if err != nil {
+		return sr, err
+	}
+	c := &changeRun{Syncer: s, st: st, r: sr.Pulled, watermark: st.Watermark, sync: sr}
+	if err := c.run(ctx); err != nil {
+		return sr, err
+	}
+
+	sr.Pushed, err = s.push(ctx, func(doc *Document) bool {
+		base, ok := c.readBase(doc.ID)
+		return ok && canonicalBody(doc.Body) == base
+	})
+	if err != nil {
+		return sr, err
+	}
+	sr.Conflicts = sr.Pushed.Conflicts
+	if err := c.recordPushed(); err != nil {
+		return sr, err
+	}
+	return sr, s.saveState(st)
+}
+
+// syncPage pulls a changed page, merging it with local edits.
+func (c *changeRun) syncPage(ctx context.Context, dir string, prev *Entry, pageID int) (*bookstack.Page, string, error) {
+	page, doc, file, err := c.renderPage(ctx, dir, pageID, c.r)
+	if err != nil {
+		return nil, "", err
+	}
+	rel := filepath.ToSlash(file)
+	remote := canonicalBody(c.localizeLinks(doc.Body, rel, doc.Attachments))
+	doc.Body = remote
+
+	localRel := rel
+	if prev != nil {
+		localRel = prev.Path
+	}
+	if local, ok, err := c.readPageFile(localRel); err != nil {
+		return nil, "", err
+	} else if ok && local != remote {
+		base, hasBase := c.readBase(pageID)
+		if !hasBase || local != base {
+			merged, conflict := merge3(base, local, remote)
+			doc.Body = merged
+			if !conflict {
+				c.sync.Merged = append(c.sync.Merged, rel)
+			}
+		}
+	}
+
+	if err := c.writeFile(file, doc.Bytes(), c.r); err != nil {
+		return nil, "", err
+	}
+	c.r.Pages++
+	return page, file, c.writeBase(pageID, remote)
+}
+
+// locallyModified reports whether a mirrored page was edited since the last
+// sync. Pages without a recorded base count as modified.
+func (c *changeRun) locallyModified(id int, e *Entry) bool {
+	local, ok, err := c.readPageFile(e.Path)
+	if err != nil || !ok {
+		return err != nil
+	}
+	base, hasBase := c.readBase(id)
+	return !hasBase || local != base
+}
+
+// recordPushed updates the state and bases from the front matter the push
+// wrote back, so pushed revisions are not pulled again as remote changes.
+func (c *changeRun) recordPushed() error {
+	return filepath.WalkDir(c.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != c.dir && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isPageFile(d) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		doc, err := ParseDocument(data)
+		if err != nil || doc.Type != TypePage || doc.ID == 0 || hasConflictMarkers(doc.Body) {
+			return nil
+		}
+		rel, err := filepath.Rel(c.dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if e := c.st.Pages[doc.ID]; e != nil && e.Revision == doc.Revision && e.Path == rel {
+			return nil
+		}
+		c.st.Pages[doc.ID] = &Entry{Path: rel, Revision: doc.Revision, UpdatedAt: doc.UpdatedAt}
+		return c.writeBase(doc.ID, canonicalBody(doc.Body))
+	})
+}
+
+// readPageFile returns the canonical body of a local page file.
+func (c *changeRun) readPageFile(rel string) (string, bool, error) {
+	data, err := os.ReadFile(filepath.Join(c.dir, filepath.FromSlash(rel)))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %w", rel, err)
+	}
+	return canonicalBody(doc.Body), true, nil
+}
+
+func (c *changeRun) readBase(id int) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(c.dir, baseDir, strconv.Itoa(id)+".md"))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func (c *changeRun) writeBase(id int, body string) error {
+	dir := filepath.Join(c.dir, baseDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, strconv.Itoa(id)+".md"), []byte(body), 0o644); err != nil {
+		return fmt.Errorf("writing base of page %d: %w", id, err)
+	}
+	return nil
+}
+
+func (c *changeRun) removeBase(id int) {
+	os.Remove(filepath.Join(c.dir, baseDir, strconv.Itoa(id)+".md"))
+}
+
+// localizeLinks turns links to mirrored pages and to the page's own
+// attachments back into relative paths, undoing the rewrite done by Push.
+func (c *changeRun) localizeLinks(body, rel string, attachments []AttachmentRef) string {
+	if c.links == nil {
+		c.links = regexp.MustCompile(regexp.QuoteMeta(c.client.BaseURL()) + `/(link|attachments)/(\d+)(#[^\s)"]*)?`)
+	}
+	return c.links.ReplaceAllStringFunc(body, func(m string) string {
+		sub := c.links.FindStringSubmatch(m)
+		id, _ := strconv.Atoi(sub[2])
+		if sub[1] == "attachments" {
+			for _, a := range attachments {
+				if a.ID == id && a.File != "" {
+					return a.File
+				}
+			}
+			return m
+		}
+		e := c.st.Pages[id]
+		if e == nil {
+			return m
+		}
+		target, err := filepath.Rel(filepath.FromSlash(path.Dir(rel)), filepath.FromSlash(e.Path))
+		if err != nil {
+			return m
+		}
+		return filepath.ToSlash(target) + sub[3]
+	})
+}
+
+// canonicalBody normalizes a body the way Document.Bytes and ParseDocument
+// do, so that bodies read back from disk compare equal.
+func canonicalBody(s string) string {
+	s = strings.TrimLeft(s, "\n")
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return s
+}
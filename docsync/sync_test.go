@@ -0,0 +1,222 @@
+package docsync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/bookstacktest"
+)
+
+// syncFixture mirrors a book with one page through a first Sync.
+func syncFixture(t *testing.T, markdown string) (*bookstacktest.Server, *Syncer, *bookstack.Page, string, func()) {
+	t.Helper()
+	srv := bookstacktest.NewServer()
+	t.Cleanup(srv.Close)
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	srv.SetClock(func() time.Time { return now })
+	book := srv.AddBook(bookstack.Book{Name: "Ops"})
+	page := srv.AddPage(bookstack.Page{BookID: book.ID, Name: "Deploy", Markdown: markdown})
+
+	s := New(srv.Client(), t.TempDir())
+	if _, err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("first Sync: %v", err)
+	}
+	file := filepath.Join(s.dir, "ops", "deploy.md")
+	advance := func() { now = now.Add(time.Hour) }
+	return srv, s, page, file, advance
+}
+
+// editLocal replaces the body of a page file.
+func editLocal(t *testing.T, file, body string) {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ParseDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Body = body
+	if err := os.WriteFile(file, doc.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func localBody(t *testing.T, file string) string {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ParseDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc.Body
+}
+
+func TestSyncer_SyncMergesNonOverlapping(t *testing.T) {
+	srv, s, page, file, advance := syncFixture(t, "# Deploy\n\nstep one\n\nstep two\n")
+	ctx := context.Background()
+
+	advance()
+	editLocal(t, file, "# Deploy now\n\nstep one\n\nstep two\n")
+	if _, err := srv.Client().Pages.Update(ctx, page.ID, &bookstack.PageUpdateRequest{Markdown: "# Deploy\n\nstep one\n\nstep 2\n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sr, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	want := "# Deploy now\n\nstep one\n\nstep 2\n"
+	if !slices.Equal(sr.Merged, []string{"ops/deploy.md"}) || len(sr.Conflicts) != 0 {
+		t.Errorf("report = %+v", sr)
+	}
+	if got := localBody(t, file); got != want {
+		t.Errorf("local = %q, want %q", got, want)
+	}
+	remote, _ := srv.Page(page.ID)
+	if remote.Markdown != want {
+		t.Errorf("remote = %q, want %q", remote.Markdown, want)
+	}
+
+	sr, err = s.Sync(ctx)
+	if err != nil {
+		t.Fatalf("second Sync: %v", err)
+	}
+	if sr.Pulled.Pages != 0 || sr.Pushed.Pages != 0 {
+		t.Errorf("settled sync did work: pulled %+v, pushed %+v", sr.Pulled, sr.Pushed)
+	}
+}
+
+func TestSyncer_SyncConflict(t *testing.T) {
+	srv, s, page, file, advance := syncFixture(t, "a\nb\nc\n")
+	ctx := context.Background()
+
+	advance()
+	editLocal(t, file, "a\nlocal\nc\n")
+	if _, err := srv.Client().Pages.Update(ctx, page.ID, &bookstack.PageUpdateRequest{Markdown: "a\nremote\nc\n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sr, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !slices.Equal(sr.Conflicts, []string{"ops/deploy.md"}) || len(sr.Merged) != 0 {
+		t.Errorf("report = %+v", sr)
+	}
+	remote, _ := srv.Page(page.ID)
+	if remote.Markdown != "a\nremote\nc\n" {
+		t.Errorf("remote clobbered: %q", remote.Markdown)
+	}
+	if body := localBody(t, file); !strings.Contains(body, "local") || !strings.Contains(body, "remote") {
+		t.Errorf("local = %q", body)
+	}
+
+	// A second sync leaves the unresolved page alone.
+	if sr, err = s.Sync(ctx); err != nil || len(sr.Conflicts) != 1 {
+		t.Fatalf("second Sync = %+v, %v", sr, err)
+	}
+
+	advance()
+	editLocal(t, file, "a\nresolved\nc\n")
+	sr, err = s.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync after resolving: %v", err)
+	}
+	if len(sr.Conflicts) != 0 || sr.Pushed.Pages != 1 {
+		t.Errorf("report = %+v, pushed %+v", sr, sr.Pushed)
+	}
+	remote, _ = srv.Page(page.ID)
+	if remote.Markdown != "a\nresolved\nc\n" {
+		t.Errorf("remote = %q", remote.Markdown)
+	}
+}
+
+func TestSyncer_SyncKeepsLocallyEditedDeletedPage(t *testing.T) {
+	srv, s, page, file, advance := syncFixture(t, "a\n")
+	ctx := context.Background()
+
+	advance()
+	editLocal(t, file, "a\nmore\n")
+	if err := srv.Client().Pages.Delete(ctx, page.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	sr, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !slices.Equal(sr.Kept, []string{"ops/deploy.md"}) {
+		t.Errorf("Kept = %v", sr.Kept)
+	}
+	if got := localBody(t, file); got != "a\nmore\n" {
+		t.Errorf("local = %q", got)
+	}
+	if srv.Count("pages") != 1 {
+		t.Error("kept page was not re-created")
+	}
+}
+
+func TestSyncer_SyncPushesOnlyEditedPages(t *testing.T) {
+	srv, s, page, file, _ := syncFixture(t, "# Deploy\n")
+	ctx := context.Background()
+	pageGet := "GET /api/pages/" + strconv.Itoa(page.ID)
+
+	srv.ResetRequests()
+	if _, err := s.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if slices.Contains(srv.Requests(), pageGet) {
+		t.Errorf("unchanged page was fetched: %v", srv.Requests())
+	}
+
+	editLocal(t, file, "# Deploy\n\nNew step.\n")
+	srv.ResetRequests()
+	sr, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync after edit: %v", err)
+	}
+	if !slices.Contains(srv.Requests(), pageGet) || sr.Pushed.Pages != 1 {
+		t.Errorf("edited page not pushed: %+v, %v", sr.Pushed, srv.Requests())
+	}
+	if remote, _ := srv.Page(page.ID); remote.Markdown != "# Deploy\n\nNew step.\n" {
+		t.Errorf("remote = %q", remote.Markdown)
+	}
+}
+
+func TestSyncer_SyncKeepsRemoteEditDuringPush(t *testing.T) {
+	srv, s, page, file, _ := syncFixture(t, "# Deploy\n")
+	ctx := context.Background()
+	editLocal(t, file, "# Deploy\n\nLocal step.\n")
+
+	// An edit stamped before the watermark is missed by the pull phase,
+	// as one made while Sync runs would be.
+	srv.SetClock(func() time.Time { return time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC) })
+	if _, err := srv.Client().Pages.Update(ctx, page.ID, &bookstack.PageUpdateRequest{Markdown: "# Deploy\n\nRemote step.\n"}); err != nil {
+		t.Fatal(err)
+	}
+	sr, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !slices.Equal(sr.Conflicts, []string{"ops/deploy.md"}) || sr.Pushed.Pages != 0 {
+		t.Errorf("report = %+v, pushed %+v", sr, sr.Pushed)
+	}
+	if remote, _ := srv.Page(page.ID); remote.Markdown != "# Deploy\n\nRemote step.\n" {
+		t.Errorf("remote edit overwritten: %q", remote.Markdown)
+	}
+	if got := localBody(t, file); !strings.Contains(got, "Local step.") {
+		t.Errorf("local edit lost: %q", got)
+	}
+}
@@ -0,0 +1,27 @@
+package bookstack
+
+import (
+	"context"
+	"iter"
+)
+
+// DraftsService lists draft pages.
+//
+// A draft is only visible to the user who created it. Bookstack's API
+// cannot create, update or publish drafts: it ignores a draft flag on page
+// requests and always publishes, so drafts have to be started and
+// published in the editor.
+type DraftsService struct {
+	client *Client
+}
+
+// List returns the drafts of the token's user, with optional filtering,
+// sorting and paging.
+func (s *DraftsService) List(ctx context.Context, opts *ListOptions) ([]Page, error) {
+	return s.client.Pages.List(ctx, opts.withFilter("draft", "1"))
+}
+
+// ListAll returns an iterator over all drafts of the token's user, handling pagination automatically.
+func (s *DraftsService) ListAll(ctx context.Context) iter.Seq2[Page, error] {
+	return s.client.Pages.ListAllWith(ctx, (*ListOptions)(nil).withFilter("draft", "1"))
+}
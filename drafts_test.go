@@ -0,0 +1,36 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestDraftsService(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/pages" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("filter[draft]"); got != "1" {
+			t.Errorf("filter[draft] = %q", got)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": 4, "draft": true}}, "total": 1})
+	})
+	ctx := context.Background()
+
+	drafts, err := c.Drafts.List(ctx, nil)
+	if err != nil || len(drafts) != 1 || !drafts[0].Draft {
+		t.Fatalf("List = %+v, %v", drafts, err)
+	}
+	n := 0
+	for _, err := range c.Drafts.ListAll(ctx) {
+		if err != nil {
+			t.Fatalf("ListAll: %v", err)
+		}
+		n++
+	}
+	if n != 1 {
+		t.Errorf("ListAll yielded %d drafts, want 1", n)
+	}
+}
@@ -0,0 +1,133 @@
+package bookstack
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Entity types as reported in SearchResult.Type.
+const (
+	EntityBook    = "book"
+	EntityChapter = "chapter"
+	EntityPage    = "page"
+	EntityShelf   = "bookshelf"
+)
+
+// hydrateWorkers bounds the number of concurrent requests made by Hydrate.
+const hydrateWorkers = 4
+
+// Entity is a typed Bookstack content item: *Book, *Chapter, *Page or *Shelf.
+type Entity interface {
+	// EntityType returns one of EntityBook, EntityChapter, EntityPage or EntityShelf.
+	EntityType() string
+	// EntityID returns the item's ID.
+	EntityID() int
+}
+
+// EntityType implements Entity.
+func (b *Book) EntityType() string { return EntityBook }
+
+// EntityID implements Entity.
+func (b *Book) EntityID() int { return b.ID }
+
+// EntityType implements Entity.
+func (c *Chapter) EntityType() string { return EntityChapter }
+
+// EntityID implements Entity.
+func (c *Chapter) EntityID() int { return c.ID }
+
+// EntityType implements Entity.
+func (p *Page) EntityType() string { return EntityPage }
+
+// EntityID implements Entity.
+func (p *Page) EntityID() int { return p.ID }
+
+// EntityType implements Entity.
+func (s *Shelf) EntityType() string { return EntityShelf }
+
+// EntityID implements Entity.
+func (s *Shelf) EntityID() int { return s.ID }
+
+// Resolve fetches the full item referenced by the search result.
+func (r SearchResult) Resolve(ctx context.Context, c *Client) (Entity, error) {
+	return c.getEntity(ctx, r.Type, r.ID)
+}
+
+// Hydrate resolves each search result to its full typed item, fetching
+// details concurrently with a bounded number of workers.
+// The returned slice is in the same order as results. On failure the first
+// error is returned and outstanding requests are cancelled.
+func (c *Client) Hydrate(ctx context.Context, results []SearchResult) ([]Entity, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	entities := make([]Entity, len(results))
+	jobs := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for range min(hydrateWorkers, len(results)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				e, err := results[i].Resolve(ctx, c)
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				entities[i] = e
+			}
+		}()
+	}
+
+feed:
+	for i := range results {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// getEntity fetches an item by type and ID using the matching service.
+func (c *Client) getEntity(ctx context.Context, typ string, id int) (Entity, error) {
+	switch typ {
+	case EntityBook:
+		return entityOrNil(c.Books.Get(ctx, id))
+	case EntityChapter:
+		return entityOrNil(c.Chapters.Get(ctx, id))
+	case EntityPage:
+		return entityOrNil(c.Pages.Get(ctx, id))
+	case EntityShelf, "shelf":
+		return entityOrNil(c.Shelves.Get(ctx, id))
+	default:
+		return nil, fmt.Errorf("unknown entity type %q", typ)
+	}
+}
+
+// entityOrNil avoids wrapping a nil pointer in a non-nil Entity on error.
+func entityOrNil[E Entity](e E, err error) (Entity, error) {
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
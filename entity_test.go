@@ -0,0 +1,97 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSearchResult_Resolve(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chapters/7" {
+			t.Errorf("path = %s, want /api/chapters/7", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 7, "name": "Chapter Seven"})
+	})
+
+	e, err := SearchResult{Type: "chapter", ID: 7}.Resolve(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ch, ok := e.(*Chapter)
+	if !ok {
+		t.Fatalf("got %T, want *Chapter", e)
+	}
+	if ch.Name != "Chapter Seven" {
+		t.Errorf("Name = %q, want %q", ch.Name, "Chapter Seven")
+	}
+}
+
+func TestSearchResult_Resolve_UnknownType(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+
+	_, err := SearchResult{Type: "user", ID: 1}.Resolve(context.Background(), c)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestClient_Hydrate(t *testing.T) {
+	var calls atomic.Int32
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		id := r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+		json.NewEncoder(w).Encode(map[string]any{"id": json.Number(id), "name": r.URL.Path})
+	})
+
+	results := []SearchResult{
+		{Type: "page", ID: 1},
+		{Type: "book", ID: 2},
+		{Type: "bookshelf", ID: 3},
+		{Type: "chapter", ID: 4},
+		{Type: "page", ID: 5},
+	}
+	entities, err := c.Hydrate(context.Background(), results)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 5 {
+		t.Errorf("API called %d times, want 5", calls.Load())
+	}
+	for i, e := range entities {
+		if e.EntityID() != results[i].ID {
+			t.Errorf("entities[%d].EntityID() = %d, want %d", i, e.EntityID(), results[i].ID)
+		}
+		if e.EntityType() != results[i].Type {
+			t.Errorf("entities[%d].EntityType() = %q, want %q", i, e.EntityType(), results[i].Type)
+		}
+	}
+	if _, ok := entities[2].(*Shelf); !ok {
+		t.Errorf("entities[2] = %T, want *Shelf", entities[2])
+	}
+}
+
+func TestClient_Hydrate_Error(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/pages/2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 1})
+	})
+
+	_, err := c.Hydrate(context.Background(), []SearchResult{
+		{Type: "page", ID: 1},
+		{Type: "page", ID: 2},
+		{Type: "page", ID: 3},
+	})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package bookstack
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Sentinel errors for common API error conditions.
@@ -12,8 +13,28 @@ var (
 	ErrForbidden    = errors.New("forbidden")
 	ErrRateLimited  = errors.New("rate limited")
 	ErrBadRequest   = errors.New("bad request")
+	ErrConflict     = errors.New("conflict")
+
+	// ErrInvalidConfig matches every error returned by Config.Validate.
+	ErrInvalidConfig = errors.New("invalid config")
 )
 
+// ConfigError reports a problem with one field of a Config.
+type ConfigError struct {
+	Field  string // Config field name, e.g. "BaseURL"
+	Reason string // What is wrong with it
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("bookstack: invalid config: %s %s", e.Field, e.Reason)
+}
+
+// Is reports whether target is ErrInvalidConfig.
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
 // APIError represents an error returned by the Bookstack API.
 type APIError struct {
 	StatusCode int    // HTTP status code
@@ -43,7 +64,28 @@ func (e *APIError) Is(target error) bool {
 		return e.StatusCode == 429
 	case ErrBadRequest:
 		return e.StatusCode == 400
+	case ErrConflict:
+		return e.StatusCode == 409
 	default:
 		return false
 	}
 }
+
+// ConflictError is returned by PagesService.UpdateIfUnchanged when the page
+// was changed after the caller last read it. It matches ErrConflict.
+type ConflictError struct {
+	PageID  int
+	Seen    *Page // The version the caller last read
+	Current *Page // The version now stored, including its content
+}
+
+// Error implements the error interface.
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("page %d was changed since it was read (revision %d at %s, now revision %d at %s)",
+		e.PageID, e.Seen.Revision, e.Seen.UpdatedAt.Format(time.RFC3339), e.Current.Revision, e.Current.UpdatedAt.Format(time.RFC3339))
+}
+
+// Is reports whether target is ErrConflict.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
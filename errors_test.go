@@ -42,6 +42,7 @@ func TestAPIError_Is(t *testing.T) {
 		{401, ErrUnauthorized, true},
 		{403, ErrForbidden, true},
 		{404, ErrNotFound, true},
+		{409, ErrConflict, true},
 		{429, ErrRateLimited, true},
 		{500, ErrNotFound, false},
 		{200, ErrBadRequest, false},
@@ -36,9 +36,14 @@ func main() {
 	fmt.Printf("Search results for %q:\n\n", query)
 	for i, r := range results {
 		fmt.Printf("%d. [%s] %s (score: %.1f)\n", i+1, r.Type, r.Name, r.Score)
-		if r.Preview != "" {
+		if r.PreviewHTML.Content != "" {
+			fmt.Printf("   %s\n", bookstack.PreviewANSI(r.PreviewHTML.Content))
+		} else if r.Preview != "" {
 			fmt.Printf("   %s\n", r.Preview)
 		}
+		if r.URL != "" {
+			fmt.Printf("   %s\n", r.URL)
+		}
 	}
 
 	if len(results) == 0 {
@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 // do executes an authenticated API request and unmarshals the response.
@@ -30,10 +33,54 @@ func (c *Client) do(ctx context.Context, method, path string, body, result any)
 		return fmt.Errorf("creating request: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Token %s:%s", c.tokenID, c.tokenSecret))
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	return c.send(req, result)
+}
+
+// doMultipart executes an authenticated multipart/form-data request, as
+// required by file upload endpoints. fields are sent as form values and data
+// as the file part named fileField. Bookstack only parses multipart bodies
+// on POST, so other methods are sent as POST with a _method override.
+func (c *Client) doMultipart(ctx context.Context, method, path string, fields map[string]string, fileField, filename string, data []byte, result any) error {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if method != "POST" {
+		if err := mw.WriteField("_method", method); err != nil {
+			return fmt.Errorf("writing form field: %w", err)
+		}
+	}
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			return fmt.Errorf("writing form field: %w", err)
+		}
+	}
+	if fileField != "" {
+		fw, err := mw.CreateFormFile(fileField, filename)
+		if err != nil {
+			return fmt.Errorf("creating form file: %w", err)
+		}
+		if _, err := fw.Write(data); err != nil {
+			return fmt.Errorf("writing form file: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("closing multipart body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, &buf)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return c.send(req, result)
+}
+
+// send adds authentication to req, executes it and unmarshals the JSON
+// response into result (nil to discard the response body).
+func (c *Client) send(req *http.Request, result any) error {
+	req.Header.Set("Authorization", fmt.Sprintf("Token %s:%s", c.tokenID, c.tokenSecret))
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.httpClient.Do(req)
@@ -110,6 +157,30 @@ func (c *Client) doRaw(ctx context.Context, method, path string) ([]byte, error)
 	return body, nil
 }
 
+// Download fetches a file hosted by the Bookstack instance, such as an
+// uploaded image, using the client's credentials. ref may be an absolute URL
+// on the instance or a path relative to BaseURL. URLs on other hosts are
+// rejected so that credentials are never sent elsewhere.
+func (c *Client) Download(ctx context.Context, ref string) ([]byte, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("parsing URL: %w", err)
+	}
+	if u.IsAbs() {
+		base, err := url.Parse(c.baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing base URL: %w", err)
+		}
+		if !strings.EqualFold(u.Host, base.Host) {
+			return nil, fmt.Errorf("refusing to download %q: not on %s", ref, base.Host)
+		}
+		ref = strings.TrimPrefix(u.RequestURI(), base.Path)
+	} else if !strings.HasPrefix(ref, "/") {
+		ref = "/" + ref
+	}
+	return c.doRaw(ctx, "GET", ref)
+}
+
 // listResponse wraps the common Bookstack list API response format.
 type listResponse[T any] struct {
 	Data  []T `json:"data"`
@@ -147,3 +218,19 @@ func (o *ListOptions) queryString() string {
 	}
 	return "?" + v.Encode()
 }
+
+// withFilter returns a copy of o, which may be nil, with the filter key set
+// to val. Bookstack compares filter values with the database column as
+// written, so boolean fields are filtered with "1" and "0".
+func (o *ListOptions) withFilter(key, val string) *ListOptions {
+	var c ListOptions
+	if o != nil {
+		c = *o
+	}
+	c.Filter = maps.Clone(c.Filter)
+	if c.Filter == nil {
+		c.Filter = map[string]string{}
+	}
+	c.Filter[key] = val
+	return &c
+}
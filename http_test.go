@@ -162,3 +162,29 @@ func TestDo_NonJSONError(t *testing.T) {
 		t.Errorf("Message = %q, want fallback status text", apiErr.Message)
 	}
 }
+
+func TestClient_Download(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/uploads/images/gallery/a.png" {
+			t.Errorf("path = %s, want /uploads/images/gallery/a.png", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") == "" {
+			t.Error("missing Authorization header")
+		}
+		w.Write([]byte("PNG"))
+	})
+
+	for _, ref := range []string{c.baseURL + "/uploads/images/gallery/a.png", "/uploads/images/gallery/a.png"} {
+		data, err := c.Download(context.Background(), ref)
+		if err != nil {
+			t.Fatalf("Download(%q): %v", ref, err)
+		}
+		if string(data) != "PNG" {
+			t.Errorf("Download(%q) = %q", ref, data)
+		}
+	}
+
+	if _, err := c.Download(context.Background(), "https://evil.example.com/x.png"); err == nil {
+		t.Error("expected error for foreign host")
+	}
+}
@@ -0,0 +1,74 @@
+package bookstack
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"iter"
+	"strconv"
+)
+
+// ImagesService handles operations on the image gallery.
+type ImagesService struct {
+	client *Client
+}
+
+// List returns a list of gallery images with optional filtering.
+func (s *ImagesService) List(ctx context.Context, opts *ListOptions) ([]Image, error) {
+	var resp listResponse[Image]
+	err := s.client.do(ctx, "GET", "/api/image-gallery"+opts.queryString(), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// ListAll returns an iterator over all gallery images, handling pagination automatically.
+func (s *ImagesService) ListAll(ctx context.Context) iter.Seq2[Image, error] {
+	return listAll[Image](ctx, s.client, "/api/image-gallery", nil)
+}
+
+// ListAllWith returns an iterator over all gallery images matching opts, handling pagination automatically.
+func (s *ImagesService) ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Image, error] {
+	return listAll[Image](ctx, s.client, "/api/image-gallery", opts)
+}
+
+// Get retrieves a single gallery image by ID.
+func (s *ImagesService) Get(ctx context.Context, id int) (*Image, error) {
+	var img Image
+	err := s.client.do(ctx, "GET", fmt.Sprintf("/api/image-gallery/%d", id), nil, &img)
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+// Create uploads a new image to the gallery.
+func (s *ImagesService) Create(ctx context.Context, req *ImageUploadRequest) (*Image, error) {
+	var img Image
+	fields := map[string]string{
+		"type":        cmp.Or(req.Type, "gallery"),
+		"uploaded_to": strconv.Itoa(req.UploadedTo),
+		"name":        cmp.Or(req.Name, req.Filename),
+	}
+	err := s.client.doMultipart(ctx, "POST", "/api/image-gallery", fields, "image", req.Filename, req.Data, &img)
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+// Update updates an existing gallery image.
+func (s *ImagesService) Update(ctx context.Context, id int, req *ImageUpdateRequest) (*Image, error) {
+	var img Image
+	err := s.client.do(ctx, "PUT", fmt.Sprintf("/api/image-gallery/%d", id), req, &img)
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+// Delete deletes a gallery image by ID.
+func (s *ImagesService) Delete(ctx context.Context, id int) error {
+	return s.client.do(ctx, "DELETE", fmt.Sprintf("/api/image-gallery/%d", id), nil, nil)
+}
@@ -0,0 +1,63 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestImagesService_Create(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/image-gallery" {
+			t.Errorf("path = %s, want /api/image-gallery", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm: %v", err)
+		}
+		if r.FormValue("type") != "gallery" || r.FormValue("uploaded_to") != "7" || r.FormValue("name") != "arch.png" {
+			t.Errorf("form = %v", r.MultipartForm.Value)
+		}
+		f, _, err := r.FormFile("image")
+		if err != nil {
+			t.Fatalf("FormFile: %v", err)
+		}
+		data, _ := io.ReadAll(f)
+		if string(data) != "PNG" {
+			t.Errorf("image data = %q", data)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"id": 1, "name": "arch.png", "url": "https://docs.example.com/uploads/images/gallery/arch.png",
+		})
+	})
+
+	img, err := c.Images.Create(context.Background(), &ImageUploadRequest{
+		UploadedTo: 7,
+		Filename:   "arch.png",
+		Data:       []byte("PNG"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if img.URL == "" {
+		t.Error("URL is empty")
+	}
+}
+
+func TestImagesService_Update(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/api/image-gallery/1" {
+			t.Errorf("%s %s, want PUT /api/image-gallery/1", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 1, "name": "renamed"})
+	})
+
+	img, err := c.Images.Update(context.Background(), 1, &ImageUpdateRequest{Name: "renamed"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if img.Name != "renamed" {
+		t.Errorf("Name = %q", img.Name)
+	}
+}
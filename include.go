@@ -0,0 +1,197 @@
+package bookstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/markup"
+)
+
+// includeTag matches a Bookstack include tag: {{@123}} includes a whole
+// page and {{@123#bkmrk-intro}} the element of page 123 with that ID.
+var includeTag = regexp.MustCompile(`\{\{@\s*(\d+)(?:#([^}\s]+))?\s*\}\}`)
+
+// includeParagraph matches an include tag that is the only content of a
+// paragraph, which the included content replaces as a whole.
+var includeParagraph = regexp.MustCompile(`<p\b[^>]*>\s*(` + includeTag.String() + `)\s*</p>`)
+
+// singleParagraph matches content that is one paragraph, capturing its
+// inner HTML.
+var singleParagraph = regexp.MustCompile(`(?s)^\s*<p\b[^>]*>(.*)</p>\s*$`)
+
+// IncludeCycleError is returned by IncludeResolver when pages include
+// each other, directly or through other pages.
+type IncludeCycleError struct {
+	Path []int // IDs of the pages forming the cycle; the first repeats at the end
+}
+
+// Error implements the error interface.
+func (e *IncludeCycleError) Error() string {
+	ids := make([]string, len(e.Path))
+	for i, id := range e.Path {
+		ids[i] = strconv.Itoa(id)
+	}
+	return "bookstack: include cycle: " + strings.Join(ids, " -> ")
+}
+
+// IncludeResolver expands the include tags in page content. The API
+// returns Page.HTML with the tags as written, so content read through it
+// shows "{{@123#bkmrk-intro}}" where the Bookstack UI shows the included
+// page or section.
+//
+// Included content is expanded recursively. Fetched pages and expanded
+// includes are cached for the lifetime of the resolver, so resolving many
+// pages that share includes fetches each page once; create a new resolver
+// to see later edits. A resolver is safe for concurrent use.
+type IncludeResolver struct {
+	client *Client
+
+	// KeepUnresolved leaves tags that refer to a missing or inaccessible
+	// page or section in place. By default they are removed, as Bookstack
+	// does when rendering a page.
+	KeepUnresolved bool
+
+	mu       sync.Mutex
+	pages    map[int]*Page
+	expanded map[string]string
+}
+
+// NewIncludeResolver creates an IncludeResolver that fetches included
+// pages with client.
+func NewIncludeResolver(client *Client) *IncludeResolver {
+	return &IncludeResolver{
+		client:   client,
+		pages:    make(map[int]*Page),
+		expanded: make(map[string]string),
+	}
+}
+
+// ResolvePage returns the HTML of page with its include tags expanded.
+// An include of the page itself, directly or through other pages, is
+// reported as an *IncludeCycleError. To get Markdown, convert the result
+// with markup.ToMarkdown.
+func (r *IncludeResolver) ResolvePage(ctx context.Context, page *Page) (string, error) {
+	r.mu.Lock()
+	r.pages[page.ID] = page
+	r.mu.Unlock()
+	return r.expand(ctx, page.HTML, []int{page.ID})
+}
+
+// Resolve returns html with its include tags expanded.
+func (r *IncludeResolver) Resolve(ctx context.Context, html string) (string, error) {
+	return r.expand(ctx, html, nil)
+}
+
+// expand replaces the include tags in html. stack holds the IDs of the
+// pages being expanded, outermost first.
+func (r *IncludeResolver) expand(ctx context.Context, html string, stack []int) (string, error) {
+	if !strings.Contains(html, "{{@") {
+		return html, nil
+	}
+	var err error
+	replace := func(re *regexp.Regexp, block bool) {
+		html = re.ReplaceAllStringFunc(html, func(match string) string {
+			if err != nil {
+				return match
+			}
+			tag := match
+			if block {
+				tag = re.FindStringSubmatch(match)[1]
+			}
+			var content string
+			var ok bool
+			content, ok, err = r.include(ctx, tag, stack)
+			switch {
+			case err != nil:
+				return match
+			case !ok && r.KeepUnresolved:
+				return match
+			case !block:
+				if m := singleParagraph.FindStringSubmatch(content); m != nil && !strings.Contains(m[1], "</p>") {
+					content = m[1]
+				}
+			}
+			return content
+		})
+	}
+	replace(includeParagraph, true)
+	replace(includeTag, false)
+	return html, err
+}
+
+// include returns the expanded content that tag refers to. It reports
+// false if the page or section does not exist or is not visible to the
+// API user.
+func (r *IncludeResolver) include(ctx context.Context, tag string, stack []int) (string, bool, error) {
+	m := includeTag.FindStringSubmatch(tag)
+	id, err := strconv.Atoi(m[1])
+	if err != nil {
+		return "", false, nil
+	}
+	section := m[2]
+	for i, sid := range stack {
+		if sid == id {
+			path := append(append([]int(nil), stack[i:]...), id)
+			return "", false, &IncludeCycleError{Path: path}
+		}
+	}
+
+	key := m[1] + "#" + section
+	r.mu.Lock()
+	content, cached := r.expanded[key]
+	r.mu.Unlock()
+	if cached {
+		return content, true, nil
+	}
+
+	page, err := r.page(ctx, id)
+	if err != nil {
+		return "", false, fmt.Errorf("fetching included page %d: %w", id, err)
+	}
+	if page == nil {
+		return "", false, nil
+	}
+	content = page.HTML
+	if section != "" {
+		var ok bool
+		if content, ok = markup.ElementByID(page.HTML, section); !ok {
+			return "", false, nil
+		}
+	}
+	content, err = r.expand(ctx, content, append(stack[:len(stack):len(stack)], id))
+	if err != nil {
+		return "", false, err
+	}
+
+	r.mu.Lock()
+	r.expanded[key] = content
+	r.mu.Unlock()
+	return content, true, nil
+}
+
+// page returns the page with the given ID, fetching it on first use. It
+// returns nil if the page does not exist or is not visible to the API user.
+func (r *IncludeResolver) page(ctx context.Context, id int) (*Page, error) {
+	r.mu.Lock()
+	page, ok := r.pages[id]
+	r.mu.Unlock()
+	if ok {
+		return page, nil
+	}
+	page, err := r.client.Pages.Get(ctx, id)
+	switch {
+	case errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden):
+		page = nil
+	case err != nil:
+		return nil, err
+	}
+	r.mu.Lock()
+	r.pages[id] = page
+	r.mu.Unlock()
+	return page, nil
+}
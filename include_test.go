@@ -0,0 +1,141 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// includeClient returns a client serving the given page HTML by ID and a
+// map counting the requests for each page.
+func includeClient(t *testing.T, pages map[int]string) (*Client, map[int]int) {
+	t.Helper()
+	fetches := make(map[int]int)
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/pages/"))
+		if err != nil {
+			t.Errorf("unexpected request %s", r.URL.Path)
+			return
+		}
+		fetches[id]++
+		if id == 500 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		html, ok := pages[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 404, "message": "Page not found"}})
+			return
+		}
+		json.NewEncoder(w).Encode(Page{ID: id, HTML: html})
+	})
+	return c, fetches
+}
+
+func TestIncludeResolver_Resolve(t *testing.T) {
+	pages := map[int]string{
+		10: `<p id="bkmrk-a">Shared <b>note</b>.</p><p id="bkmrk-b">Second.</p>`,
+		11: `<h2 id="bkmrk-h">Nested</h2><p id="bkmrk-n">{{@10#bkmrk-b}}</p>`,
+		12: `<ul id="bkmrk-l"><li>one</li></ul>`,
+	}
+	tests := []struct {
+		name, in, want string
+	}{
+		{"whole page", `<p id="x">{{@10}}</p>`,
+			`<p id="bkmrk-a">Shared <b>note</b>.</p><p id="bkmrk-b">Second.</p>`},
+		{"section as block", `<p id="x">{{@10#bkmrk-a}}</p><p>after</p>`,
+			`<p id="bkmrk-a">Shared <b>note</b>.</p><p>after</p>`},
+		{"paragraph inline", `<p>See: {{@10#bkmrk-a}} Done.</p>`,
+			`<p>See: Shared <b>note</b>. Done.</p>`},
+		{"block inline", `<p>List {{@12#bkmrk-l}}</p>`,
+			`<p>List <ul id="bkmrk-l"><li>one</li></ul></p>`},
+		{"nested", `{{@11}}`,
+			`<h2 id="bkmrk-h">Nested</h2><p id="bkmrk-b">Second.</p>`},
+		{"spaces in tag", `<p>{{@ 10#bkmrk-b }}</p>`, `<p id="bkmrk-b">Second.</p>`},
+		{"missing page", `<p>a</p><p>{{@99}}</p><p>b {{@99#x}}</p>`, `<p>a</p><p>b </p>`},
+		{"missing section", `<p>{{@10#bkmrk-none}}</p>`, ``},
+		{"no tags", `<p>{{not an include}}</p>`, `<p>{{not an include}}</p>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := includeClient(t, pages)
+			got, err := NewIncludeResolver(c).Resolve(context.Background(), tt.in)
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludeResolver_KeepUnresolved(t *testing.T) {
+	c, _ := includeClient(t, map[int]string{10: `<p id="bkmrk-a">A</p>`})
+	r := NewIncludeResolver(c)
+	r.KeepUnresolved = true
+	in := `<p>{{@99}}</p><p>x {{@10#bkmrk-none}}</p><p>{{@10#bkmrk-a}}</p>`
+	got, err := r.Resolve(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	want := `<p>{{@99}}</p><p>x {{@10#bkmrk-none}}</p><p id="bkmrk-a">A</p>`
+	if got != want {
+		t.Errorf("Resolve = %s, want %s", got, want)
+	}
+}
+
+func TestIncludeResolver_Cycle(t *testing.T) {
+	c, _ := includeClient(t, map[int]string{
+		1: `<p>one</p><p>{{@2}}</p>`,
+		2: `<p id="bkmrk-s">{{@3#bkmrk-t}}</p>`,
+		3: `<p id="bkmrk-t">three {{@1}}</p>`,
+		4: `<p>{{@4}}</p>`,
+	})
+	r := NewIncludeResolver(c)
+	_, err := r.ResolvePage(context.Background(), &Page{ID: 1, HTML: `<p>{{@2}}</p>`})
+	var cycle *IncludeCycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("err = %v, want *IncludeCycleError", err)
+	}
+	if got := cycle.Error(); got != "bookstack: include cycle: 1 -> 2 -> 3 -> 1" {
+		t.Errorf("Error() = %q", got)
+	}
+
+	_, err = r.Resolve(context.Background(), `{{@4}}`)
+	if !errors.As(err, &cycle) || len(cycle.Path) != 2 {
+		t.Errorf("self include: err = %v", err)
+	}
+}
+
+func TestIncludeResolver_Cache(t *testing.T) {
+	c, fetches := includeClient(t, map[int]string{
+		10: `<p id="bkmrk-a">A</p><p id="bkmrk-b">B</p>`,
+		11: `<p>{{@10#bkmrk-a}}</p>`,
+	})
+	r := NewIncludeResolver(c)
+	ctx := context.Background()
+	for _, in := range []string{`{{@10#bkmrk-a}} {{@10#bkmrk-b}}`, `{{@11}}`, `{{@11}} {{@99}}`, `{{@99}}`} {
+		if _, err := r.Resolve(ctx, in); err != nil {
+			t.Fatalf("Resolve(%q): %v", in, err)
+		}
+	}
+	for id, want := range map[int]int{10: 1, 11: 1, 99: 1} {
+		if fetches[id] != want {
+			t.Errorf("page %d fetched %d times, want %d", id, fetches[id], want)
+		}
+	}
+}
+
+func TestIncludeResolver_FetchError(t *testing.T) {
+	c, _ := includeClient(t, nil)
+	_, err := NewIncludeResolver(c).Resolve(context.Background(), `<p>{{@500}}</p>`)
+	if err == nil || !strings.Contains(err.Error(), "fetching included page 500") {
+		t.Errorf("err = %v", err)
+	}
+}
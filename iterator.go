@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"iter"
+	"net/url"
 )
 
 const defaultPageSize = 100
@@ -16,13 +17,44 @@ type listAllResponse struct {
 }
 
 // listAll returns an iterator that paginates through all results for the given path.
-func listAll[T any](ctx context.Context, c *Client, path string) iter.Seq2[T, error] {
+// Sort and Filter from opts are applied to every request; Count sets the page
+// size and Offset the starting position.
+func listAll[T any](ctx context.Context, c *Client, path string, opts *ListOptions) iter.Seq2[T, error] {
+	pageSize, start := defaultPageSize, 0
+	if opts != nil {
+		if opts.Count > 0 {
+			pageSize = opts.Count
+		}
+		start = opts.Offset
+	}
+	return paginate[T](ctx, c, func(_, offset int) string {
+		v := url.Values{}
+		if opts != nil {
+			if opts.Sort != "" {
+				v.Set("sort", opts.Sort)
+			}
+			for key, val := range opts.Filter {
+				v.Set("filter["+key+"]", val)
+			}
+		}
+		q := fmt.Sprintf("count=%d&offset=%d", pageSize, start+offset)
+		if len(v) > 0 {
+			q += "&" + v.Encode()
+		}
+		return path + "?" + q
+	})
+}
+
+// paginate returns an iterator that requests successive result pages until
+// the reported total is reached or an empty page is returned.
+// pageURL builds the request path for the zero-based page number n, where
+// offset is the number of items already yielded.
+func paginate[T any](ctx context.Context, c *Client, pageURL func(n, offset int) string) iter.Seq2[T, error] {
 	return func(yield func(T, error) bool) {
 		offset := 0
-		for {
+		for n := 0; ; n++ {
 			var resp listAllResponse
-			url := fmt.Sprintf("%s?count=%d&offset=%d", path, defaultPageSize, offset)
-			if err := c.do(ctx, "GET", url, nil, &resp); err != nil {
+			if err := c.do(ctx, "GET", pageURL(n, offset), nil, &resp); err != nil {
 				var zero T
 				yield(zero, err)
 				return
@@ -0,0 +1,32 @@
+package markup
+
+// ElementByID returns the element of the HTML fragment s whose id
+// attribute is id, exactly as written in s, and whether there is one.
+// Bookstack gives the block elements of a page IDs such as "bkmrk-intro",
+// which include tags and links use to address part of the page.
+func ElementByID(s, id string) (string, bool) {
+	if id == "" {
+		return "", false
+	}
+	if n := findID(parseHTML(s), id); n != nil {
+		return n.src, true
+	}
+	return "", false
+}
+
+// findID returns the first element below n, in document order, with the
+// given id.
+func findID(n *node, id string) *node {
+	for _, c := range n.children {
+		if c.tag == "" || c.tag == "!" {
+			continue
+		}
+		if c.attr("id") == id {
+			return c
+		}
+		if found := findID(c, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package markup
+
+import "testing"
+
+func TestElementByID(t *testing.T) {
+	const page = `<h2 id="bkmrk-setup">Setup</h2>` +
+		`<p id="bkmrk-intro">Run <code>make</code>.</p>` +
+		`<ul id="bkmrk-list"><li id="bkmrk-one">one<li>two</ul>` +
+		`<p id="bkmrk-open">unclosed`
+	tests := []struct {
+		id, want string
+		ok       bool
+	}{
+		{"bkmrk-intro", `<p id="bkmrk-intro">Run <code>make</code>.</p>`, true},
+		{"bkmrk-one", `<li id="bkmrk-one">one`, true},
+		{"bkmrk-list", `<ul id="bkmrk-list"><li id="bkmrk-one">one<li>two</ul>`, true},
+		{"bkmrk-open", `<p id="bkmrk-open">unclosed`, true},
+		{"bkmrk-missing", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ElementByID(page, tt.id)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ElementByID(%q) = %q, %v; want %q, %v", tt.id, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package markup
+
+import (
+	"html"
+	"strings"
+)
+
+// node is an element, text or comment of a parsed HTML fragment.
+type node struct {
+	tag      string // Lower-case element name; "" for text, "!" for comments
+	text     string // Decoded text of text nodes
+	attrs    map[string]string
+	open     string // Start tag as written
+	src      string // Whole element as written
+	parent   *node
+	children []*node
+	start    int
+}
+
+func (n *node) attr(name string) string { return n.attrs[name] }
+
+func (n *node) hasAttr(name string) bool {
+	_, ok := n.attrs[name]
+	return ok
+}
+
+// hasClass reports whether the class attribute of n contains class.
+func (n *node) hasClass(class string) bool {
+	return strings.Contains(" "+n.attr("class")+" ", " "+class+" ")
+}
+
+// textContent returns the decoded text of n and its descendants.
+func (n *node) textContent() string {
+	if n.tag == "" {
+		return n.text
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		if c.tag != "!" {
+			b.WriteString(c.textContent())
+		}
+	}
+	return b.String()
+}
+
+// voidElements have no end tag.
+var voidElements = set("area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "source", "track", "wbr")
+
+// rawTextElements contain text that is not parsed as markup.
+var rawTextElements = set("script", "style", "textarea")
+
+// closesP are elements whose start tag ends an open paragraph.
+var closesP = set("address", "article", "aside", "blockquote", "details", "div", "dl", "fieldset",
+	"figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "main",
+	"nav", "ol", "p", "pre", "section", "table", "ul")
+
+func set(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
+
+// parseHTML parses an HTML fragment into a tree. It is lenient in the way
+// browsers are for the markup found in pages: unclosed paragraphs, list
+// items and table cells are closed implicitly and stray end tags are
+// ignored.
+func parseHTML(s string) *node {
+	p := &htmlParser{s: s, root: &node{tag: "#root"}}
+	p.cur = p.root
+	p.parse()
+	return p.root
+}
+
+type htmlParser struct {
+	s    string
+	root *node
+	cur  *node
+}
+
+func (p *htmlParser) parse() {
+	s := p.s
+	i := 0
+	for i < len(s) {
+		lt := strings.IndexByte(s[i:], '<')
+		if lt < 0 {
+			p.text(s[i:])
+			break
+		}
+		p.text(s[i : i+lt])
+		i += lt
+		switch {
+		case strings.HasPrefix(s[i:], "<!--"):
+			end := strings.Index(s[i+4:], "-->")
+			if end < 0 {
+				end = len(s)
+			} else {
+				end += i + 7
+			}
+			p.cur.children = append(p.cur.children, &node{tag: "!", src: s[i:end], parent: p.cur})
+			i = end
+		case strings.HasPrefix(s[i:], "</"):
+			gt := strings.IndexByte(s[i:], '>')
+			if gt < 0 {
+				p.text(s[i:])
+				return
+			}
+			name := strings.ToLower(strings.TrimSpace(s[i+2 : i+gt]))
+			p.close(name, i, i+gt+1)
+			i += gt + 1
+		case i+1 < len(s) && isLetter(s[i+1]):
+			n, size, selfClosing := parseStartTag(s[i:])
+			if size == 0 {
+				p.text("<")
+				i++
+				continue
+			}
+			n.start = i
+			i += size
+			p.implicitClose(n.tag, n.start)
+			n.parent = p.cur
+			p.cur.children = append(p.cur.children, n)
+			switch {
+			case voidElements[n.tag] || selfClosing:
+				n.src = n.open
+			case rawTextElements[n.tag]:
+				end := indexFold(s[i:], "</"+n.tag)
+				if end < 0 {
+					end = len(s) - i
+				}
+				n.children = []*node{{text: s[i : i+end], parent: n}}
+				i += end
+				gt := strings.IndexByte(s[i:], '>')
+				if gt < 0 {
+					gt = len(s) - i - 1
+				}
+				n.src = s[n.start : i+gt+1]
+				i += gt + 1
+			default:
+				p.cur = n
+			}
+		default:
+			p.text("<")
+			i++
+		}
+	}
+	for p.cur != p.root {
+		p.cur.src = s[p.cur.start:]
+		p.cur = p.cur.parent
+	}
+}
+
+func (p *htmlParser) text(s string) {
+	if s == "" {
+		return
+	}
+	p.cur.children = append(p.cur.children, &node{text: html.UnescapeString(s), parent: p.cur})
+}
+
+// close ends the innermost open element named name. The end tag spans
+// s[start:end]; elements left open inside it end where it starts.
+func (p *htmlParser) close(name string, start, end int) {
+	for n := p.cur; n != p.root; n = n.parent {
+		if n.tag != name {
+			continue
+		}
+		for c := p.cur; c != n; c = c.parent {
+			c.src = p.s[c.start:start]
+		}
+		n.src = p.s[n.start:end]
+		p.cur = n.parent
+		return
+	}
+}
+
+// implicitClose closes the elements that a start tag for tag ends.
+func (p *htmlParser) implicitClose(tag string, at int) {
+	var stops, closes []string
+	switch {
+	case closesP[tag]:
+		closes, stops = []string{"p"}, []string{"div", "li", "td", "th", "blockquote", "details"}
+	case tag == "li":
+		closes, stops = []string{"li"}, []string{"ul", "ol"}
+	case tag == "td" || tag == "th":
+		closes, stops = []string{"td", "th"}, []string{"tr", "table"}
+	case tag == "tr":
+		closes, stops = []string{"tr", "td", "th"}, []string{"table", "thead", "tbody", "tfoot"}
+	case tag == "thead" || tag == "tbody" || tag == "tfoot":
+		closes, stops = []string{"thead", "tbody", "tfoot", "tr", "td", "th"}, []string{"table"}
+	default:
+		return
+	}
+	for n := p.cur; n != p.root; n = n.parent {
+		switch {
+		case contains(stops, n.tag):
+			return
+		case contains(closes, n.tag):
+			for c := p.cur; c != n.parent; c = c.parent {
+				c.src = p.s[c.start:at]
+			}
+			p.cur = n.parent
+			if tag == "p" || closesP[tag] && n.tag == "p" {
+				return
+			}
+			// Keep closing, so that a new row also ends the open cell.
+			p.implicitClose(tag, at)
+			return
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStartTag parses the start tag at the beginning of s. It returns the
+// element, the length of the tag and whether it ends with "/>", or a size
+// of 0 if s does not begin with a well-formed start tag.
+func parseStartTag(s string) (*node, int, bool) {
+	i := 1
+	for i < len(s) && (isLetter(s[i]) || isDigit(s[i]) || s[i] == '-' || s[i] == ':') {
+		i++
+	}
+	n := &node{tag: strings.ToLower(s[1:i]), attrs: map[string]string{}}
+	for {
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			return nil, 0, false
+		}
+		switch {
+		case s[i] == '>':
+			n.open = s[:i+1]
+			return n, i + 1, false
+		case strings.HasPrefix(s[i:], "/>"):
+			n.open = s[:i+2]
+			return n, i + 2, true
+		case s[i] == '/' || s[i] == '<':
+			i++
+			continue
+		}
+		start := i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && !strings.HasPrefix(s[i:], "/>") {
+			i++
+		}
+		name := strings.ToLower(s[start:i])
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) || s[i] != '=' {
+			n.attrs[name] = ""
+			continue
+		}
+		i++
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			return nil, 0, false
+		}
+		var val string
+		if q := s[i]; q == '"' || q == '\'' {
+			end := strings.IndexByte(s[i+1:], q)
+			if end < 0 {
+				return nil, 0, false
+			}
+			val = s[i+1 : i+1+end]
+			i += end + 2
+		} else {
+			start := i
+			for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+				i++
+			}
+			val = s[start:i]
+		}
+		n.attrs[name] = html.UnescapeString(val)
+	}
+}
+
+// indexFold returns the index of the first case-insensitive match of
+// substr in s, or -1.
+func indexFold(s, substr string) int {
+	return strings.Index(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func isLetter(c byte) bool { return c|0x20 >= 'a' && c|0x20 <= 'z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isSpace(c byte) bool  { return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' }
@@ -0,0 +1,56 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+)
+
+// dump renders a tree as nested tags, for comparing structure.
+func dump(n *node) string {
+	var b strings.Builder
+	for _, c := range n.children {
+		switch c.tag {
+		case "":
+			b.WriteString(c.text)
+		case "!":
+			b.WriteString("<!>")
+		default:
+			b.WriteString("<" + c.tag + ">" + dump(c) + "</" + c.tag + ">")
+		}
+	}
+	return b.String()
+}
+
+func TestParseHTML(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`<p>a <b>b</b></p>`, `<p>a <b>b</b></p>`},
+		{`<p>one<p>two`, `<p>one</p><p>two</p>`},
+		{`<p>text<ul><li>a<li>b</ul>`, `<p>text</p><ul><li>a</li><li>b</li></ul>`},
+		{`<table><tr><td>1<td>2<tr><td>3</table>`, `<table><tr><td>1</td><td>2</td></tr><tr><td>3</td></tr></table>`},
+		{`a<br>b<img src=x.png>c</span>d`, `a<br></br>b<img></img>cd`},
+		{`<script>if (a < b) {}</script>x`, `<script>if (a < b) {}</script>x`},
+		{`<!-- note -->&amp;&lt;`, `<!>&<`},
+		{`1 < 2 <`, `1 < 2 <`},
+	}
+	for _, tt := range tests {
+		if got := dump(parseHTML(tt.in)); got != tt.want {
+			t.Errorf("parseHTML(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseHTML_Source(t *testing.T) {
+	root := parseHTML(`<div drawio-diagram="5" data-x='a&amp;b'><img src="d.png"></div><p>after`)
+	div := root.children[0]
+	if div.src != `<div drawio-diagram="5" data-x='a&amp;b'><img src="d.png"></div>` {
+		t.Errorf("src = %q", div.src)
+	}
+	if div.attr("drawio-diagram") != "5" || div.attr("data-x") != "a&b" {
+		t.Errorf("attrs = %v", div.attrs)
+	}
+	if p := root.children[1]; p.src != "<p>after" {
+		t.Errorf("unclosed src = %q", p.src)
+	}
+}
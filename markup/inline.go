@@ -0,0 +1,351 @@
+package markup
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	inlineTag  = regexp.MustCompile(`^(?:<!--[\s\S]*?-->|</?[a-zA-Z][a-zA-Z0-9-]*(?:\s+[a-zA-Z_:][\w.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>)`)
+	autolink   = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*)>`)
+	emailLink  = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9.-]*[a-zA-Z0-9])?)>`)
+	entityRef  = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+	emphasisOf = map[byte][3]string{
+		'*': {"em", "strong", ""},
+		'_': {"em", "strong", ""},
+		'~': {"", "del", ""},
+	}
+)
+
+// parseInline converts inline Markdown to HTML.
+func parseInline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch c {
+		case '\\':
+			switch {
+			case i+1 < len(s) && s[i+1] == '\n':
+				b.WriteString("<br>\n")
+				i = skipSpaces(s, i+2)
+			case i+1 < len(s) && isPunct(s[i+1]):
+				b.WriteString(escapeHTML(s[i+1 : i+2]))
+				i += 2
+			default:
+				b.WriteByte('\\')
+				i++
+			}
+		case '\n':
+			b.WriteByte('\n')
+			i = skipSpaces(s, i+1)
+		case ' ':
+			j := skipSpaces(s, i)
+			switch {
+			case j < len(s) && s[j] == '\n':
+				if j-i >= 2 {
+					b.WriteString("<br>")
+				}
+			case j < len(s):
+				b.WriteString(s[i:j])
+			}
+			i = j
+		case '`':
+			n := runLength(s, i)
+			end := findCodeEnd(s, i+n, n)
+			if end < 0 {
+				b.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			code := strings.ReplaceAll(s[i+n:end], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			b.WriteString("<code>" + escapeHTML(code) + "</code>")
+			i = end + n
+		case '!', '[':
+			if c == '!' && (i+1 >= len(s) || s[i+1] != '[') {
+				b.WriteByte('!')
+				i++
+				continue
+			}
+			start := i
+			if c == '!' {
+				start++
+			}
+			if h, end, ok := link(s, start, c == '!'); ok {
+				b.WriteString(h)
+				i = end
+				continue
+			}
+			b.WriteByte(c)
+			i++
+		case '<':
+			switch rest := s[i:]; {
+			case autolink.MatchString(rest):
+				m := autolink.FindStringSubmatch(rest)
+				b.WriteString(`<a href="` + escapeAttr(m[1]) + `">` + escapeHTML(m[1]) + "</a>")
+				i += len(m[0])
+			case emailLink.MatchString(rest):
+				m := emailLink.FindStringSubmatch(rest)
+				b.WriteString(`<a href="mailto:` + escapeAttr(m[1]) + `">` + escapeHTML(m[1]) + "</a>")
+				i += len(m[0])
+			case inlineTag.MatchString(rest):
+				m := inlineTag.FindString(rest)
+				b.WriteString(m)
+				i += len(m)
+			default:
+				b.WriteString("&lt;")
+				i++
+			}
+		case '>':
+			b.WriteString("&gt;")
+			i++
+		case '&':
+			if m := entityRef.FindString(s[i:]); m != "" {
+				b.WriteString(m)
+				i += len(m)
+			} else {
+				b.WriteString("&amp;")
+				i++
+			}
+		case '*', '_', '~':
+			n := runLength(s, i)
+			if h, end, ok := emphasis(s, i, n); ok {
+				b.WriteString(h)
+				i = end
+				continue
+			}
+			// Try again with a shorter opening run.
+			b.WriteByte(c)
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+// runLength returns the number of repetitions of s[i] starting at i.
+func runLength(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+// findCodeEnd returns the start of the run of exactly n backticks closing
+// a code span that starts at i, or -1.
+func findCodeEnd(s string, i, n int) int {
+	for i < len(s) {
+		k := strings.IndexByte(s[i:], '`')
+		if k < 0 {
+			return -1
+		}
+		i += k
+		m := runLength(s, i)
+		if m == n {
+			return i
+		}
+		i += m
+	}
+	return -1
+}
+
+func isWhitespace(c byte) bool { return c == ' ' || c == '\n' || c == '\t' }
+
+func isAlnum(c byte) bool { return isLetter(c) || isDigit(c) || c >= 0x80 }
+
+// flanking reports whether the delimiter run s[i:i+n] can open and close
+// emphasis, following CommonMark's rules.
+func flanking(s string, i, n int) (left, right bool) {
+	prev, next := byte(' '), byte(' ')
+	if i > 0 {
+		prev = s[i-1]
+	}
+	if i+n < len(s) {
+		next = s[i+n]
+	}
+	left = !isWhitespace(next) && (!isPunct(next) || isWhitespace(prev) || isPunct(prev))
+	right = !isWhitespace(prev) && (!isPunct(prev) || isWhitespace(next) || isPunct(next))
+	if s[i] == '_' {
+		// Underscores do not work inside words.
+		left, right = left && (!right || isPunct(prev)), right && (!left || isPunct(next))
+	}
+	return left, right
+}
+
+// emphasis parses emphasis opened by the run of n delimiters at i. It
+// returns the HTML and the index after the closing run.
+func emphasis(s string, i, n int) (string, int, bool) {
+	c := s[i]
+	tags := emphasisOf[c]
+	if n > 3 || n > 2 && c == '~' || tags[n-1] == "" && n < 3 {
+		return "", 0, false
+	}
+	open, openRight := flanking(s, i, n)
+	if !open {
+		return "", 0, false
+	}
+	for j := i + n; j < len(s); {
+		switch s[j] {
+		case '\\':
+			j += 2
+			continue
+		case '`':
+			m := runLength(s, j)
+			if end := findCodeEnd(s, j+m, m); end >= 0 {
+				j = end + m
+			} else {
+				j += m
+			}
+			continue
+		case c:
+			m := runLength(s, j)
+			left, right := flanking(s, j, m)
+			// A longer run closes with its first delimiters, as in
+			// **a***b*, unless CommonMark's rule of three applies to runs
+			// that can both open and close.
+			fits := m == n || m > n && c != '~'
+			if (left || openRight) && (n+m)%3 == 0 && (n%3 != 0 || m%3 != 0) {
+				fits = false
+			}
+			if right && fits {
+				inner := parseInline(s[i+n : j])
+				if n == 3 {
+					return "<em><strong>" + inner + "</strong></em>", j + n, true
+				}
+				return "<" + tags[n-1] + ">" + inner + "</" + tags[n-1] + ">", j + n, true
+			}
+			if left {
+				// Skip over nested emphasis, as in **a *b* c**.
+				if _, end, ok := emphasis(s, j, m); ok {
+					j = end
+					continue
+				}
+			}
+			j += m
+			continue
+		}
+		j++
+	}
+	return "", 0, false
+}
+
+// link parses a link or image whose text starts with the bracket at i.
+func link(s string, i int, image bool) (string, int, bool) {
+	// Find the closing bracket, allowing nested brackets.
+	depth := 0
+	j := i
+	for ; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			m := runLength(s, j)
+			if end := findCodeEnd(s, j+m, m); end >= 0 {
+				j = end + m - 1
+			} else {
+				j += m - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if j >= len(s) || j+1 >= len(s) || s[j+1] != '(' {
+		return "", 0, false
+	}
+	text := s[i+1 : j]
+	dest, title, end, ok := linkDestinationAt(s, j+2)
+	if !ok {
+		return "", 0, false
+	}
+	titleAttr := ""
+	if title != "" {
+		titleAttr = ` title="` + escapeAttr(title) + `"`
+	}
+	if image {
+		return `<img src="` + escapeAttr(dest) + `" alt="` + escapeAttr(plainText(text)) + `"` + titleAttr + `>`, end, true
+	}
+	return `<a href="` + escapeAttr(dest) + `"` + titleAttr + `>` + parseInline(text) + "</a>", end, true
+}
+
+// plainText returns inline Markdown without its markup, for alt text.
+func plainText(s string) string {
+	root := parseHTML(parseInline(s))
+	return root.textContent()
+}
+
+// linkDestinationAt parses "dest "title")" starting at i.
+func linkDestinationAt(s string, i int) (dest, title string, end int, ok bool) {
+	i = skipWhitespace(s, i)
+	if i < len(s) && s[i] == '<' {
+		k := strings.IndexAny(s[i+1:], ">\n")
+		if k < 0 || s[i+1+k] != '>' {
+			return "", "", 0, false
+		}
+		dest = s[i+1 : i+1+k]
+		i += k + 2
+	} else {
+		start, depth := i, 0
+		for ; i < len(s) && !isWhitespace(s[i]); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '(' {
+				depth++
+			} else if s[i] == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		dest = s[start:min(i, len(s))]
+	}
+	dest = unescapeMarkdown(dest)
+	i = skipWhitespace(s, i)
+	if i < len(s) && (s[i] == '"' || s[i] == '\'' || s[i] == '(') {
+		closeCh := s[i]
+		if closeCh == '(' {
+			closeCh = ')'
+		}
+		k := i + 1
+		for ; k < len(s) && s[k] != closeCh; k++ {
+			if s[k] == '\\' {
+				k++
+			}
+		}
+		if k >= len(s) {
+			return "", "", 0, false
+		}
+		title = unescapeMarkdown(s[i+1 : k])
+		i = skipWhitespace(s, k+1)
+	}
+	if i >= len(s) || s[i] != ')' {
+		return "", "", 0, false
+	}
+	return dest, title, i + 1, true
+}
+
+func skipWhitespace(s string, i int) int {
+	for i < len(s) && isWhitespace(s[i]) {
+		i++
+	}
+	return i
+}
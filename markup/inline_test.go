@@ -0,0 +1,28 @@
+package markup
+
+import "testing"
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"*em* **strong** ***both*** ~~del~~", "<em>em</em> <strong>strong</strong> <em><strong>both</strong></em> <del>del</del>"},
+		{"**a *b* c** and __d__ _e_", "<strong>a <em>b</em> c</strong> and <strong>d</strong> <em>e</em>"},
+		{"**g***h* *a **b** c* *a *b* c*", "<strong>g</strong><em>h</em> <em>a <strong>b</strong> c</em> <em>a <em>b</em> c</em>"},
+		{"*a**b**c* **a*** ~~a~~~", "<em>a<strong>b</strong>c</em> <strong>a</strong>* ~~a~~~"},
+		{"snake_case_name 2 * 3 * 4 **open", "snake_case_name 2 * 3 * 4 **open"},
+		{"`a*b*` ``x ` y``", "<code>a*b*</code> <code>x ` y</code>"},
+		{`\*not\* \[x\] C:\path`, `*not* [x] C:\path`},
+		{`[link](https://x.org "Title") [rel](</a b>)`, `<a href="https://x.org" title="Title">link</a> <a href="/a b">rel</a>`},
+		{"![alt *text*](i.png) [![t](s.png)](f.png)", `<img src="i.png" alt="alt text"> <a href="f.png"><img src="s.png" alt="t"></a>`},
+		{"[not a link] [x](", "[not a link] [x]("},
+		{"<https://x.org> <me@x.org> <u>u</u> <!-- c -->", `<a href="https://x.org">https://x.org</a> <a href="mailto:me@x.org">me@x.org</a> <u>u</u> <!-- c -->`},
+		{"a < b & c &amp; &copy; > d", "a &lt; b &amp; c &amp; &copy; &gt; d"},
+		{"hard\\\nbreak", "hard<br>\nbreak"},
+	}
+	for _, tt := range tests {
+		if got := parseInline(tt.in); got != tt.want {
+			t.Errorf("parseInline(%q)\n got: %q\nwant: %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Package markup converts page content between HTML and Markdown.
+//
+// Pages written in Bookstack's WYSIWYG editor only have HTML, while pages
+// from the Markdown editor have both. ToMarkdown and ToHTML convert between
+// the two without a request to the instance, and handle Bookstack's own
+// markup: callouts, collapsible blocks, draw.io diagrams, include tags and
+// task lists.
+//
+//	md := markup.ToMarkdown(page.HTML)
+//	html := markup.ToHTML(md)
+//
+// Markdown written by ToMarkdown survives a round trip through ToHTML
+// unchanged. HTML survives up to formatting: element IDs, link targets
+// and insignificant whitespace are dropped.
+package markup
@@ -0,0 +1,49 @@
+package markup
+
+import "testing"
+
+// page is WYSIWYG editor output using most of Bookstack's markup.
+const page = `<h1 id="bkmrk-runbook">Runbook</h1>
+<p id="bkmrk-intro">Deploys run from <a href="https://ci.example.com/deploy">CI</a> with <code>make deploy</code>.</p>
+<p class="callout info" id="bkmrk-note">Deploys are <strong>blocked</strong> on Fridays.</p>
+<details id="bkmrk-logs"><summary>Where are the logs?</summary><p>In <em>/var/log/app</em>.</p></details>
+<div drawio-diagram="41" id="bkmrk-flow"><img src="https://docs.example.com/uploads/images/drawio/2024-05/drawing-1.png"></div>
+<p id="bkmrk-include">{{@123#bkmrk-rollback}}</p>
+<ul class="contains-task-list"><li class="task-list-item"><input type="checkbox" checked="checked"> Tag release</li><li class="task-list-item"><input type="checkbox"> Announce<ul><li>#ops channel</li></ul></li></ul>
+<pre><code class="language-bash">make deploy ENV=prod</code></pre>
+<table><thead><tr><th>Env</th><th>Host</th></tr></thead><tbody><tr><td>prod</td><td>app-1</td></tr></tbody></table>`
+
+func TestRoundTrip_HTML(t *testing.T) {
+	md := ToMarkdown(page)
+	h := ToHTML(md)
+	if again := ToMarkdown(h); again != md {
+		t.Errorf("Markdown changed after a round trip:\n%s\n---\n%s", md, again)
+	}
+	if again := ToHTML(ToMarkdown(h)); again != h {
+		t.Errorf("HTML changed after a round trip:\n%s\n---\n%s", h, again)
+	}
+}
+
+func TestRoundTrip_Markdown(t *testing.T) {
+	docs := []string{
+		"# Title\n\nText with **bold**, *em*, ~~del~~, `code` and [a link](/books/a \"A\").\\\nNext line\nsoft line\n",
+		"> [!success]\n> Done.\n\n> Quote\n>\n> > Nested\n",
+		"<details open>\n<summary>Summary</summary>\n\n- one\n- two\n\n</details>\n",
+		"1. First\n   - [ ] sub task\n   - [x] done\n2. Second\n\n   With a paragraph.\n",
+		"```\nplain\n```\n\n| A | B |\n| :---: | --- |\n| 1 | 2 |\n",
+		"{{@5}}\n\n<div drawio-diagram=\"1\"><img src=\"d.png\"></div>\n\n---\n\n![img](a.png) <kbd>Ctrl</kbd> snake_case \\*x\\*\n",
+	}
+	for _, md := range docs {
+		if got := ToMarkdown(ToHTML(md)); got != md {
+			t.Errorf("round trip of %q\n got: %q", md, got)
+		}
+	}
+}
+
+func TestRoundTrip_AdjacentEmphasis(t *testing.T) {
+	in := `<p><strong>a</strong><strong>b</strong>x <b>g</b><em>h</em> <em>i</em><strong>j</strong></p>`
+	want := "<p><strong>ab</strong>x <strong>g</strong><em>h</em> <em>i</em><strong>j</strong></p>"
+	if got := ToHTML(ToMarkdown(in)); got != want {
+		t.Errorf("ToHTML(ToMarkdown(%q)) = %q, want %q", in, got, want)
+	}
+}
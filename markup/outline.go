@@ -0,0 +1,141 @@
+package markup
+
+import (
+	"iter"
+	"net/url"
+	"strings"
+)
+
+// Outline is the heading structure of a page.
+type Outline struct {
+	// Intro is the content before the first heading.
+	Intro string
+
+	// Sections holds the sections of the highest heading level present
+	// and any sections before them with a lower level.
+	Sections []*Section
+}
+
+// Section is a heading of a page and the content it introduces: the
+// elements up to the next heading of the same or a higher level.
+type Section struct {
+	Level  int    // 1 for <h1> through 6 for <h6>
+	Title  string // Text of the heading, with whitespace collapsed
+	Anchor string // ID of the heading element, e.g. "bkmrk-setup"; empty if it has none
+
+	// HTML is the heading and its content as written in the page,
+	// including subsections.
+	HTML string
+
+	// Body is HTML without the heading.
+	Body string
+
+	Children []*Section
+}
+
+// ParseOutline parses the headings of the HTML fragment s. Only headings
+// at the top level of the fragment start sections; headings nested in
+// other elements, such as callouts or collapsible blocks, are part of the
+// body of the section containing them.
+func ParseOutline(s string) *Outline {
+	o := &Outline{}
+	type open struct {
+		sec   *Section
+		start int // Offset of the heading in s
+		body  int // Offset just past the heading
+	}
+	var stack []open
+	closeTo := func(level, at int) {
+		for len(stack) > 0 && stack[len(stack)-1].sec.Level >= level {
+			top := stack[len(stack)-1]
+			top.sec.HTML = strings.TrimSpace(s[top.start:at])
+			top.sec.Body = strings.TrimSpace(s[top.body:at])
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	root := parseHTML(s)
+	first := len(s)
+	for _, n := range root.children {
+		level := headingLevel(n.tag)
+		if level == 0 {
+			continue
+		}
+		if first == len(s) {
+			first = n.start
+		}
+		closeTo(level, n.start)
+		sec := &Section{
+			Level:  level,
+			Title:  strings.Join(strings.Fields(n.textContent()), " "),
+			Anchor: n.attr("id"),
+		}
+		if len(stack) == 0 {
+			o.Sections = append(o.Sections, sec)
+		} else {
+			parent := stack[len(stack)-1].sec
+			parent.Children = append(parent.Children, sec)
+		}
+		stack = append(stack, open{sec: sec, start: n.start, body: n.start + len(n.src)})
+	}
+	closeTo(1, len(s))
+	o.Intro = strings.TrimSpace(s[:first])
+	return o
+}
+
+// headingLevel returns the level of a heading element name, or 0 if tag
+// is not a heading.
+func headingLevel(tag string) int {
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
+
+// All returns an iterator over all sections of the outline in document
+// order, parents before their children.
+func (o *Outline) All() iter.Seq[*Section] {
+	return func(yield func(*Section) bool) {
+		var walk func([]*Section) bool
+		walk = func(secs []*Section) bool {
+			for _, sec := range secs {
+				if !yield(sec) || !walk(sec.Children) {
+					return false
+				}
+			}
+			return true
+		}
+		walk(o.Sections)
+	}
+}
+
+// Find returns the first section whose anchor is ref, with or without a
+// leading "#", or failing that the first section whose title equals ref,
+// ignoring case and surrounding whitespace. It returns nil if there is
+// no such section.
+func (o *Outline) Find(ref string) *Section {
+	anchor := strings.TrimPrefix(ref, "#")
+	for sec := range o.All() {
+		if anchor != "" && sec.Anchor == anchor {
+			return sec
+		}
+	}
+	title := strings.Join(strings.Fields(ref), " ")
+	for sec := range o.All() {
+		if title != "" && strings.EqualFold(sec.Title, title) {
+			return sec
+		}
+	}
+	return nil
+}
+
+// Link returns a deep link to the section, built by replacing the
+// fragment of pageURL with the section's anchor. It returns pageURL
+// unchanged if the heading has no anchor.
+func (s *Section) Link(pageURL string) string {
+	if s.Anchor == "" {
+		return pageURL
+	}
+	base, _, _ := strings.Cut(pageURL, "#")
+	return base + "#" + url.PathEscape(s.Anchor)
+}
@@ -0,0 +1,98 @@
+package markup
+
+import (
+	"slices"
+	"testing"
+)
+
+const outlinePage = `<p id="bkmrk-intro">Intro.</p>
+<h2 id="bkmrk-install">Install</h2>
+<p id="bkmrk-run">Run it.</p>
+<h3 id="bkmrk-linux">On  <em>Linux</em></h3>
+<pre><code>make</code></pre>
+<h4 id="bkmrk-deep">Deep</h4>
+<h3 id="bkmrk-mac">Mac</h3>
+<details><summary>More</summary><h3>Nested</h3></details>
+<h2 id="bkmrk-usage">Usage</h2>
+<h1>Top</h1>
+<p>End`
+
+func TestParseOutline(t *testing.T) {
+	o := ParseOutline(outlinePage)
+	if o.Intro != `<p id="bkmrk-intro">Intro.</p>` {
+		t.Errorf("Intro = %q", o.Intro)
+	}
+
+	var got []string
+	for sec := range o.All() {
+		got = append(got, string(rune('0'+sec.Level))+" "+sec.Anchor+" "+sec.Title)
+	}
+	want := []string{
+		"2 bkmrk-install Install",
+		"3 bkmrk-linux On Linux",
+		"4 bkmrk-deep Deep",
+		"3 bkmrk-mac Mac",
+		"2 bkmrk-usage Usage",
+		"1  Top",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("sections =\n%q\nwant\n%q", got, want)
+	}
+	if len(o.Sections) != 3 || len(o.Sections[0].Children) != 2 || len(o.Sections[0].Children[0].Children) != 1 {
+		t.Errorf("unexpected tree shape: %+v", o.Sections)
+	}
+
+	linux := o.Sections[0].Children[0]
+	if linux.HTML != "<h3 id=\"bkmrk-linux\">On  <em>Linux</em></h3>\n<pre><code>make</code></pre>\n<h4 id=\"bkmrk-deep\">Deep</h4>" {
+		t.Errorf("HTML = %q", linux.HTML)
+	}
+	if linux.Body != "<pre><code>make</code></pre>\n<h4 id=\"bkmrk-deep\">Deep</h4>" {
+		t.Errorf("Body = %q", linux.Body)
+	}
+	if mac := o.Sections[0].Children[1]; mac.Body != "<details><summary>More</summary><h3>Nested</h3></details>" {
+		t.Errorf("nested heading body = %q", mac.Body)
+	}
+	if top := o.Sections[2]; top.Body != "<p>End" {
+		t.Errorf("last body = %q", top.Body)
+	}
+}
+
+func TestParseOutline_NoHeadings(t *testing.T) {
+	o := ParseOutline("<p>Just text.</p>\n")
+	if o.Intro != "<p>Just text.</p>" || len(o.Sections) != 0 {
+		t.Errorf("outline = %+v", o)
+	}
+}
+
+func TestOutline_Find(t *testing.T) {
+	o := ParseOutline(outlinePage)
+	tests := []struct {
+		ref, want string
+	}{
+		{"bkmrk-mac", "Mac"},
+		{"#bkmrk-deep", "Deep"},
+		{"on linux", "On Linux"},
+		{"  USAGE ", "Usage"},
+		{"Nested", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if sec := o.Find(tt.ref); sec != nil {
+			got = sec.Title
+		}
+		if got != tt.want {
+			t.Errorf("Find(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestSection_Link(t *testing.T) {
+	sec := &Section{Anchor: "bkmrk-set-up"}
+	if got := sec.Link("https://docs.example.com/books/ops/page/deploy#old"); got != "https://docs.example.com/books/ops/page/deploy#bkmrk-set-up" {
+		t.Errorf("Link = %q", got)
+	}
+	if got := (&Section{}).Link("https://docs.example.com/link/5"); got != "https://docs.example.com/link/5" {
+		t.Errorf("Link without anchor = %q", got)
+	}
+}
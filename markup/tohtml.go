@@ -0,0 +1,494 @@
+package markup
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ToHTML converts Markdown to HTML, understanding the Bookstack markup that
+// ToMarkdown writes as well as CommonMark and the GitHub extensions for
+// tables, task lists and strikethrough.
+//
+// Blockquotes starting with [!info], [!success], [!warning] or [!danger]
+// become Bookstack callouts. HTML blocks, such as draw.io diagrams, are
+// copied unchanged; a <details> block may contain Markdown.
+func ToHTML(markdown string) string {
+	lines := strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(markdown), "\n")
+	expandTabs(lines)
+	return strings.Join(parseBlocks(lines, false), "\n")
+}
+
+// expandTabs replaces tabs in the indentation of lines outside fenced code
+// blocks with four spaces.
+func expandTabs(lines []string) {
+	var fence string
+	for i, l := range lines {
+		if fence != "" {
+			if t := strings.TrimSpace(l); strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if m := fenceOpen.FindStringSubmatch(l); m != nil {
+			fence = m[2]
+		}
+		indent := len(l) - len(strings.TrimLeft(l, " \t"))
+		lines[i] = strings.ReplaceAll(l[:indent], "\t", "    ") + l[indent:]
+	}
+}
+
+var (
+	atxHeading      = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	thematic        = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fenceOpen       = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`\\s]*)[^`]*$")
+	listItem        = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])( +|$)`)
+	htmlBlock       = regexp.MustCompile(`^ {0,3}(?:<!--|</?([a-zA-Z][a-zA-Z0-9-]*)(?:[\s/>]|$))`)
+	calloutMark     = regexp.MustCompile(`^\[!(info|success|warning|danger)\][ \t]*$`)
+	taskMarker      = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+|$)`)
+	tableDelimRe    = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	detailsOpen     = regexp.MustCompile(`^ {0,3}<details(\s+open(="[^"]*")?)?\s*>\s*$`)
+	setextUnderline = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	summaryLine     = regexp.MustCompile(`^ {0,3}<summary>(.*)</summary>\s*$`)
+)
+
+// htmlBlockTags are the tags that start an HTML block, as in CommonMark.
+var htmlBlockTags = set("address", "article", "aside", "audio", "blockquote", "canvas", "caption", "center",
+	"col", "colgroup", "dd", "details", "dialog", "dir", "div", "dl", "dt", "embed", "fieldset",
+	"figcaption", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr",
+	"iframe", "li", "main", "math", "menu", "nav", "object", "ol", "p", "pre", "script", "section",
+	"style", "summary", "svg", "table", "tbody", "td", "tfoot", "th", "thead", "tr", "ul", "video")
+
+func isBlank(line string) bool { return strings.TrimSpace(line) == "" }
+
+func indentOf(line string) int { return len(line) - len(strings.TrimLeft(line, " ")) }
+
+// parseBlocks converts lines to HTML blocks. In tight lists, paragraphs
+// are written without <p> tags.
+func parseBlocks(lines []string, tight bool) []string {
+	var out []string
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case isBlank(line):
+			i++
+		case fenceOpen.MatchString(line):
+			var block string
+			block, i = codeFence(lines, i)
+			out = append(out, block)
+		case atxHeading.MatchString(line):
+			m := atxHeading.FindStringSubmatch(line)
+			level := len(m[1])
+			out = append(out, fmt.Sprintf("<h%d>%s</h%d>", level, parseInline(strings.TrimSpace(m[2])), level))
+			i++
+		case thematic.MatchString(line):
+			out = append(out, "<hr>")
+			i++
+		case strings.HasPrefix(strings.TrimLeft(line, " "), ">") && indentOf(line) < 4:
+			var block string
+			block, i = blockquote(lines, i)
+			out = append(out, block)
+		case listItem.MatchString(line):
+			var block string
+			block, i = list(lines, i)
+			out = append(out, block)
+		case detailsOpen.MatchString(line):
+			var block string
+			block, i = details(lines, i)
+			out = append(out, block)
+		case startsHTMLBlock(line):
+			start := i
+			for i < len(lines) && !isBlank(lines[i]) {
+				i++
+			}
+			out = append(out, strings.Join(lines[start:i], "\n"))
+		case indentOf(line) >= 4:
+			start := i
+			for i < len(lines) && (indentOf(lines[i]) >= 4 || isBlank(lines[i])) {
+				i++
+			}
+			for i > start && isBlank(lines[i-1]) {
+				i--
+			}
+			code := make([]string, i-start)
+			for k, l := range lines[start:i] {
+				if len(l) >= 4 {
+					code[k] = l[4:]
+				}
+			}
+			out = append(out, "<pre><code>"+escapeHTML(strings.Join(code, "\n"))+"\n</code></pre>")
+		case i+1 < len(lines) && isTableStart(lines[i], lines[i+1]):
+			var block string
+			block, i = table(lines, i)
+			out = append(out, block)
+		default:
+			start := i
+			level := 0
+			for i++; i < len(lines) && !isBlank(lines[i]); i++ {
+				if m := setextUnderline.FindStringSubmatch(lines[i]); m != nil {
+					level = 1
+					if m[1][0] == '-' {
+						level = 2
+					}
+					break
+				}
+				if interruptsParagraph(lines[i]) {
+					break
+				}
+			}
+			para := make([]string, i-start)
+			for k, l := range lines[start:i] {
+				para[k] = strings.TrimLeft(l, " ")
+			}
+			text := parseInline(strings.TrimRight(strings.Join(para, "\n"), " "))
+			switch {
+			case level > 0:
+				out = append(out, fmt.Sprintf("<h%d>%s</h%d>", level, text, level))
+				i++
+			case tight:
+				out = append(out, text)
+			default:
+				out = append(out, "<p>"+text+"</p>")
+			}
+		}
+	}
+	return out
+}
+
+func startsHTMLBlock(line string) bool {
+	m := htmlBlock.FindStringSubmatch(line)
+	return m != nil && (m[1] == "" || htmlBlockTags[strings.ToLower(m[1])])
+}
+
+// interruptsParagraph reports whether line starts a block that may follow
+// a paragraph line directly.
+func interruptsParagraph(line string) bool {
+	if atxHeading.MatchString(line) || thematic.MatchString(line) || fenceOpen.MatchString(line) ||
+		startsHTMLBlock(line) || strings.HasPrefix(strings.TrimLeft(line, " "), ">") && indentOf(line) < 4 {
+		return true
+	}
+	// Only non-empty bullet items and ordered items starting at 1 do.
+	m := listItem.FindStringSubmatch(line)
+	if m == nil || isBlank(line[len(m[0]):]) {
+		return false
+	}
+	return !isDigit(m[2][0]) || strings.TrimLeft(m[2][:len(m[2])-1], "0") == "1"
+}
+
+func codeFence(lines []string, i int) (string, int) {
+	m := fenceOpen.FindStringSubmatch(lines[i])
+	indent, fence, lang := len(m[1]), m[2], m[3]
+	var code []string
+	i++
+	for ; i < len(lines); i++ {
+		l := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(l, fence) && strings.Trim(l, fence[:1]) == "" && indentOf(lines[i]) < 4 {
+			i++
+			break
+		}
+		l = lines[i]
+		for k := 0; k < indent && strings.HasPrefix(l, " "); k++ {
+			l = l[1:]
+		}
+		code = append(code, l)
+	}
+	class := ""
+	if lang != "" {
+		class = ` class="language-` + escapeAttr(unescapeMarkdown(lang)) + `"`
+	}
+	text := escapeHTML(strings.Join(code, "\n"))
+	if len(code) > 0 {
+		text += "\n"
+	}
+	return "<pre><code" + class + ">" + text + "</code></pre>", i
+}
+
+func blockquote(lines []string, i int) (string, int) {
+	var inner []string
+	for ; i < len(lines); i++ {
+		l := strings.TrimLeft(lines[i], " ")
+		if !strings.HasPrefix(l, ">") || indentOf(lines[i]) >= 4 {
+			// A paragraph continues lazily on lines without the marker.
+			if isBlank(lines[i]) || len(inner) == 0 || isBlank(inner[len(inner)-1]) || interruptsParagraph(lines[i]) || listItem.MatchString(lines[i]) {
+				break
+			}
+			inner = append(inner, lines[i])
+			continue
+		}
+		l = strings.TrimPrefix(l[1:], " ")
+		inner = append(inner, l)
+	}
+	if len(inner) > 0 {
+		if m := calloutMark.FindStringSubmatch(strings.TrimSpace(inner[0])); m != nil {
+			var text []string
+			for _, l := range inner[1:] {
+				text = append(text, strings.TrimLeft(l, " "))
+			}
+			return `<p class="callout ` + m[1] + `">` + parseInline(strings.TrimSpace(strings.Join(text, "\n"))) + "</p>", i
+		}
+	}
+	return "<blockquote>\n" + strings.Join(parseBlocks(inner, false), "\n") + "\n</blockquote>", i
+}
+
+// listItemMarker describes the marker of a list item.
+type listItemMarker struct {
+	bullet  byte // '-', '+', '*', or '.' or ')' for ordered lists
+	start   int
+	indent  int // Indentation of the item's content
+	content string
+}
+
+func parseListItem(line string) (listItemMarker, bool) {
+	m := listItem.FindStringSubmatch(line)
+	if m == nil {
+		return listItemMarker{}, false
+	}
+	lm := listItemMarker{bullet: m[2][len(m[2])-1]}
+	if isDigit(m[2][0]) {
+		lm.start, _ = strconv.Atoi(m[2][:len(m[2])-1])
+	}
+	spaces := len(m[3])
+	if spaces > 4 || spaces == 0 {
+		spaces = 1
+	}
+	lm.indent = len(m[1]) + len(m[2]) + spaces
+	if len(line) > lm.indent {
+		lm.content = line[lm.indent:]
+	}
+	return lm, true
+}
+
+func list(lines []string, i int) (string, int) {
+	first, _ := parseListItem(lines[i])
+	type item struct {
+		lines []string
+	}
+	var items []item
+	loose := false
+	for i < len(lines) {
+		lm, ok := parseListItem(lines[i])
+		if !ok || lm.bullet != first.bullet || thematic.MatchString(lines[i]) {
+			break
+		}
+		it := item{lines: []string{lm.content}}
+		i++
+		for i < len(lines) {
+			l := lines[i]
+			if isBlank(l) {
+				// The item continues if the next non-blank line is indented.
+				j := i
+				for j < len(lines) && isBlank(lines[j]) {
+					j++
+				}
+				if j < len(lines) && indentOf(lines[j]) >= lm.indent {
+					for ; i < j; i++ {
+						it.lines = append(it.lines, "")
+					}
+					continue
+				}
+				break
+			}
+			if indentOf(l) >= lm.indent {
+				it.lines = append(it.lines, l[lm.indent:])
+				i++
+				continue
+			}
+			// Lazy continuation of a paragraph.
+			if last := it.lines[len(it.lines)-1]; !isBlank(last) && !interruptsParagraph(l) && !listItem.MatchString(l) {
+				it.lines = append(it.lines, l)
+				i++
+				continue
+			}
+			break
+		}
+		for k := 0; k+1 < len(it.lines); k++ {
+			if isBlank(it.lines[k]) && k > 0 {
+				loose = true
+			}
+		}
+		items = append(items, it)
+		// A blank line between items makes the list loose.
+		if i < len(lines) && isBlank(lines[i]) {
+			j := i
+			for j < len(lines) && isBlank(lines[j]) {
+				j++
+			}
+			if next, ok := parseListItem(safeLine(lines, j)); ok && next.bullet == first.bullet && !thematic.MatchString(lines[j]) {
+				loose = true
+				i = j
+			}
+		}
+	}
+
+	tag, open := "ul", "<ul>"
+	if first.bullet == '.' || first.bullet == ')' {
+		tag, open = "ol", "<ol>"
+		if first.start != 1 {
+			open = fmt.Sprintf(`<ol start="%d">`, first.start)
+		}
+	}
+	var b strings.Builder
+	hasTasks := false
+	for _, it := range items {
+		attrs, task := "", ""
+		if m := taskMarker.FindStringSubmatch(it.lines[0]); m != nil {
+			hasTasks = true
+			it.lines[0] = it.lines[0][len(m[0]):]
+			attrs = ` class="task-list-item"`
+			task = `<input type="checkbox" disabled="disabled"> `
+			if m[1] != " " {
+				task = `<input type="checkbox" disabled="disabled" checked="checked"> `
+			}
+		}
+		content := strings.Join(parseBlocks(it.lines, !loose), "\n")
+		if rest, ok := strings.CutPrefix(content, "<p>"); ok && task != "" {
+			content = "<p>" + task + rest
+		} else {
+			content = task + content
+		}
+		b.WriteString("\n<li" + attrs + ">" + content + "</li>")
+	}
+	if hasTasks && tag == "ul" {
+		open = `<ul class="contains-task-list">`
+	}
+	return open + b.String() + "\n</" + tag + ">", i
+}
+
+func safeLine(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+func details(lines []string, i int) (string, int) {
+	open := "<details>"
+	if m := detailsOpen.FindStringSubmatch(lines[i]); m[1] != "" {
+		open = "<details open>"
+	}
+	i++
+	depth := 1
+	start := i
+	for ; i < len(lines); i++ {
+		switch t := strings.TrimSpace(lines[i]); {
+		case detailsOpen.MatchString(lines[i]):
+			depth++
+		case t == "</details>":
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	inner := lines[start:min(i, len(lines))]
+	i++
+
+	var summary string
+	for k, l := range inner {
+		if isBlank(l) {
+			continue
+		}
+		if m := summaryLine.FindStringSubmatch(l); m != nil {
+			summary = "<summary>" + parseInline(strings.TrimSpace(m[1])) + "</summary>"
+			inner = inner[k+1:]
+		}
+		break
+	}
+	parts := []string{open + summary}
+	parts = append(parts, parseBlocks(inner, false)...)
+	return strings.Join(append(parts, "</details>"), "\n"), i
+}
+
+func isTableStart(header, delim string) bool {
+	if !strings.Contains(header, "|") || !tableDelimRe.MatchString(delim) || !strings.Contains(delim, "-") {
+		return false
+	}
+	return len(splitRow(header)) == len(splitRow(delim))
+}
+
+// splitRow splits a table row into cells on unescaped pipes.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			b.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(b.String()))
+			b.Reset()
+		default:
+			b.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(b.String()))
+}
+
+func table(lines []string, i int) (string, int) {
+	header := splitRow(lines[i])
+	var aligns []string
+	for _, d := range splitRow(lines[i+1]) {
+		switch {
+		case strings.HasPrefix(d, ":") && strings.HasSuffix(d, ":"):
+			aligns = append(aligns, ` style="text-align: center"`)
+		case strings.HasSuffix(d, ":"):
+			aligns = append(aligns, ` style="text-align: right"`)
+		case strings.HasPrefix(d, ":"):
+			aligns = append(aligns, ` style="text-align: left"`)
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+	row := func(cells []string, tag string) string {
+		var b strings.Builder
+		b.WriteString("<tr>")
+		for k := range header {
+			var cell string
+			if k < len(cells) {
+				cell = cells[k]
+			}
+			fmt.Fprintf(&b, "<%s%s>%s</%s>", tag, aligns[k], parseInline(cell), tag)
+		}
+		b.WriteString("</tr>")
+		return b.String()
+	}
+	var b strings.Builder
+	b.WriteString("<table>\n<thead>\n" + row(header, "th") + "\n</thead>\n<tbody>")
+	for i += 2; i < len(lines) && !isBlank(lines[i]) && !interruptsParagraph(lines[i]); i++ {
+		b.WriteString("\n" + row(splitRow(lines[i]), "td"))
+	}
+	b.WriteString("\n</tbody>\n</table>")
+	return b.String(), i
+}
+
+var (
+	htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+func escapeHTML(s string) string { return htmlEscaper.Replace(s) }
+func escapeAttr(s string) string { return attrEscaper.Replace(s) }
+
+// unescapeMarkdown removes backslash escapes and decodes entities.
+func unescapeMarkdown(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return html.UnescapeString(b.String())
+}
+
+func isPunct(c byte) bool {
+	return c >= '!' && c <= '/' || c >= ':' && c <= '@' || c >= '[' && c <= '`' || c >= '{' && c <= '~'
+}
@@ -0,0 +1,62 @@
+package markup
+
+import "testing"
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"headings",
+			"# One\n\nTwo\n---\n\n### Three ###",
+			"<h1>One</h1>\n<h2>Two</h2>\n<h3>Three</h3>"},
+		{"paragraphs",
+			"First line\nsecond line\n\nNext  \nbreak",
+			"<p>First line\nsecond line</p>\n<p>Next<br>\nbreak</p>"},
+		{"callout",
+			"> [!danger]\n> Do **not** delete.",
+			`<p class="callout danger">Do <strong>not</strong> delete.</p>`},
+		{"blockquote",
+			"> Quote\n>\n> - item",
+			"<blockquote>\n<p>Quote</p>\n<ul>\n<li>item</li>\n</ul>\n</blockquote>"},
+		{"details",
+			"<details>\n<summary>More</summary>\n\nHidden *text*\n\n</details>",
+			"<details><summary>More</summary>\n<p>Hidden <em>text</em></p>\n</details>"},
+		{"html block",
+			"<div drawio-diagram=\"3\"><img src=\"d.png\"></div>\n\nAfter",
+			"<div drawio-diagram=\"3\"><img src=\"d.png\"></div>\n<p>After</p>"},
+		{"task list",
+			"- [ ] Open\n- [x] Done",
+			"<ul class=\"contains-task-list\">\n<li class=\"task-list-item\"><input type=\"checkbox\" disabled=\"disabled\"> Open</li>\n<li class=\"task-list-item\"><input type=\"checkbox\" disabled=\"disabled\" checked=\"checked\"> Done</li>\n</ul>"},
+		{"loose list",
+			"1. One\n\n2. Two",
+			"<ol>\n<li><p>One</p></li>\n<li><p>Two</p></li>\n</ol>"},
+		{"ordered start",
+			"3) Three\n4) Four",
+			"<ol start=\"3\">\n<li>Three</li>\n<li>Four</li>\n</ol>"},
+		{"fenced code",
+			"```go\nif a < b {\n\treturn\n}\n```",
+			"<pre><code class=\"language-go\">if a &lt; b {\n\treturn\n}\n</code></pre>"},
+		{"indented code",
+			"    x := 1",
+			"<pre><code>x := 1\n</code></pre>"},
+		{"table",
+			"| A | B |\n|:--|:-:|\n| 1 | `x\\|y` |",
+			"<table>\n<thead>\n<tr><th style=\"text-align: left\">A</th><th style=\"text-align: center\">B</th></tr>\n</thead>\n<tbody>\n<tr><td style=\"text-align: left\">1</td><td style=\"text-align: center\"><code>x|y</code></td></tr>\n</tbody>\n</table>"},
+		{"thematic break",
+			"a\n\n* * *",
+			"<p>a</p>\n<hr>"},
+		{"include tag",
+			"{{@12#bkmrk-setup}}",
+			"<p>{{@12#bkmrk-setup}}</p>"},
+		{"crlf and tabs",
+			"- a\r\n\t- b\r\n",
+			"<ul>\n<li>a\n<ul>\n<li>b</li>\n</ul></li>\n</ul>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToHTML(tt.in); got != tt.want {
+				t.Errorf("ToHTML(%q)\n got: %q\nwant: %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
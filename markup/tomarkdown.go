@@ -0,0 +1,600 @@
+package markup
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// calloutTypes are the callout classes of Bookstack's editors.
+var calloutTypes = []string{"info", "success", "warning", "danger"}
+
+// rawBlocks are elements kept as HTML in Markdown, since Markdown has no
+// equivalent for them.
+var rawBlocks = set("audio", "canvas", "dl", "embed", "form", "iframe", "math", "object", "script", "style", "svg", "video")
+
+// transparentBlocks are containers whose content is converted as if they
+// were not there.
+var transparentBlocks = set("#root", "article", "aside", "div", "figure", "footer", "header", "main", "nav", "section", "tbody", "thead")
+
+// blockElements start a new Markdown block.
+var blockElements = set("blockquote", "details", "h1", "h2", "h3", "h4", "h5", "h6", "hr", "ol", "p", "pre", "table", "ul", "!")
+
+// inlineHTML are inline elements without a Markdown equivalent, kept as
+// HTML tags around their converted content.
+var inlineHTML = set("abbr", "kbd", "mark", "small", "sub", "sup", "u")
+
+// ToMarkdown converts HTML, such as Page.HTML from the WYSIWYG editor, to
+// Markdown.
+//
+// Bookstack markup is converted as follows:
+//
+//   - Callouts (<p class="callout info">) become blockquotes starting with
+//     [!info], [!success], [!warning] or [!danger].
+//   - Collapsible blocks (<details>) keep their <details> and <summary>
+//     tags around Markdown content.
+//   - Draw.io diagrams, embedded media and other markup without a Markdown
+//     equivalent are kept as HTML blocks.
+//   - Include tags such as {{@123#bkmrk-intro}} are kept as they are.
+//   - Task list items become "- [ ]" and "- [x]" items.
+//
+// Element IDs, such as Bookstack's bkmrk- anchors, are dropped; Bookstack
+// adds new ones when the page is saved.
+func ToMarkdown(html string) string {
+	c := &mdWriter{}
+	return strings.Join(c.blocks(parseHTML(html)), "\n\n") + "\n"
+}
+
+type mdWriter struct {
+	inTable bool
+}
+
+// blocks converts the children of n into Markdown blocks.
+func (w *mdWriter) blocks(n *node) []string {
+	var out []string
+	var inline []*node
+	flush := func() {
+		if p := w.paragraph(inline); p != "" {
+			out = append(out, p)
+		}
+		inline = nil
+	}
+	for _, c := range n.children {
+		switch {
+		case c.tag == "!":
+			flush()
+			out = append(out, c.src)
+		case c.tag == "" || !w.isBlock(c):
+			inline = append(inline, c)
+		default:
+			flush()
+			out = append(out, w.block(c)...)
+		}
+	}
+	flush()
+	return out
+}
+
+func (w *mdWriter) isBlock(n *node) bool {
+	return blockElements[n.tag] || rawBlocks[n.tag] || transparentBlocks[n.tag] || n.tag == "li"
+}
+
+// block converts a block element.
+func (w *mdWriter) block(n *node) []string {
+	switch n.tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := singleLine(w.inlineText(n.children))
+		if text == "" {
+			return nil
+		}
+		return []string{strings.Repeat("#", int(n.tag[1]-'0')) + " " + text}
+	case "p":
+		for _, t := range calloutTypes {
+			if n.hasClass("callout") && n.hasClass(t) {
+				return []string{quote("[!" + t + "]\n" + w.inlineText(n.children))}
+			}
+		}
+		if p := w.paragraph(n.children); p != "" {
+			return []string{p}
+		}
+		return nil
+	case "blockquote":
+		if inner := w.blocks(n); len(inner) > 0 {
+			return []string{quote(strings.Join(inner, "\n\n"))}
+		}
+		return nil
+	case "ul", "ol":
+		return []string{w.list(n)}
+	case "li":
+		return []string{w.list(&node{tag: "ul", children: []*node{n}})}
+	case "pre":
+		return []string{codeBlock(n)}
+	case "hr":
+		return []string{"---"}
+	case "table":
+		if t, ok := w.table(n); ok {
+			return []string{t}
+		}
+		return []string{rawHTML(n)}
+	case "details":
+		return []string{w.details(n)}
+	case "div":
+		if n.hasAttr("drawio-diagram") {
+			return []string{rawHTML(n)}
+		}
+	}
+	if rawBlocks[n.tag] {
+		return []string{rawHTML(n)}
+	}
+	return w.blocks(n)
+}
+
+// singleLine joins the lines of inline Markdown, dropping hard breaks.
+func singleLine(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\\\n", " "), "\n", " ")
+}
+
+// rawHTML returns the source of n for use as a Markdown HTML block, which
+// ends at the first blank line.
+func rawHTML(n *node) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(n.src), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// quote prefixes each line of s with "> ".
+func quote(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (w *mdWriter) details(n *node) string {
+	open := "<details>"
+	if n.hasAttr("open") {
+		open = "<details open>"
+	}
+	var summary string
+	rest := &node{tag: "div"}
+	for _, c := range n.children {
+		if c.tag == "summary" && summary == "" {
+			summary = singleLine(w.inlineText(c.children))
+			continue
+		}
+		rest.children = append(rest.children, c)
+	}
+	parts := []string{open + "\n<summary>" + summary + "</summary>"}
+	parts = append(parts, w.blocks(rest)...)
+	return strings.Join(append(parts, "</details>"), "\n\n")
+}
+
+func (w *mdWriter) list(n *node) string {
+	num := 1
+	if v, err := strconv.Atoi(n.attr("start")); err == nil {
+		num = v
+	}
+	var items []string
+	for _, li := range n.children {
+		if li.tag != "li" {
+			continue
+		}
+		marker := "- "
+		if n.tag == "ol" {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+		var task string
+		content := &node{tag: "li"}
+		for _, c := range li.children {
+			if c.tag == "input" && c.attr("type") == "checkbox" && task == "" {
+				task = "[ ] "
+				if c.hasAttr("checked") {
+					task = "[x] "
+				}
+				continue
+			}
+			content.children = append(content.children, c)
+		}
+		if task == "" && li.hasClass("task-list-item") {
+			task = "[ ] "
+		}
+
+		// Paragraphs inside items are written as plain text, and a nested
+		// list directly follows the text it belongs to.
+		var b strings.Builder
+		blocks := w.itemBlocks(content)
+		for i, blk := range blocks {
+			if i > 0 {
+				if isList(blk) {
+					b.WriteString("\n")
+				} else {
+					b.WriteString("\n\n")
+				}
+			}
+			b.WriteString(blk)
+		}
+		indent := strings.Repeat(" ", len(marker))
+		lines := strings.Split(b.String(), "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = indent + lines[i]
+			}
+		}
+		items = append(items, marker+task+strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
+
+// itemBlocks converts the content of a list item, unwrapping paragraphs.
+func (w *mdWriter) itemBlocks(li *node) []string {
+	var flat []*node
+	for _, c := range li.children {
+		if c.tag == "p" && !c.hasClass("callout") {
+			flat = append(flat, &node{tag: "div", children: c.children})
+			continue
+		}
+		flat = append(flat, c)
+	}
+	return w.blocks(&node{tag: "li", children: flat})
+}
+
+var listMarker = regexp.MustCompile(`^(- |\d+\. )`)
+
+func isList(block string) bool { return listMarker.MatchString(block) }
+
+func codeBlock(n *node) string {
+	code := n
+	if len(n.children) == 1 && n.children[0].tag == "code" {
+		code = n.children[0]
+	}
+	var lang string
+	for _, class := range strings.Fields(code.attr("class")) {
+		if l, ok := strings.CutPrefix(class, "language-"); ok {
+			lang = l
+		}
+	}
+	text := strings.TrimSuffix(code.textContent(), "\n")
+	text = strings.TrimPrefix(text, "\n")
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + text + "\n" + fence
+}
+
+// table converts a table with a header row and inline cells to a GFM
+// table. It reports false for tables Markdown cannot express.
+func (w *mdWriter) table(n *node) (string, bool) {
+	var rows [][]*node
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, c := range n.children {
+			switch c.tag {
+			case "thead", "tbody", "tfoot":
+				walk(c)
+			case "tr":
+				var cells []*node
+				for _, cell := range c.children {
+					if cell.tag == "td" || cell.tag == "th" {
+						cells = append(cells, cell)
+					}
+				}
+				rows = append(rows, cells)
+			case "", "!", "colgroup", "caption":
+				if c.tag == "" && strings.TrimSpace(c.text) != "" || c.tag == "caption" {
+					rows = nil
+					return
+				}
+			}
+		}
+	}
+	walk(n)
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return "", false
+	}
+	for _, cell := range rows[0] {
+		if cell.tag != "th" {
+			return "", false
+		}
+	}
+	cols := len(rows[0])
+	for _, row := range rows {
+		if len(row) != cols {
+			return "", false
+		}
+		for _, cell := range row {
+			if cell.hasAttr("colspan") || cell.hasAttr("rowspan") {
+				return "", false
+			}
+			for _, c := range cell.children {
+				if c.tag != "" && w.isBlock(c) && c.tag != "p" {
+					return "", false
+				}
+			}
+		}
+	}
+
+	w.inTable = true
+	defer func() { w.inTable = false }()
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		cells := make([]string, cols)
+		for j, cell := range row {
+			var content []*node
+			for _, c := range cell.children {
+				if c.tag == "p" {
+					content = append(content, c.children...)
+				} else {
+					content = append(content, c)
+				}
+			}
+			cells[j] = strings.ReplaceAll(w.inlineText(content), "|", `\|`)
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			delims := make([]string, cols)
+			for j, cell := range row {
+				delims[j] = alignDelimiter(cell)
+			}
+			lines = append(lines, "| "+strings.Join(delims, " | ")+" |")
+		}
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+var textAlign = regexp.MustCompile(`text-align:\s*(left|center|right)`)
+
+func alignDelimiter(cell *node) string {
+	align := cell.attr("align")
+	if m := textAlign.FindStringSubmatch(cell.attr("style")); m != nil {
+		align = m[1]
+	}
+	switch align {
+	case "left":
+		return ":---"
+	case "center":
+		return ":---:"
+	case "right":
+		return "---:"
+	}
+	return "---"
+}
+
+// paragraph converts inline nodes to a paragraph, escaping text that would
+// otherwise start a block.
+func (w *mdWriter) paragraph(nodes []*node) string {
+	text := w.inlineText(nodes)
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if loc := blockStart.FindStringIndex(l); loc != nil {
+			// Escape the last character of the marker, e.g. "1\." or "\#".
+			k := loc[1] - 1
+			for k > 0 && l[k] == ' ' {
+				k--
+			}
+			if isDigit(l[0]) {
+				lines[i] = l[:k] + `\` + l[k:]
+			} else {
+				lines[i] = `\` + l
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// blockStart matches text at the start of a line that Markdown would read
+// as a block marker.
+var blockStart = regexp.MustCompile(`^(#{1,6}( |$)|[-+] |>|\d{1,9}[.)]( |$)|-{3,}\s*$|={3,}\s*$)`)
+
+// inlineText converts inline nodes to Markdown, collapsing whitespace.
+func (w *mdWriter) inlineText(nodes []*node) string {
+	var b strings.Builder
+	for _, n := range mergeRuns(nodes) {
+		w.inline(&b, n)
+	}
+	lines := strings.Split(b.String(), "\n")
+	out := lines[:0]
+	for _, l := range lines {
+		// Blank lines would end the paragraph.
+		if l = strings.Join(strings.FieldsFunc(l, func(r rune) bool { return r == ' ' }), " "); l != "" {
+			out = append(out, l)
+		}
+	}
+	text := strings.Join(out, "\n")
+	// Hard breaks at the start or end of a paragraph have no effect.
+	text = strings.Trim(text, "\n")
+	for strings.HasSuffix(text, `\`) && !strings.HasSuffix(text, `\\`) {
+		text = strings.TrimSpace(strings.TrimSuffix(text, `\`))
+	}
+	return text
+}
+
+var (
+	whitespace = regexp.MustCompile(`\s+`)
+	entityLike = regexp.MustCompile(`&(#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+)
+
+// escapeText escapes Markdown syntax in text. Underscores inside words
+// cannot start emphasis and are left alone.
+func escapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', '`', '*', '[', ']', '<', '~':
+			b.WriteByte('\\')
+		case '_':
+			if i == 0 || i == len(s)-1 || !isAlnum(s[i-1]) || !isAlnum(s[i+1]) {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return entityLike.ReplaceAllString(b.String(), `\$0`)
+}
+
+func (w *mdWriter) inline(b *strings.Builder, n *node) {
+	switch n.tag {
+	case "":
+		// Line breaks are kept, as Markdown reads them as spaces.
+		text := whitespace.ReplaceAllStringFunc(n.text, func(ws string) string {
+			if strings.Contains(ws, "\n") && !w.inTable {
+				return "\n"
+			}
+			return " "
+		})
+		b.WriteString(escapeText(text))
+		return
+	case "!", "input", "script", "style":
+		return
+	case "br":
+		if w.inTable {
+			b.WriteString("<br>")
+		} else {
+			b.WriteString("\\\n")
+		}
+		return
+	case "strong", "b", "em", "i", "cite", "s", "del", "strike":
+		b.WriteString(wrap(emphasisDelims[n.tag], w.inlineRaw(n)))
+		return
+	case "code":
+		b.WriteString(codeSpan(whitespace.ReplaceAllString(n.textContent(), " ")))
+		return
+	case "a":
+		href, ok := n.attrs["href"]
+		text := w.inlineRaw(n)
+		if !ok {
+			b.WriteString(text)
+			return
+		}
+		if autolinkable(n, href) {
+			b.WriteString("<" + n.textContent() + ">")
+			return
+		}
+		b.WriteString("[" + strings.TrimSpace(text) + "](" + linkDestination(href, n.attr("title")) + ")")
+		return
+	case "img":
+		if hasOnlyAttrs(n, "src", "alt", "title") {
+			b.WriteString("![" + escapeText(n.attr("alt")) + "](" + linkDestination(n.attr("src"), n.attr("title")) + ")")
+		} else {
+			b.WriteString(n.open)
+		}
+		return
+	case "span":
+		if n.hasAttr("style") || n.hasAttr("class") {
+			b.WriteString(n.open + w.inlineRaw(n) + "</span>")
+			return
+		}
+	}
+	if inlineHTML[n.tag] {
+		b.WriteString(n.open + w.inlineRaw(n) + "</" + n.tag + ">")
+		return
+	}
+	if w.isBlock(n) || rawBlocks[n.tag] {
+		// A block inside inline content, such as a paragraph in a table
+		// cell, is joined with its surroundings.
+		b.WriteString(" " + w.inlineRaw(n) + " ")
+		return
+	}
+	b.WriteString(w.inlineRaw(n))
+}
+
+// inlineRaw converts the children of n without collapsing the result.
+func (w *mdWriter) inlineRaw(n *node) string {
+	var b strings.Builder
+	for _, c := range mergeRuns(n.children) {
+		w.inline(&b, c)
+	}
+	return b.String()
+}
+
+// emphasisDelims maps emphasis elements to their Markdown delimiters.
+var emphasisDelims = map[string]string{
+	"strong": "**", "b": "**",
+	"em": "*", "i": "*", "cite": "*",
+	"s": "~~", "del": "~~", "strike": "~~",
+}
+
+// mergeRuns joins adjacent emphasis elements of the same kind, as editors
+// often split a bold run in two. Written separately they would touch, as
+// in "**a****b**", which Markdown does not read back as emphasis.
+func mergeRuns(nodes []*node) []*node {
+	var out []*node
+	for _, n := range nodes {
+		delim := emphasisDelims[n.tag]
+		if last := len(out) - 1; delim != "" && last >= 0 && emphasisDelims[out[last].tag] == delim {
+			prev := out[last]
+			out[last] = &node{tag: prev.tag, attrs: prev.attrs, open: prev.open, parent: prev.parent,
+				children: append(append([]*node(nil), prev.children...), n.children...)}
+			continue
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// wrap surrounds s with delim, keeping leading and trailing spaces outside.
+func wrap(delim, s string) string {
+	core := strings.TrimSpace(s)
+	if core == "" || core == `\` {
+		return s
+	}
+	lead := s[:strings.Index(s, core)]
+	trail := s[len(lead)+len(core):]
+	return lead + delim + core + delim + trail
+}
+
+// codeSpan writes s as a code span, using a fence longer than any run of
+// backticks in it.
+func codeSpan(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+var scheme = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*$`)
+
+// autolinkable reports whether link n can be written as <href>.
+func autolinkable(n *node, href string) bool {
+	text := n.textContent()
+	if len(n.children) != 1 || n.children[0].tag != "" || n.hasAttr("title") || !scheme.MatchString(href) {
+		return false
+	}
+	return text == href || "mailto:"+text == href && strings.Contains(text, "@")
+}
+
+func linkDestination(href, title string) string {
+	if strings.ContainsAny(href, " ()<>") || href == "" {
+		href = "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(href) + ">"
+	}
+	if title != "" {
+		href += ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+	}
+	return href
+}
+
+func hasOnlyAttrs(n *node, names ...string) bool {
+	for name := range n.attrs {
+		if !contains(names, name) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package markup
+
+import "testing"
+
+func TestToMarkdown(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"heading and paragraph",
+			`<h2 id="bkmrk-setup">Setup</h2><p id="bkmrk-run">Run <strong>make</strong> and <em>wait</em>.</p>`,
+			"## Setup\n\nRun **make** and *wait*.\n"},
+		{"callout",
+			`<p class="callout warning" id="bkmrk-x">Back up <code>data/</code> first.<br>Really.</p>`,
+			"> [!warning]\n> Back up `data/` first.\\\n> Really.\n"},
+		{"details",
+			`<details open><summary>Logs</summary><p>See <a href="/books/ops">ops</a>.</p></details>`,
+			"<details open>\n<summary>Logs</summary>\n\nSee [ops](/books/ops).\n\n</details>\n"},
+		{"drawio",
+			`<div drawio-diagram="12" id="bkmrk-d"><img src="/uploads/images/drawio/d.png"></div>`,
+			"<div drawio-diagram=\"12\" id=\"bkmrk-d\"><img src=\"/uploads/images/drawio/d.png\"></div>\n"},
+		{"include tag",
+			`<p id="bkmrk-1">{{@123#bkmrk-intro}}</p>`,
+			"{{@123#bkmrk-intro}}\n"},
+		{"task list",
+			`<ul class="contains-task-list"><li class="task-list-item"><input type="checkbox" checked="checked"> Done</li><li class="task-list-item"><input type="checkbox"> Open</li></ul>`,
+			"- [x] Done\n- [ ] Open\n"},
+		{"nested lists",
+			`<ol start="2"><li><p>Build</p><ul><li>lint</li><li>test</li></ul></li><li>Ship</li></ol>`,
+			"2. Build\n   - lint\n   - test\n3. Ship\n"},
+		{"code block",
+			"<pre id=\"bkmrk-c\"><code class=\"language-bash\">echo \"&lt;ok&gt;\"\n```\n</code></pre>",
+			"````bash\necho \"<ok>\"\n```\n````\n"},
+		{"table",
+			`<table><thead><tr><th>Key</th><th style="text-align: right">Value</th></tr></thead><tbody><tr><td>a|b</td><td><p>1</p></td></tr></tbody></table>`,
+			"| Key | Value |\n| --- | ---: |\n| a\\|b | 1 |\n"},
+		{"table without header",
+			"<table>\n<tbody>\n\n<tr><td>x</td></tr></tbody></table>",
+			"<table>\n<tbody>\n<tr><td>x</td></tr></tbody></table>\n"},
+		{"image link",
+			`<p><a href="/uploads/full.png" target="_blank"><img src="/uploads/thumb.png" alt="diagram"></a></p>`,
+			"[![diagram](/uploads/thumb.png)](/uploads/full.png)\n"},
+		{"sized image",
+			`<p><img src="a.png" width="200"></p>`,
+			"<img src=\"a.png\" width=\"200\">\n"},
+		{"inline html",
+			`<p><u>under</u> <span style="color: red">red</span> <s>old</s> <span>plain</span></p>`,
+			"<u>under</u> <span style=\"color: red\">red</span> ~~old~~ plain\n"},
+		{"escaping",
+			`<p>1. *not* a list, [x] &lt;b&gt; snake_case _x_ &amp;copy;</p><p># no heading</p>`,
+			"1\\. \\*not\\* a list, \\[x\\] \\<b> snake_case \\_x\\_ \\&copy;\n\n\\# no heading\n"},
+		{"whitespace",
+			"<p>\n  Some   text\n  <strong> spaced </strong>\n</p><p><br></p><p></p>",
+			"Some text\n**spaced**\n"},
+		{"adjacent emphasis",
+			`<p><strong>a</strong><strong>b</strong>x <em>c</em><i>d</i> <s>e</s><del>f</del> <b>g</b><em>h</em></p><ul><li><strong>i</strong><b>j</b></li></ul>`,
+			"**ab**x *cd* ~~ef~~ **g***h*\n\n- **ij**\n"},
+		{"autolink",
+			`<p><a href="https://example.com">https://example.com</a> <a href="mailto:ops@example.com">ops@example.com</a></p>`,
+			"<https://example.com> <ops@example.com>\n"},
+		{"blockquote and rule",
+			`<blockquote><p>One</p><p>Two</p></blockquote><hr>`,
+			"> One\n>\n> Two\n\n---\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToMarkdown(tt.in); got != tt.want {
+				t.Errorf("ToMarkdown(%q)\n got: %q\nwant: %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
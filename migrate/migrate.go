@@ -0,0 +1,89 @@
+// Package migrate copies shelves and books from one Bookstack instance to
+// another.
+//
+// A migration writes the selected content to a temporary backup archive
+// (see package backup) and restores it on the target, so hierarchy,
+// priorities, tags, attachments, images, comments and item permissions
+// are carried over. Links, book and shelf URLs and image URLs pointing at
+// migrated items on the source are rewritten to the target; links to
+// anything else keep pointing at the source.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/backup"
+)
+
+// Options configures a migration. A nil *Options uses the defaults.
+type Options struct {
+	// RoleIDs and UserIDs map role and user IDs of the source instance to
+	// the target's, for item permissions and ownership. See
+	// backup.RestoreOptions.
+	RoleIDs map[int]int
+	UserIDs map[int]int
+
+	// TempDir is where the intermediate archive is written. It defaults
+	// to os.TempDir.
+	TempDir string
+}
+
+// Report describes a completed migration.
+type Report struct {
+	Source string `json:"source"` // Base URL of the source instance
+	Target string `json:"target"` // Base URL of the target instance
+
+	// Counts holds the number of items copied, keyed by archive entry
+	// name (for example "pages.jsonl").
+	Counts map[string]int `json:"counts"`
+
+	// IDs maps source IDs to target IDs for every copied item.
+	IDs *backup.IDMap `json:"ids"`
+}
+
+// Shelf copies a shelf and all books on it from src to dst.
+func Shelf(ctx context.Context, src, dst *bookstack.Client, shelfID int, opts *Options) (*Report, error) {
+	return Run(ctx, src, dst, backup.Scope{ShelfIDs: []int{shelfID}}, opts)
+}
+
+// Book copies a single book from src to dst.
+func Book(ctx context.Context, src, dst *bookstack.Client, bookID int, opts *Options) (*Report, error) {
+	return Run(ctx, src, dst, backup.Scope{BookIDs: []int{bookID}}, opts)
+}
+
+// Run copies the content in scope from src to dst. When an error occurs
+// after copying started, the returned Report holds the IDs of the items
+// created so far, so a partial migration can be cleaned up.
+func Run(ctx context.Context, src, dst *bookstack.Client, scope backup.Scope, opts *Options) (*Report, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	r := &Report{Source: src.BaseURL(), Target: dst.BaseURL()}
+
+	f, err := os.CreateTemp(opts.TempDir, "bookstack-migrate-*.zip")
+	if err != nil {
+		return r, fmt.Errorf("creating temporary archive: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	m, err := backup.WriteScope(ctx, src, f, scope)
+	if err != nil {
+		return r, fmt.Errorf("reading source: %w", err)
+	}
+	r.Counts = m.Counts
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return r, err
+	}
+
+	r.IDs, err = backup.Restore(ctx, dst, f, size, &backup.RestoreOptions{RoleIDs: opts.RoleIDs, UserIDs: opts.UserIDs})
+	if err != nil {
+		return r, fmt.Errorf("writing target: %w", err)
+	}
+	return r, nil
+}
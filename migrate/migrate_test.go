@@ -0,0 +1,96 @@
+package migrate
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/bookstacktest"
+)
+
+func TestBook(t *testing.T) {
+	src := bookstacktest.NewServer()
+	defer src.Close()
+	ops := src.AddBook(bookstack.Book{Name: "Operations", Tags: []bookstack.Tag{{Name: "team", Value: "platform"}}})
+	other := src.AddBook(bookstack.Book{Name: "Other"})
+	ch := src.AddChapter(bookstack.Chapter{BookID: ops.ID, Name: "Runbooks", Priority: 5})
+	deploy := src.AddPage(bookstack.Page{ChapterID: ch.ID, Name: "Deploy", Markdown: "# Deploy", Priority: 7, Template: true})
+	external := src.AddPage(bookstack.Page{BookID: other.ID, Name: "Elsewhere", Markdown: "x"})
+	img := src.AddImage(bookstack.Image{Name: "arch.png", UploadedTo: deploy.ID}, []byte("PNG"))
+	src.AddComment(bookstack.Comment{PageID: deploy.ID, HTML: "<p>Nice</p>"})
+	src.AddComment(bookstack.Comment{PageID: external.ID, HTML: "<p>Not copied</p>"})
+	overview := src.AddPage(bookstack.Page{BookID: ops.ID, Name: "Overview", Markdown: strings.Join([]string{
+		"[deploy](" + src.URL + "/link/" + strconv.Itoa(deploy.ID) + ")",
+		"[elsewhere](" + src.URL + "/link/" + strconv.Itoa(external.ID) + ")",
+		"[book](" + src.URL + "/books/operations/chapter/runbooks)",
+		"![arch](" + img.URL + ")",
+	}, "\n")})
+
+	dst := bookstacktest.NewServer()
+	defer dst.Close()
+	dst.AddBook(bookstack.Book{Name: "Existing"})
+	dst.AddPage(bookstack.Page{BookID: 1, Name: "Existing page", Markdown: "y"})
+
+	ctx := context.Background()
+	r, err := Book(ctx, src.Client(), dst.Client(), ops.ID, nil)
+	if err != nil {
+		t.Fatalf("Book: %v", err)
+	}
+	if r.Source != src.URL || r.Target != dst.URL {
+		t.Errorf("report = %+v", r)
+	}
+	if len(r.IDs.Books) != 1 || len(r.IDs.Pages) != 2 || len(r.IDs.Images) != 1 || len(r.IDs.Comments) != 1 {
+		t.Errorf("IDs = %+v", r.IDs)
+	}
+
+	newCh, ok := dst.Chapter(r.IDs.Chapters[ch.ID])
+	if !ok || newCh.Priority != 5 {
+		t.Errorf("chapter = %+v", newCh)
+	}
+	newDeploy, _ := dst.Page(r.IDs.Pages[deploy.ID])
+	if newDeploy.Priority != 7 || newDeploy.ChapterID != newCh.ID || !newDeploy.Template {
+		t.Errorf("deploy = %+v", newDeploy)
+	}
+	newBook, _ := dst.Book(r.IDs.Books[ops.ID])
+	if len(newBook.Tags) != 1 {
+		t.Errorf("book tags = %+v", newBook.Tags)
+	}
+
+	got, _ := dst.Page(r.IDs.Pages[overview.ID])
+	for _, want := range []string{
+		dst.URL + "/link/" + strconv.Itoa(newDeploy.ID),
+		src.URL + "/link/" + strconv.Itoa(external.ID),
+		dst.URL + "/books/" + newBook.Slug + "/chapter/runbooks",
+		dst.URL + "/uploads/images/",
+	} {
+		if !strings.Contains(got.Markdown, want) {
+			t.Errorf("overview = %q, missing %s", got.Markdown, want)
+		}
+	}
+}
+
+func TestShelf(t *testing.T) {
+	src := bookstacktest.NewServer()
+	defer src.Close()
+	a := src.AddBook(bookstack.Book{Name: "A"})
+	b := src.AddBook(bookstack.Book{Name: "B"})
+	src.AddBook(bookstack.Book{Name: "C"})
+	src.AddPage(bookstack.Page{BookID: a.ID, Name: "Intro", Markdown: "hi"})
+	shelf := src.AddShelf(bookstack.Shelf{Name: "Team"}, b.ID, a.ID)
+
+	dst := bookstacktest.NewServer()
+	defer dst.Close()
+	r, err := Shelf(context.Background(), src.Client(), dst.Client(), shelf.ID, nil)
+	if err != nil {
+		t.Fatalf("Shelf: %v", err)
+	}
+	if dst.Count("books") != 2 || dst.Count("pages") != 1 {
+		t.Errorf("books = %d, pages = %d", dst.Count("books"), dst.Count("pages"))
+	}
+	newShelf, _ := dst.Shelf(r.IDs.Shelves[shelf.ID])
+	if len(newShelf.Books) != 2 || newShelf.Books[0].ID != r.IDs.Books[b.ID] {
+		t.Errorf("shelf books = %+v", newShelf.Books)
+	}
+}
@@ -0,0 +1,140 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path"
+)
+
+// MoveTarget is the new location of a moved or copied page or chapter.
+// For pages, ChapterID places the page in that chapter, while BookID alone
+// places it at the top level of the book. Chapters only use BookID.
+type MoveTarget struct {
+	BookID    int
+	ChapterID int
+}
+
+// Move moves a page to another chapter or book, keeping its ID.
+func (s *PagesService) Move(ctx context.Context, id int, target MoveTarget) (*Page, error) {
+	req := &PageUpdateRequest{ChapterID: target.ChapterID}
+	if target.ChapterID == 0 {
+		if target.BookID == 0 {
+			return nil, errors.New("move target needs a BookID or ChapterID")
+		}
+		req.BookID = target.BookID
+	}
+	return s.Update(ctx, id, req)
+}
+
+// Copy creates a copy of a page at target, duplicating its content, tags
+// and attachments. The copy is placed after the existing items at target.
+// Images are shared with the original, as the content keeps their URLs.
+func (s *PagesService) Copy(ctx context.Context, id int, target MoveTarget) (*Page, error) {
+	page, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.copyPage(ctx, page, target, 0)
+}
+
+// copyPage creates a copy of page at target with the given priority, or at
+// the end when priority is 0, then copies its attachments.
+func (s *PagesService) copyPage(ctx context.Context, page *Page, target MoveTarget, priority int) (*Page, error) {
+	req := &PageCreateRequest{
+		BookID:    target.BookID,
+		ChapterID: target.ChapterID,
+		Name:      page.Name,
+		Priority:  priority,
+		Tags:      page.Tags,
+	}
+	if page.Markdown != "" {
+		req.Markdown = page.Markdown
+	} else {
+		req.HTML = page.HTML
+	}
+	cp, err := s.Create(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("copying page %d: %w", page.ID, err)
+	}
+	if err := s.client.copyAttachments(ctx, page.ID, cp.ID); err != nil {
+		return cp, err
+	}
+	return cp, nil
+}
+
+// copyAttachments duplicates the attachments of one page onto another.
+func (c *Client) copyAttachments(ctx context.Context, fromPageID, toPageID int) error {
+	opts := &ListOptions{Sort: "order", Filter: map[string]string{"uploaded_to": fmt.Sprint(fromPageID)}}
+	for a, err := range c.Attachments.ListAllWith(ctx, opts) {
+		if err != nil {
+			return err
+		}
+		full, err := c.Attachments.Get(ctx, a.ID)
+		if err != nil {
+			return fmt.Errorf("copying attachment %d: %w", a.ID, err)
+		}
+		if full.External {
+			_, err = c.Attachments.Create(ctx, &AttachmentCreateRequest{Name: full.Name, UploadedTo: toPageID, Link: full.Content})
+		} else {
+			var data []byte
+			if data, err = base64.StdEncoding.DecodeString(full.Content); err != nil {
+				return fmt.Errorf("copying attachment %d: decoding content: %w", a.ID, err)
+			}
+			filename := full.Name
+			if full.Extension != "" && path.Ext(filename) != "."+full.Extension {
+				filename += "." + full.Extension
+			}
+			_, err = c.Attachments.Upload(ctx, &AttachmentUploadRequest{Name: full.Name, UploadedTo: toPageID, Filename: filename, Data: data})
+		}
+		if err != nil {
+			return fmt.Errorf("copying attachment %d: %w", a.ID, err)
+		}
+	}
+	return nil
+}
+
+// Move moves a chapter and its pages to another book, keeping their IDs.
+func (s *ChaptersService) Move(ctx context.Context, id int, target MoveTarget) (*Chapter, error) {
+	if target.BookID == 0 || target.ChapterID != 0 {
+		return nil, errors.New("chapters can only be moved to a book")
+	}
+	return s.Update(ctx, id, &ChapterUpdateRequest{BookID: target.BookID})
+}
+
+// Copy creates a copy of a chapter at the end of the target book, along
+// with copies of its pages in their original order.
+func (s *ChaptersService) Copy(ctx context.Context, id int, target MoveTarget) (*Chapter, error) {
+	if target.BookID == 0 || target.ChapterID != 0 {
+		return nil, errors.New("chapters can only be copied to a book")
+	}
+	ch, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cp, err := s.Create(ctx, &ChapterCreateRequest{
+		BookID:      target.BookID,
+		Name:        ch.Name,
+		Description: ch.Description,
+		Tags:        ch.Tags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("copying chapter %d: %w", id, err)
+	}
+
+	opts := &ListOptions{Sort: "priority", Filter: map[string]string{"chapter_id": fmt.Sprint(id)}}
+	for p, err := range s.client.Pages.ListAllWith(ctx, opts) {
+		if err != nil {
+			return cp, err
+		}
+		page, err := s.client.Pages.Get(ctx, p.ID)
+		if err != nil {
+			return cp, fmt.Errorf("copying page %d: %w", p.ID, err)
+		}
+		if _, err := s.client.Pages.copyPage(ctx, page, MoveTarget{ChapterID: cp.ID}, page.Priority); err != nil {
+			return cp, err
+		}
+	}
+	return cp, nil
+}
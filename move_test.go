@@ -0,0 +1,105 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestPagesService_Move(t *testing.T) {
+	var body map[string]any
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/api/pages/7" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(map[string]any{"id": 7})
+	})
+	ctx := context.Background()
+
+	if _, err := c.Pages.Move(ctx, 7, MoveTarget{BookID: 2, ChapterID: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if len(body) != 1 || body["chapter_id"] != float64(5) {
+		t.Errorf("move to chapter: body = %v", body)
+	}
+	if _, err := c.Pages.Move(ctx, 7, MoveTarget{BookID: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if len(body) != 1 || body["book_id"] != float64(2) {
+		t.Errorf("move to book: body = %v", body)
+	}
+	if _, err := c.Pages.Move(ctx, 7, MoveTarget{}); err == nil {
+		t.Error("expected an error for an empty target")
+	}
+	if _, err := c.Chapters.Move(ctx, 3, MoveTarget{ChapterID: 5}); err == nil {
+		t.Error("expected an error moving a chapter into a chapter")
+	}
+}
+
+func TestPagesService_Copy(t *testing.T) {
+	var created map[string]any
+	var attachments []map[string]any
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/pages/7":
+			json.NewEncoder(w).Encode(map[string]any{
+				"id": 7, "name": "Deploy", "html": "<p>x</p>", "markdown": "x",
+				"tags": []map[string]any{{"name": "team", "value": "ops"}},
+			})
+		case r.Method == "POST" && r.URL.Path == "/api/pages":
+			json.NewDecoder(r.Body).Decode(&created)
+			json.NewEncoder(w).Encode(map[string]any{"id": 8, "name": "Deploy"})
+		case r.Method == "GET" && r.URL.Path == "/api/attachments":
+			if got := r.URL.Query().Get("filter[uploaded_to]"); got != "7" {
+				t.Errorf("filter[uploaded_to] = %q", got)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": 1}, {"id": 2}}, "total": 2})
+		case r.Method == "GET" && r.URL.Path == "/api/attachments/1":
+			json.NewEncoder(w).Encode(map[string]any{"id": 1, "name": "Spec", "external": true, "content": "https://example.com/spec"})
+		case r.Method == "GET" && r.URL.Path == "/api/attachments/2":
+			json.NewEncoder(w).Encode(map[string]any{"id": 2, "name": "notes", "extension": "txt", "content": base64.StdEncoding.EncodeToString([]byte("hi"))})
+		case r.Method == "POST" && r.URL.Path == "/api/attachments":
+			a := map[string]any{}
+			if r.Header.Get("Content-Type") == "application/json" {
+				json.NewDecoder(r.Body).Decode(&a)
+			} else {
+				r.ParseMultipartForm(1 << 20)
+				f, h, _ := r.FormFile("file")
+				var buf [16]byte
+				n, _ := f.Read(buf[:])
+				a["uploaded_to"], a["file"], a["data"] = r.FormValue("uploaded_to"), h.Filename, string(buf[:n])
+			}
+			attachments = append(attachments, a)
+			json.NewEncoder(w).Encode(map[string]any{"id": 10 + len(attachments)})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	cp, err := c.Pages.Copy(context.Background(), 7, MoveTarget{ChapterID: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cp.ID != 8 {
+		t.Errorf("copy ID = %d", cp.ID)
+	}
+	if created["chapter_id"] != float64(3) || created["markdown"] != "x" || created["html"] != nil {
+		t.Errorf("create body = %v", created)
+	}
+	if tags, _ := created["tags"].([]any); len(tags) != 1 {
+		t.Errorf("tags = %v", created["tags"])
+	}
+	if len(attachments) != 2 {
+		t.Fatalf("attachments = %v", attachments)
+	}
+	if attachments[0]["link"] != "https://example.com/spec" || attachments[0]["uploaded_to"] != float64(8) {
+		t.Errorf("link attachment = %v", attachments[0])
+	}
+	if attachments[1]["file"] != "notes.txt" || attachments[1]["data"] != "hi" || attachments[1]["uploaded_to"] != "8" {
+		t.Errorf("file attachment = %v", attachments[1])
+	}
+}
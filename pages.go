@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"iter"
+
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/markup"
 )
 
 // PagesService handles operations on pages.
@@ -23,7 +25,12 @@ func (s *PagesService) List(ctx context.Context, opts *ListOptions) ([]Page, err
 
 // ListAll returns an iterator over all pages, handling pagination automatically.
 func (s *PagesService) ListAll(ctx context.Context) iter.Seq2[Page, error] {
-	return listAll[Page](ctx, s.client, "/api/pages")
+	return listAll[Page](ctx, s.client, "/api/pages", nil)
+}
+
+// ListAllWith returns an iterator over all pages matching opts, handling pagination automatically.
+func (s *PagesService) ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Page, error] {
+	return listAll[Page](ctx, s.client, "/api/pages", opts)
 }
 
 // Get retrieves a single page by ID, including its content.
@@ -36,6 +43,27 @@ func (s *PagesService) Get(ctx context.Context, id int) (*Page, error) {
 	return &page, nil
 }
 
+// GetBySlug retrieves a single page by its URL slug, including its content.
+// Page slugs are only unique within a book, so bookSlug should be given;
+// if it is empty the slug must match exactly one page across all books.
+func (s *PagesService) GetBySlug(ctx context.Context, bookSlug, slug string) (*Page, error) {
+	return getBySlug(ctx, s.client, "page:"+bookSlug+"/"+slug, func() (int, error) {
+		bookID, err := s.client.bookIDBySlug(ctx, bookSlug)
+		if err != nil {
+			return 0, err
+		}
+		pages, err := s.List(ctx, slugFilter(slug, bookID))
+		if err != nil {
+			return 0, err
+		}
+		ids := make([]int, len(pages))
+		for i, p := range pages {
+			ids[i] = p.ID
+		}
+		return singleMatch("page", slug, ids)
+	}, s.Get, func(p *Page) bool { return p.Slug == slug })
+}
+
 // Create creates a new page.
 func (s *PagesService) Create(ctx context.Context, req *PageCreateRequest) (*Page, error) {
 	var page Page
@@ -56,6 +84,24 @@ func (s *PagesService) Update(ctx context.Context, id int, req *PageUpdateReques
 	return &page, nil
 }
 
+// UpdateIfUnchanged updates the page seen, but only if it has not changed
+// since the caller read it. The page is re-fetched first; if its revision
+// count or update time differ from seen, nothing is written and a
+// *ConflictError holding the current page is returned.
+//
+// The check and the update are separate requests, so an edit made between
+// them is still overwritten; the window is only as wide as one round trip.
+func (s *PagesService) UpdateIfUnchanged(ctx context.Context, seen *Page, req *PageUpdateRequest) (*Page, error) {
+	current, err := s.Get(ctx, seen.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current.Revision != seen.Revision || !current.UpdatedAt.Equal(seen.UpdatedAt) {
+		return nil, &ConflictError{PageID: seen.ID, Seen: seen, Current: current}
+	}
+	return s.Update(ctx, seen.ID, req)
+}
+
 // Delete deletes a page by ID.
 func (s *PagesService) Delete(ctx context.Context, id int) error {
 	return s.client.do(ctx, "DELETE", fmt.Sprintf("/api/pages/%d", id), nil, nil)
@@ -66,6 +112,24 @@ func (s *PagesService) ExportMarkdown(ctx context.Context, id int) ([]byte, erro
 	return s.client.doRaw(ctx, "GET", fmt.Sprintf("/api/pages/%d/export/markdown", id))
 }
 
+// MarkdownContent returns the page content as Markdown: the Markdown
+// source for pages written in the Markdown editor, and the HTML converted
+// with markup.ToMarkdown otherwise. Unlike PagesService.ExportMarkdown it
+// makes no request, but needs a page fetched with its content.
+func (p *Page) MarkdownContent() string {
+	if p.Markdown != "" || p.HTML == "" {
+		return p.Markdown
+	}
+	return markup.ToMarkdown(p.HTML)
+}
+
+// Outline parses the headings of the page HTML into sections, each with
+// its "bkmrk-" anchor and content. The page must have been fetched with
+// its content.
+func (p *Page) Outline() *markup.Outline {
+	return markup.ParseOutline(p.HTML)
+}
+
 // ExportPDF exports a page as PDF.
 func (s *PagesService) ExportPDF(ctx context.Context, id int) ([]byte, error) {
 	return s.client.doRaw(ctx, "GET", fmt.Sprintf("/api/pages/%d/export/pdf", id))
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestPagesService_List(t *testing.T) {
@@ -132,6 +133,46 @@ func TestPagesService_Update(t *testing.T) {
 	}
 }
 
+func TestPagesService_UpdateIfUnchanged(t *testing.T) {
+	seenAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	current := map[string]any{"id": 10, "revision_count": 4, "updated_at": "2024-03-01T10:00:00.000000Z"}
+	var puts int
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			json.NewEncoder(w).Encode(current)
+		case "PUT":
+			puts++
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["summary"] != "Clarify steps" {
+				t.Errorf("summary = %v", body["summary"])
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": 10, "revision_count": 5})
+		}
+	})
+	seen := &Page{ID: 10, Revision: 4, UpdatedAt: seenAt}
+	req := &PageUpdateRequest{Markdown: "# New", Summary: "Clarify steps"}
+
+	page, err := c.Pages.UpdateIfUnchanged(context.Background(), seen, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Revision != 5 || puts != 1 {
+		t.Errorf("Revision = %d, puts = %d", page.Revision, puts)
+	}
+
+	current["revision_count"], current["updated_at"] = 5, "2024-03-01T10:05:00.000000Z"
+	_, err = c.Pages.UpdateIfUnchanged(context.Background(), seen, req)
+	var conflict *ConflictError
+	if !errors.Is(err, ErrConflict) || !errors.As(err, &conflict) {
+		t.Fatalf("err = %v, want a ConflictError", err)
+	}
+	if conflict.Current.Revision != 5 || conflict.Seen != seen || puts != 1 {
+		t.Errorf("conflict = %+v, puts = %d", conflict, puts)
+	}
+}
+
 func TestPagesService_Create_BadRequest(t *testing.T) {
 	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
@@ -241,3 +282,23 @@ func TestPagesService_ExportMarkdown_NotFound(t *testing.T) {
 		t.Error("expected ErrNotFound")
 	}
 }
+
+func TestPage_MarkdownContent(t *testing.T) {
+	md := &Page{Markdown: "# Title", HTML: "<h1>Title</h1>"}
+	if got := md.MarkdownContent(); got != "# Title" {
+		t.Errorf("Markdown page: %q", got)
+	}
+	wysiwyg := &Page{HTML: `<p class="callout info">Note</p>`}
+	if got, want := wysiwyg.MarkdownContent(), "> [!info]\n> Note\n"; got != want {
+		t.Errorf("HTML page: %q, want %q", got, want)
+	}
+}
+
+func TestPage_Outline(t *testing.T) {
+	page := &Page{HTML: `<p>Intro</p><h2 id="bkmrk-setup">Setup</h2><p>Run make.</p>`}
+	o := page.Outline()
+	sec := o.Find("setup")
+	if sec == nil || sec.Anchor != "bkmrk-setup" || sec.Body != "<p>Run make.</p>" {
+		t.Errorf("Find(setup) = %+v", sec)
+	}
+}
@@ -0,0 +1,34 @@
+package bookstack
+
+import (
+	"context"
+	"fmt"
+)
+
+// PermissionsService handles item-level content permissions.
+//
+// Content types are the Entity type names: EntityBook, EntityChapter,
+// EntityPage and EntityShelf.
+type PermissionsService struct {
+	client *Client
+}
+
+// Get retrieves the permissions of an item.
+func (s *PermissionsService) Get(ctx context.Context, contentType string, id int) (*ContentPermissions, error) {
+	var p ContentPermissions
+	err := s.client.do(ctx, "GET", fmt.Sprintf("/api/content-permissions/%s/%d", contentType, id), nil, &p)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Update changes the permissions of an item and returns the result.
+func (s *PermissionsService) Update(ctx context.Context, contentType string, id int, req *ContentPermissionsUpdateRequest) (*ContentPermissions, error) {
+	var p ContentPermissions
+	err := s.client.do(ctx, "PUT", fmt.Sprintf("/api/content-permissions/%s/%d", contentType, id), req, &p)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
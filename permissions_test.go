@@ -0,0 +1,62 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestPermissionsService_Get(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/content-permissions/book/4" {
+			t.Errorf("path = %s, want /api/content-permissions/book/4", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"owner": map[string]any{"id": 1, "name": "Admin", "slug": "admin"},
+			"role_permissions": []map[string]any{
+				{"role_id": 3, "view": true, "role": map[string]any{"id": 3, "display_name": "Viewer"}},
+			},
+			"fallback_permissions": map[string]any{"inheriting": true},
+		})
+	})
+
+	p, err := c.Permissions.Get(context.Background(), EntityBook, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Owner.ID != 1 || len(p.RolePermissions) != 1 || !p.RolePermissions[0].View || !p.FallbackPermissions.Inheriting {
+		t.Errorf("got %+v", p)
+	}
+	if p.RolePermissions[0].Role == nil || p.RolePermissions[0].Role.DisplayName != "Viewer" {
+		t.Errorf("role = %+v", p.RolePermissions[0].Role)
+	}
+}
+
+func TestPermissionsService_Update(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/api/content-permissions/bookshelf/2" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["owner_id"] != float64(5) {
+			t.Errorf("owner_id = %v", body["owner_id"])
+		}
+		if fb, _ := body["fallback_permissions"].(map[string]any); fb["view"] != true {
+			t.Errorf("fallback_permissions = %v", body["fallback_permissions"])
+		}
+		json.NewEncoder(w).Encode(map[string]any{"owner": map[string]any{"id": 5}})
+	})
+
+	p, err := c.Permissions.Update(context.Background(), EntityShelf, 2, &ContentPermissionsUpdateRequest{
+		OwnerID:             5,
+		FallbackPermissions: &FallbackPermissions{View: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Owner.ID != 5 {
+		t.Errorf("Owner.ID = %d", p.Owner.ID)
+	}
+}
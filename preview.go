@@ -0,0 +1,74 @@
+package bookstack
+
+import (
+	"html"
+	"strings"
+)
+
+// ANSI escape sequences used by PreviewANSI to highlight matched terms.
+const (
+	ansiHighlight = "\x1b[1;33m"
+	ansiReset     = "\x1b[0m"
+)
+
+// PreviewText converts a highlighted preview fragment (such as
+// SearchResult.PreviewHTML.Content) into plain text.
+// Tags are removed, entities decoded and runs of whitespace collapsed.
+func PreviewText(fragment string) string {
+	return renderPreview(fragment, "", "")
+}
+
+// PreviewANSI converts a highlighted preview fragment into text suitable for a
+// terminal, rendering matched terms in bold yellow using ANSI escape codes.
+func PreviewANSI(fragment string) string {
+	return renderPreview(fragment, ansiHighlight, ansiReset)
+}
+
+// renderPreview strips markup from fragment, replacing <strong> and <b>
+// elements with the given open and close markers.
+func renderPreview(fragment, open, close string) string {
+	var b strings.Builder
+	depth := 0
+	for len(fragment) > 0 {
+		lt := strings.IndexByte(fragment, '<')
+		if lt < 0 {
+			b.WriteString(html.UnescapeString(fragment))
+			break
+		}
+		b.WriteString(html.UnescapeString(fragment[:lt]))
+		gt := strings.IndexByte(fragment[lt:], '>')
+		if gt < 0 {
+			b.WriteString(html.UnescapeString(fragment[lt:]))
+			break
+		}
+		tag := strings.ToLower(strings.TrimSpace(fragment[lt+1 : lt+gt]))
+		fragment = fragment[lt+gt+1:]
+
+		name, closing := strings.CutPrefix(tag, "/")
+		if i := strings.IndexAny(name, " \t\n/"); i >= 0 {
+			name = name[:i]
+		}
+		switch name {
+		case "strong", "b":
+			if closing {
+				if depth > 0 {
+					depth--
+					if depth == 0 {
+						b.WriteString(close)
+					}
+				}
+			} else {
+				if depth == 0 {
+					b.WriteString(open)
+				}
+				depth++
+			}
+		case "br", "p", "div", "li":
+			b.WriteByte(' ')
+		}
+	}
+	if depth > 0 {
+		b.WriteString(close)
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,40 @@
+package bookstack
+
+import "testing"
+
+func TestPreviewText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "no markup here", "no markup here"},
+		{"highlight", "How to <strong>deploy</strong> the app", "How to deploy the app"},
+		{"entities", "Tom &amp; Jerry &lt;3", "Tom & Jerry <3"},
+		{"whitespace", "line one<br>\n   line two", "line one line two"},
+		{"unclosed tag", "broken <strong", "broken <strong"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PreviewText(tt.in); got != tt.want {
+				t.Errorf("PreviewText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewANSI(t *testing.T) {
+	got := PreviewANSI("How to <strong>deploy</strong> the <strong class=\"x\">app</strong>")
+	want := "How to \x1b[1;33mdeploy\x1b[0m the \x1b[1;33mapp\x1b[0m"
+	if got != want {
+		t.Errorf("PreviewANSI = %q, want %q", got, want)
+	}
+}
+
+func TestPreviewANSI_Unterminated(t *testing.T) {
+	got := PreviewANSI("<strong>deploy")
+	want := "\x1b[1;33mdeploy\x1b[0m"
+	if got != want {
+		t.Errorf("PreviewANSI = %q, want %q", got, want)
+	}
+}
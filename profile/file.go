@@ -0,0 +1,184 @@
+package profile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// File is a parsed profiles file.
+//
+// The file uses a subset of TOML: an optional top-level default key naming
+// the profile to use when none is selected, followed by one table per
+// profile holding string values:
+//
+//	default = "prod"
+//
+//	[prod]
+//	url = "https://docs.example.com"
+//	token_id = "abc123"
+//	token_secret_command = "pass show bookstack/prod"
+//
+//	[staging]
+//	url = "https://staging.docs.example.com"
+//	token_id_command = "op read op://work/bookstack-staging/id"
+//	token_secret_command = "op read op://work/bookstack-staging/secret"
+type File struct {
+	Default  string
+	Profiles map[string]*Profile
+}
+
+// Profile holds the settings of one named profile. For the token fields a
+// literal value takes precedence over the command producing it.
+type Profile struct {
+	Name               string
+	URL                string // "url"
+	TokenID            string // "token_id"
+	TokenIDCommand     string // "token_id_command"
+	TokenSecret        string // "token_secret"
+	TokenSecretCommand string // "token_secret_command"
+}
+
+// set assigns the value of a profile key.
+func (p *Profile) set(key, val string) bool {
+	switch key {
+	case "url":
+		p.URL = val
+	case "token_id":
+		p.TokenID = val
+	case "token_id_command":
+		p.TokenIDCommand = val
+	case "token_secret":
+		p.TokenSecret = val
+	case "token_secret_command":
+		p.TokenSecretCommand = val
+	default:
+		return false
+	}
+	return true
+}
+
+// ReadFile parses the profiles file at path.
+func ReadFile(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	pf, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pf, nil
+}
+
+// Parse reads a profiles file. Unknown keys and TOML features beyond
+// tables and string values are rejected with the offending line number.
+func Parse(r io.Reader) (*File, error) {
+	f := &File{Profiles: make(map[string]*Profile)}
+	var cur *Profile
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(sc.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header %q", n, line)
+			}
+			name, err := parseKey(strings.TrimSpace(line[1 : len(line)-1]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			if _, dup := f.Profiles[name]; dup {
+				return nil, fmt.Errorf("line %d: duplicate profile %q", n, name)
+			}
+			cur = &Profile{Name: name}
+			f.Profiles[name] = cur
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = \"value\"", n)
+		}
+		key, err := parseKey(strings.TrimSpace(k))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		val, err := parseString(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", n, key, err)
+		}
+		switch {
+		case cur == nil && key == "default":
+			f.Default = val
+		case cur == nil:
+			return nil, fmt.Errorf("line %d: unknown top-level key %q", n, key)
+		case !cur.set(key, val):
+			return nil, fmt.Errorf("line %d: unknown key %q in profile %q", n, key, cur.Name)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// stripComment removes a trailing # comment that is not inside a string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == 0 && c == '#':
+			return line[:i]
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++
+		case c == quote:
+			quote = 0
+		}
+	}
+	return line
+}
+
+// parseKey parses a bare or quoted key.
+func parseKey(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'") {
+		return parseString(s)
+	}
+	if s == "" {
+		return "", fmt.Errorf("missing key")
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return "", fmt.Errorf("invalid key %q; quote keys containing %q", s, r)
+		}
+	}
+	return s, nil
+}
+
+// parseString parses a basic ("...") or literal ('...') TOML string.
+func parseString(s string) (string, error) {
+	switch {
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		inner := s[1 : len(s)-1]
+		if strings.ContainsRune(inner, '\'') {
+			return "", fmt.Errorf("invalid literal string %s", s)
+		}
+		return inner, nil
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", s)
+		}
+		return v, nil
+	}
+	return "", fmt.Errorf("expected a quoted string, got %q", s)
+}
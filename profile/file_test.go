@@ -0,0 +1,55 @@
+package profile
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	f, err := Parse(strings.NewReader(`
+# Bookstack profiles
+default = "prod"
+
+[prod]
+url = "https://docs.example.com" # production
+token_id = 'abc#123'
+token_secret_command = "pass show \"bookstack/prod\""
+
+["my wiki"]
+url = "http://localhost:6875"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Default != "prod" {
+		t.Errorf("Default = %q", f.Default)
+	}
+	prod := f.Profiles["prod"]
+	if prod == nil || prod.URL != "https://docs.example.com" || prod.TokenID != "abc#123" || prod.TokenSecretCommand != `pass show "bookstack/prod"` {
+		t.Errorf("prod = %+v", prod)
+	}
+	if w := f.Profiles["my wiki"]; w == nil || w.URL != "http://localhost:6875" {
+		t.Errorf("my wiki = %+v", w)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"url = \"x\"", `line 1: unknown top-level key "url"`},
+		{"[prod]\ntoken = \"x\"", `line 2: unknown key "token" in profile "prod"`},
+		{"[prod]\nurl = https://x", "line 2: url: expected a quoted string"},
+		{"[prod]\n[prod]", `line 2: duplicate profile "prod"`},
+		{"[[prod]]", "line 1: invalid table header"},
+		{"[prod]\nurl", "line 2: expected key"},
+		{"[a.b]", "line 1: invalid key"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
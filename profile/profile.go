@@ -0,0 +1,251 @@
+// Package profile builds client configurations from named profiles.
+//
+// Settings for a profile are looked up, field by field, in this order:
+//
+//  1. Environment variables with the profile prefix, such as
+//     BOOKSTACK_STAGING_URL for the profile "staging".
+//  2. The profile's table in the profiles file (see File).
+//  3. The unprefixed variables BOOKSTACK_URL, BOOKSTACK_TOKEN_ID and
+//     BOOKSTACK_TOKEN_SECRET.
+//
+// Token fields may instead name a command printing the value, so secrets can
+// stay in a password manager such as pass or 1Password:
+//
+//	[prod]
+//	url = "https://docs.example.com"
+//	token_id = "abc123"
+//	token_secret_command = "pass show bookstack/prod"
+//
+// The environment variables use the same names as the file keys, upper-cased:
+// BOOKSTACK_PROD_TOKEN_SECRET_COMMAND overrides the command above.
+//
+//	cfg, err := profile.Load(ctx, "prod")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client, err := bookstack.NewClient(cfg)
+package profile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+// DefaultName is the profile used when none is selected.
+const DefaultName = "default"
+
+// ErrNotFound is returned when a selected profile is defined neither in the
+// profiles file nor in the environment.
+var ErrNotFound = errors.New("profile not found")
+
+// Loader resolves profiles. The zero value reads the default profiles file
+// and the process environment.
+type Loader struct {
+	// Path is the profiles file. If empty, $BOOKSTACK_CONFIG is used, then
+	// DefaultPath; a missing file is only an error when named explicitly.
+	Path string
+
+	// Getenv looks up environment variables. Defaults to os.Getenv.
+	Getenv func(string) string
+
+	// RunCommand runs a secret command and returns its standard output.
+	// Defaults to running the command with "sh -c" ("cmd /C" on Windows).
+	RunCommand func(ctx context.Context, command string) ([]byte, error)
+}
+
+// Load resolves the named profile with a zero Loader.
+func Load(ctx context.Context, name string) (bookstack.Config, error) {
+	return (&Loader{}).Load(ctx, name)
+}
+
+// DefaultPath returns the default location of the profiles file,
+// bookstack/config.toml in the user's configuration directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bookstack", "config.toml"), nil
+}
+
+// Load resolves the named profile into a validated Config. An empty name
+// selects $BOOKSTACK_PROFILE, then the file's default, then DefaultName.
+func (l *Loader) Load(ctx context.Context, name string) (bookstack.Config, error) {
+	getenv := l.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+
+	file, err := l.readFile(getenv)
+	if err != nil {
+		return bookstack.Config{}, err
+	}
+	explicit := name != ""
+	if name == "" {
+		name = getenv("BOOKSTACK_PROFILE")
+		explicit = name != ""
+	}
+	if name == "" && file != nil && file.Default != "" {
+		name, explicit = file.Default, true
+	}
+	if name == "" {
+		name = DefaultName
+	}
+
+	var p *Profile
+	if file != nil {
+		p = file.Profiles[name]
+	}
+	found := p != nil
+	if !found {
+		p = &Profile{Name: name}
+	}
+	sources := []func(string) string{
+		prefixedEnv(getenv, name),
+		p.get,
+		func(key string) string { return getenv("BOOKSTACK_" + strings.ToUpper(key)) },
+	}
+	if explicit && !found && !definedIn(sources[0]) {
+		return bookstack.Config{}, fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+
+	var cfg bookstack.Config
+	if cfg.BaseURL, err = l.resolve(ctx, sources, "url", false); err == nil {
+		if cfg.TokenID, err = l.resolve(ctx, sources, "token_id", true); err == nil {
+			cfg.TokenSecret, err = l.resolve(ctx, sources, "token_secret", true)
+		}
+	}
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return bookstack.Config{}, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return cfg, nil
+}
+
+// readFile reads the profiles file, returning nil if the default file does
+// not exist.
+func (l *Loader) readFile(getenv func(string) string) (*File, error) {
+	path := l.Path
+	if path == "" {
+		path = getenv("BOOKSTACK_CONFIG")
+	}
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, nil
+		}
+	}
+	f, err := ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading profiles: %w", err)
+	}
+	return f, nil
+}
+
+// resolve returns the value of key from the first source defining it or,
+// when command is true, a key_command to run.
+func (l *Loader) resolve(ctx context.Context, sources []func(string) string, key string, command bool) (string, error) {
+	for _, src := range sources {
+		if v := src(key); v != "" {
+			return v, nil
+		}
+		if !command {
+			continue
+		}
+		if c := src(key + "_command"); c != "" {
+			out, err := l.run(ctx, c)
+			if err != nil {
+				return "", fmt.Errorf("%s_command: %w", key, err)
+			}
+			v := strings.TrimRight(string(out), "\r\n")
+			if v == "" {
+				return "", fmt.Errorf("%s_command printed nothing", key)
+			}
+			return v, nil
+		}
+	}
+	return "", nil
+}
+
+func (l *Loader) run(ctx context.Context, command string) ([]byte, error) {
+	if l.RunCommand != nil {
+		return l.RunCommand(ctx, command)
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// get returns the value of a file key.
+func (p *Profile) get(key string) string {
+	switch key {
+	case "url":
+		return p.URL
+	case "token_id":
+		return p.TokenID
+	case "token_id_command":
+		return p.TokenIDCommand
+	case "token_secret":
+		return p.TokenSecret
+	case "token_secret_command":
+		return p.TokenSecretCommand
+	}
+	return ""
+}
+
+// EnvPrefix returns the environment variable prefix of a profile, e.g.
+// "BOOKSTACK_MY_WIKI_" for "my-wiki".
+func EnvPrefix(name string) string {
+	return "BOOKSTACK_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name) + "_"
+}
+
+func prefixedEnv(getenv func(string) string, name string) func(string) string {
+	prefix := EnvPrefix(name)
+	return func(key string) string { return getenv(prefix + strings.ToUpper(key)) }
+}
+
+// definedIn reports whether src sets any profile key.
+func definedIn(src func(string) string) bool {
+	for _, key := range []string{"url", "token_id", "token_id_command", "token_secret", "token_secret_command"} {
+		if src(key) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bookstack "code.beautifulmachines.dev/jakoubek/bookstack-api"
+)
+
+const testFile = `
+default = "prod"
+
+[prod]
+url = "https://docs.example.com"
+token_id = "prod-id"
+token_secret_command = "secret prod"
+
+[staging]
+url = "https://staging.example.com"
+token_id = "staging-id"
+token_secret = "staging-secret"
+`
+
+// newLoader returns a Loader reading content from a temporary file, with
+// the given environment and a fake command runner.
+func newLoader(t *testing.T, content string, env map[string]string) *Loader {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return &Loader{
+		Path:   path,
+		Getenv: func(k string) string { return env[k] },
+		RunCommand: func(_ context.Context, command string) ([]byte, error) {
+			if command == "fail" {
+				return nil, errors.New("exit status 1: not in keychain")
+			}
+			return []byte(strings.ToUpper(command) + "\n"), nil
+		},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		profile string
+		env     map[string]string
+		want    bookstack.Config
+	}{
+		{
+			name: "file default with secret command",
+			want: bookstack.Config{BaseURL: "https://docs.example.com", TokenID: "prod-id", TokenSecret: "SECRET PROD"},
+		},
+		{
+			name:    "named profile",
+			profile: "staging",
+			want:    bookstack.Config{BaseURL: "https://staging.example.com", TokenID: "staging-id", TokenSecret: "staging-secret"},
+		},
+		{
+			name: "BOOKSTACK_PROFILE",
+			env:  map[string]string{"BOOKSTACK_PROFILE": "staging"},
+			want: bookstack.Config{BaseURL: "https://staging.example.com", TokenID: "staging-id", TokenSecret: "staging-secret"},
+		},
+		{
+			name:    "prefixed environment overrides file",
+			profile: "staging",
+			env: map[string]string{
+				"BOOKSTACK_STAGING_TOKEN_SECRET_COMMAND": "from env",
+				"BOOKSTACK_TOKEN_ID":                     "ignored",
+			},
+			want: bookstack.Config{BaseURL: "https://staging.example.com", TokenID: "staging-id", TokenSecret: "FROM ENV"},
+		},
+		{
+			name:    "profile only in environment",
+			profile: "my-wiki",
+			env: map[string]string{
+				"BOOKSTACK_MY_WIKI_URL":          "http://localhost:6875",
+				"BOOKSTACK_MY_WIKI_TOKEN_ID":     "id",
+				"BOOKSTACK_MY_WIKI_TOKEN_SECRET": "secret",
+			},
+			want: bookstack.Config{BaseURL: "http://localhost:6875", TokenID: "id", TokenSecret: "secret"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newLoader(t, testFile, tt.env).Load(ctx, tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_UnprefixedFallback(t *testing.T) {
+	env := map[string]string{
+		"BOOKSTACK_URL":          "https://docs.example.com",
+		"BOOKSTACK_TOKEN_ID":     "id",
+		"BOOKSTACK_TOKEN_SECRET": "secret",
+	}
+	l := &Loader{Path: filepath.Join(t.TempDir(), "none.toml"), Getenv: func(k string) string { return env[k] }}
+	if _, err := l.Load(context.Background(), ""); err == nil {
+		t.Fatal("expected an error for a missing explicit profiles file")
+	}
+
+	l = newLoader(t, "", env)
+	cfg, err := l.Load(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BaseURL != "https://docs.example.com" || cfg.TokenSecret != "secret" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := newLoader(t, testFile, nil).Load(ctx, "qa")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown profile: err = %v, want ErrNotFound", err)
+	}
+
+	_, err = newLoader(t, testFile, map[string]string{"BOOKSTACK_PROD_TOKEN_SECRET_COMMAND": "fail"}).Load(ctx, "prod")
+	if err == nil || !strings.Contains(err.Error(), `profile "prod": token_secret_command: exit status 1: not in keychain`) {
+		t.Errorf("failing command: err = %v", err)
+	}
+
+	_, err = newLoader(t, "[prod]\nurl = \"docs.example.com\"\ntoken_id = \"a\"\ntoken_secret = \"b\"\n", nil).Load(ctx, "prod")
+	if !errors.Is(err, bookstack.ErrInvalidConfig) || !strings.Contains(err.Error(), "must start with http:// or https://") {
+		t.Errorf("invalid URL: err = %v", err)
+	}
+
+	_, err = newLoader(t, "[prod]\nurl = \"https://docs.example.com\"\n", nil).Load(ctx, "prod")
+	if !errors.Is(err, bookstack.ErrInvalidConfig) || !strings.Contains(err.Error(), "TokenID is required") {
+		t.Errorf("missing token: err = %v", err)
+	}
+}
+
+func TestEnvPrefix(t *testing.T) {
+	for name, want := range map[string]string{
+		"prod":    "BOOKSTACK_PROD_",
+		"my-wiki": "BOOKSTACK_MY_WIKI_",
+		"Team 2":  "BOOKSTACK_TEAM_2_",
+	} {
+		if got := EnvPrefix(name); got != want {
+			t.Errorf("EnvPrefix(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLoader_RunCommand(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no shell")
+	}
+	out, err := (&Loader{}).run(context.Background(), "echo secret; echo warning >&2")
+	if err != nil || string(out) != "secret\n" {
+		t.Errorf("run = %q, %v", out, err)
+	}
+	_, err = (&Loader{}).run(context.Background(), "echo denied >&2; exit 3")
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("failing run: err = %v", err)
+	}
+}
@@ -0,0 +1,364 @@
+package bookstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// LayoutItem is one top-level entry of a book layout: either a chapter
+// with its pages in order, or a page placed directly in the book.
+type LayoutItem struct {
+	ChapterID int   `json:"chapter_id,omitempty"` // A chapter...
+	Pages     []int `json:"pages,omitempty"`      // ...and the IDs of its pages, in order
+	PageID    int   `json:"page_id,omitempty"`    // Or a page at the top level of the book
+}
+
+// ReorderChange describes the update made to one chapter or page by Reorder.
+type ReorderChange struct {
+	Type          string `json:"type"` // EntityChapter or EntityPage
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	FromBookID    int    `json:"from_book_id"`
+	ToBookID      int    `json:"to_book_id"`
+	FromChapterID int    `json:"from_chapter_id"` // Pages only; 0 for the top level of a book
+	ToChapterID   int    `json:"to_chapter_id"`
+	FromPriority  int    `json:"from_priority"`
+	ToPriority    int    `json:"to_priority"`
+}
+
+// Moved reports whether the change puts the item in another book or chapter.
+func (c ReorderChange) Moved() bool {
+	return c.FromBookID != c.ToBookID || c.FromChapterID != c.ToChapterID
+}
+
+// PlanReorder computes the updates Reorder would make, without applying them.
+func (s *BooksService) PlanReorder(ctx context.Context, bookID int, layout []LayoutItem) ([]ReorderChange, error) {
+	p := &reorderPlan{client: s.client, bookID: bookID, chapters: map[int]*Chapter{}, pages: map[int]*Page{}}
+	if err := p.load(ctx, layout); err != nil {
+		return nil, err
+	}
+	return p.changes(layout), nil
+}
+
+// Reorder arranges a book's chapters and pages to match layout. The layout
+// must list every chapter and page in the book; chapters and pages from
+// other books are moved in. Only items whose position, chapter or book
+// changes are updated, and existing priorities are kept where they are
+// already in order. New priorities start at 1.
+//
+// The applied changes are returned; if an update fails, the changes made
+// before it are returned along with the error.
+func (s *BooksService) Reorder(ctx context.Context, bookID int, layout []LayoutItem) ([]ReorderChange, error) {
+	changes, err := s.PlanReorder(ctx, bookID, layout)
+	if err != nil {
+		return nil, err
+	}
+	for i, ch := range changes {
+		if err := s.client.applyChange(ctx, ch); err != nil {
+			return changes[:i], fmt.Errorf("updating %s %d: %w", ch.Type, ch.ID, err)
+		}
+	}
+	return changes, nil
+}
+
+func (c *Client) applyChange(ctx context.Context, ch ReorderChange) error {
+	if ch.Type == EntityChapter {
+		req := &ChapterUpdateRequest{Priority: ch.ToPriority}
+		if ch.Moved() {
+			req.BookID = ch.ToBookID
+		}
+		_, err := c.Chapters.Update(ctx, ch.ID, req)
+		return err
+	}
+	req := &PageUpdateRequest{Priority: ch.ToPriority}
+	if ch.Moved() {
+		if ch.ToChapterID != 0 {
+			req.ChapterID = ch.ToChapterID
+		} else {
+			req.BookID = ch.ToBookID
+		}
+	}
+	_, err := c.Pages.Update(ctx, ch.ID, req)
+	return err
+}
+
+// reorderPlan holds the current state of the items involved in a reorder.
+type reorderPlan struct {
+	client   *Client
+	bookID   int
+	chapters map[int]*Chapter
+	pages    map[int]*Page
+}
+
+// load fetches the book's current contents and any items the layout moves
+// in from elsewhere, and checks the layout against them.
+func (p *reorderPlan) load(ctx context.Context, layout []LayoutItem) error {
+	book := map[string]string{"book_id": strconv.Itoa(p.bookID)}
+	for ch, err := range p.client.Chapters.ListAllWith(ctx, &ListOptions{Filter: book}) {
+		if err != nil {
+			return err
+		}
+		p.chapters[ch.ID] = &ch
+	}
+	for pg, err := range p.client.Pages.ListAllWith(ctx, &ListOptions{Filter: book}) {
+		if err != nil {
+			return err
+		}
+		p.pages[pg.ID] = &pg
+	}
+	unlisted := make(map[string]bool)
+	for id := range p.chapters {
+		unlisted[EntityChapter+" "+strconv.Itoa(id)] = true
+	}
+	for id := range p.pages {
+		unlisted[EntityPage+" "+strconv.Itoa(id)] = true
+	}
+
+	seen := make(map[string]bool)
+	use := func(typ string, id int) error {
+		key := typ + " " + strconv.Itoa(id)
+		if seen[key] {
+			return fmt.Errorf("layout lists %s more than once", key)
+		}
+		seen[key] = true
+		delete(unlisted, key)
+		return nil
+	}
+	for _, item := range layout {
+		switch {
+		case item.ChapterID != 0 && item.PageID != 0, item.ChapterID == 0 && item.PageID == 0:
+			return errors.New("each layout item must set exactly one of ChapterID and PageID")
+		case item.PageID != 0 && len(item.Pages) > 0:
+			return fmt.Errorf("layout item for page %d cannot have pages", item.PageID)
+		case item.ChapterID != 0:
+			if err := use(EntityChapter, item.ChapterID); err != nil {
+				return err
+			}
+			if err := p.loadChapter(ctx, item.ChapterID, seen, unlisted); err != nil {
+				return err
+			}
+			for _, id := range item.Pages {
+				if err := use(EntityPage, id); err != nil {
+					return err
+				}
+				if err := p.loadPage(ctx, id); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := use(EntityPage, item.PageID); err != nil {
+				return err
+			}
+			if err := p.loadPage(ctx, item.PageID); err != nil {
+				return err
+			}
+		}
+	}
+	if len(unlisted) > 0 {
+		missing := make([]string, 0, len(unlisted))
+		for key := range unlisted {
+			missing = append(missing, key)
+		}
+		slices.Sort(missing)
+		return fmt.Errorf("layout does not list %v", missing)
+	}
+	return nil
+}
+
+// loadChapter fetches a chapter from another book. Its pages move with it,
+// so those not already listed by the layout are added to the items it
+// must list.
+func (p *reorderPlan) loadChapter(ctx context.Context, id int, seen, unlisted map[string]bool) error {
+	if _, ok := p.chapters[id]; ok {
+		return nil
+	}
+	ch, err := p.client.Chapters.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("getting chapter %d: %w", id, err)
+	}
+	p.chapters[id] = ch
+	opts := &ListOptions{Filter: map[string]string{"chapter_id": strconv.Itoa(id)}}
+	for pg, err := range p.client.Pages.ListAllWith(ctx, opts) {
+		if err != nil {
+			return err
+		}
+		p.pages[pg.ID] = &pg
+		if key := EntityPage + " " + strconv.Itoa(pg.ID); !seen[key] {
+			unlisted[key] = true
+		}
+	}
+	return nil
+}
+
+func (p *reorderPlan) loadPage(ctx context.Context, id int) error {
+	if _, ok := p.pages[id]; ok {
+		return nil
+	}
+	pg, err := p.client.Pages.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("getting page %d: %w", id, err)
+	}
+	p.pages[id] = pg
+	return nil
+}
+
+// changes computes the updates for a validated layout: chapters first, so
+// that pages moved into them find them in place.
+func (p *reorderPlan) changes(layout []LayoutItem) []ReorderChange {
+	var chapters, pages []ReorderChange
+
+	top := make([]slot, len(layout))
+	for i, item := range layout {
+		if item.ChapterID != 0 {
+			ch := p.chapters[item.ChapterID]
+			top[i] = slot{priority: ch.Priority, inPlace: ch.BookID == p.bookID}
+		} else {
+			pg := p.pages[item.PageID]
+			top[i] = slot{priority: pg.Priority, inPlace: pg.BookID == p.bookID && pg.ChapterID == 0}
+		}
+	}
+	for i, prio := range assignPriorities(top) {
+		item := layout[i]
+		if item.ChapterID != 0 {
+			ch := p.chapters[item.ChapterID]
+			chapters = appendChange(chapters, ReorderChange{
+				Type: EntityChapter, ID: ch.ID, Name: ch.Name,
+				FromBookID: ch.BookID, ToBookID: p.bookID,
+				FromPriority: ch.Priority, ToPriority: prio,
+			})
+			pages = append(pages, p.pageChanges(item.Pages, item.ChapterID)...)
+		} else {
+			pages = append(pages, p.pageChange(item.PageID, 0, prio)...)
+		}
+	}
+	return append(chapters, pages...)
+}
+
+// pageChanges computes the updates for the pages of a chapter.
+func (p *reorderPlan) pageChanges(ids []int, chapterID int) []ReorderChange {
+	seq := make([]slot, len(ids))
+	for i, id := range ids {
+		seq[i] = slot{priority: p.pages[id].Priority, inPlace: p.pages[id].ChapterID == chapterID}
+	}
+	var out []ReorderChange
+	for i, prio := range assignPriorities(seq) {
+		out = append(out, p.pageChange(ids[i], chapterID, prio)...)
+	}
+	return out
+}
+
+func (p *reorderPlan) pageChange(id, chapterID, prio int) []ReorderChange {
+	pg := p.pages[id]
+	return appendChange(nil, ReorderChange{
+		Type: EntityPage, ID: id, Name: pg.Name,
+		FromBookID: pg.BookID, ToBookID: p.bookID,
+		FromChapterID: pg.ChapterID, ToChapterID: chapterID,
+		FromPriority: pg.Priority, ToPriority: prio,
+	})
+}
+
+// appendChange appends c unless it changes nothing.
+func appendChange(out []ReorderChange, c ReorderChange) []ReorderChange {
+	if c.Moved() || c.FromPriority != c.ToPriority {
+		return append(out, c)
+	}
+	return out
+}
+
+// slot is an item in an ordered sequence: its current priority and whether
+// it already sits in the sequence's book or chapter.
+type slot struct {
+	priority int
+	inPlace  bool
+}
+
+// assignPriorities returns new priorities for seq, in order, changing as
+// few in-place items as possible. It keeps the longest run of in-place
+// items whose priorities already increase and fits the others into the
+// gaps between them; when the gaps are too small, it numbers from 1.
+func assignPriorities(seq []slot) []int {
+	renumbered := make([]int, len(seq))
+	for i := range renumbered {
+		renumbered[i] = i + 1
+	}
+	kept, ok := fitAroundKept(seq)
+	if !ok || updates(seq, kept) > updates(seq, renumbered) {
+		return renumbered
+	}
+	return kept
+}
+
+// updates counts the items of seq whose priority or parent would change.
+func updates(seq []slot, prios []int) int {
+	n := 0
+	for i, s := range seq {
+		if !s.inPlace || s.priority != prios[i] {
+			n++
+		}
+	}
+	return n
+}
+
+// fitAroundKept keeps the priorities of a longest increasing subsequence
+// of in-place items and assigns the others consecutive values in the gaps.
+// It reports false if some gap has no room.
+func fitAroundKept(seq []slot) ([]int, bool) {
+	keep := longestIncreasing(seq)
+	out := make([]int, len(seq))
+	prev := 0 // Priority of the last kept item; new priorities are at least 1
+	for i := 0; i < len(seq); {
+		if keep[i] {
+			out[i], prev = seq[i].priority, seq[i].priority
+			i++
+			continue
+		}
+		// Fill the run of items up to the next kept one.
+		j := i
+		for j < len(seq) && !keep[j] {
+			j++
+		}
+		if j < len(seq) && seq[j].priority-prev-1 < j-i {
+			return nil, false
+		}
+		for k := i; k < j; k++ {
+			prev++
+			out[k] = prev
+		}
+		i = j
+	}
+	return out, true
+}
+
+// longestIncreasing marks a longest subsequence of in-place items whose
+// priorities strictly increase and are at least 1.
+func longestIncreasing(seq []slot) []bool {
+	// tails[k] is the index of the smallest tail of an increasing run of
+	// length k+1; prev links each index to its predecessor in the run.
+	var tails []int
+	prev := make([]int, len(seq))
+	for i, s := range seq {
+		prev[i] = -1
+		if !s.inPlace || s.priority < 1 {
+			continue
+		}
+		k, _ := slices.BinarySearchFunc(tails, s.priority, func(t, p int) int { return seq[t].priority - p })
+		if k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	keep := make([]bool, len(seq))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+			keep[i] = true
+		}
+	}
+	return keep
+}
@@ -0,0 +1,106 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAssignPriorities(t *testing.T) {
+	tests := []struct {
+		name string
+		seq  []slot
+		want []int
+	}{
+		{"unchanged", []slot{{1, true}, {2, true}, {3, true}}, []int{1, 2, 3}},
+		{"move last to front", []slot{{3, true}, {1, true}, {2, true}}, []int{1, 2, 3}},
+		{"fits in gap", []slot{{0, true}, {5, true}, {10, true}}, []int{1, 5, 10}},
+		{"keeps sparse priorities", []slot{{10, true}, {30, true}, {20, true}}, []int{10, 11, 20}},
+		{"new item between", []slot{{2, true}, {7, false}, {4, true}}, []int{2, 3, 4}},
+		{"no room", []slot{{1, true}, {7, false}, {2, true}}, []int{1, 2, 3}},
+		{"ties", []slot{{1, true}, {1, true}, {1, true}}, []int{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := assignPriorities(tt.seq); !slices.Equal(got, tt.want) {
+				t.Errorf("assignPriorities(%v) = %v, want %v", tt.seq, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBooksService_Reorder(t *testing.T) {
+	var updates []string
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/chapters":
+			json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+				{"id": 10, "book_id": 1, "name": "Setup", "priority": 1},
+			}, "total": 1})
+		case r.Method == "GET" && r.URL.Path == "/api/pages":
+			json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+				{"id": 20, "book_id": 1, "chapter_id": 10, "name": "Install", "priority": 1},
+				{"id": 21, "book_id": 1, "chapter_id": 10, "name": "Configure", "priority": 2},
+				{"id": 22, "book_id": 1, "name": "Intro", "priority": 2},
+			}, "total": 3})
+		case r.Method == "PUT":
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			b, _ := json.Marshal(body)
+			updates = append(updates, r.URL.Path+" "+string(b))
+			json.NewEncoder(w).Encode(map[string]any{"id": 1})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+	ctx := context.Background()
+
+	// Intro moves before the chapter, and Configure out of it to the end.
+	layout := []LayoutItem{
+		{PageID: 22},
+		{ChapterID: 10, Pages: []int{20}},
+		{PageID: 21},
+	}
+	changes, err := c.Books.Reorder(ctx, 1, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`/api/chapters/10 {"priority":2}`,
+		`/api/pages/22 {"priority":1}`,
+		`/api/pages/21 {"book_id":1,"priority":3}`,
+	}
+	if !slices.Equal(updates, want) {
+		t.Errorf("updates = %q, want %q", updates, want)
+	}
+	if len(changes) != 3 || !changes[2].Moved() || changes[2].FromChapterID != 10 || changes[2].ToChapterID != 0 {
+		t.Errorf("changes = %+v", changes)
+	}
+
+	// A layout that is already in place makes no updates.
+	updates = nil
+	changes, err = c.Books.PlanReorder(ctx, 1, []LayoutItem{{ChapterID: 10, Pages: []int{20, 21}}, {PageID: 22}})
+	if err != nil || len(changes) != 0 {
+		t.Errorf("PlanReorder = %+v, %v", changes, err)
+	}
+
+	for _, tt := range []struct {
+		layout []LayoutItem
+		err    string
+	}{
+		{[]LayoutItem{{ChapterID: 10, Pages: []int{20, 21}}}, "does not list [page 22]"},
+		{[]LayoutItem{{ChapterID: 10, Pages: []int{20, 21, 22}}, {PageID: 22}}, "more than once"},
+		{[]LayoutItem{{ChapterID: 10, PageID: 22}}, "exactly one"},
+		{[]LayoutItem{{PageID: 22, Pages: []int{20}}}, "cannot have pages"},
+	} {
+		if _, err := c.Books.PlanReorder(ctx, 1, tt.layout); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("PlanReorder(%+v) error = %v, want %q", tt.layout, err, tt.err)
+		}
+	}
+	if len(updates) != 0 {
+		t.Errorf("PlanReorder made updates: %q", updates)
+	}
+}
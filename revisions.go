@@ -0,0 +1,61 @@
+package bookstack
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// RevisionsService handles the saved revisions of pages.
+//
+// Revisions are read from /api/pages/{id}/revisions. Instances whose API
+// does not expose page revisions respond with ErrNotFound.
+type RevisionsService struct {
+	client *Client
+}
+
+// List returns a page's revisions with optional sorting and paging.
+// Listed revisions do not include their content.
+func (s *RevisionsService) List(ctx context.Context, pageID int, opts *ListOptions) ([]Revision, error) {
+	var resp listResponse[Revision]
+	err := s.client.do(ctx, "GET", fmt.Sprintf("/api/pages/%d/revisions", pageID)+opts.queryString(), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// ListAll returns an iterator over all revisions of a page, handling pagination automatically.
+func (s *RevisionsService) ListAll(ctx context.Context, pageID int) iter.Seq2[Revision, error] {
+	return listAll[Revision](ctx, s.client, fmt.Sprintf("/api/pages/%d/revisions", pageID), nil)
+}
+
+// Get retrieves a single revision of a page, including its content.
+func (s *RevisionsService) Get(ctx context.Context, pageID, revisionID int) (*Revision, error) {
+	var r Revision
+	err := s.client.do(ctx, "GET", fmt.Sprintf("/api/pages/%d/revisions/%d", pageID, revisionID), nil, &r)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Diff compares two revisions of a page. A toID of 0 compares against the
+// current page content instead of a revision.
+func (s *RevisionsService) Diff(ctx context.Context, pageID, fromID, toID int, format DiffFormat) (*Diff, error) {
+	from, err := s.Get(ctx, pageID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	var to *Revision
+	if toID == 0 {
+		page, err := s.client.Pages.Get(ctx, pageID)
+		if err != nil {
+			return nil, err
+		}
+		to = &Revision{PageID: page.ID, Name: page.Name, HTML: page.HTML, Markdown: page.Markdown}
+	} else if to, err = s.Get(ctx, pageID, toID); err != nil {
+		return nil, err
+	}
+	return DiffRevisions(from, to, format), nil
+}
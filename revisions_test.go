@@ -0,0 +1,84 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestRevisionsService_List(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/pages/5/revisions" {
+			t.Errorf("path = %s, want /api/pages/5/revisions", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("sort"); got != "-created_at" {
+			t.Errorf("sort = %q", got)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{
+				{"id": 12, "page_id": 5, "revision_number": 3, "summary": "Fix typo", "created_by": 2, "created_at": "2024-03-01T10:00:00.000000Z"},
+			},
+			"total": 1,
+		})
+	})
+
+	revs, err := c.Revisions.List(context.Background(), 5, &ListOptions{Sort: "-created_at"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(revs) != 1 || revs[0].Number != 3 || revs[0].Summary != "Fix typo" || revs[0].CreatedBy != 2 || revs[0].CreatedAt.IsZero() {
+		t.Errorf("got %+v", revs)
+	}
+}
+
+func TestRevisionsService_Get(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/pages/5/revisions/12" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 12, "page_id": 5, "markdown": "# Old"})
+	})
+
+	rev, err := c.Revisions.Get(context.Background(), 5, 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rev.ID != 12 || rev.Markdown != "# Old" {
+		t.Errorf("got %+v", rev)
+	}
+}
+
+func TestRevisionsService_DiffCurrent(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/pages/5/revisions/12":
+			json.NewEncoder(w).Encode(map[string]any{"id": 12, "revision_number": 2, "markdown": "# Deploy\n\nRun make.\n"})
+		case "/api/pages/5":
+			json.NewEncoder(w).Encode(map[string]any{"id": 5, "markdown": "# Deploy\n\nRun make deploy.\n"})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	d, err := c.Revisions.Diff(context.Background(), 5, 12, 0, DiffMarkdown)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "--- revision 2\n+++ current\n@@ -1,3 +1,3 @@\n # Deploy\n \n-Run make.\n+Run make deploy.\n"
+	if got := d.Unified(3); got != want {
+		t.Errorf("Unified =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRevisionsService_NotFound(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"message": "Route not found"}})
+	})
+
+	if _, err := c.Revisions.List(context.Background(), 5, nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
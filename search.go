@@ -2,6 +2,7 @@ package bookstack
 
 import (
 	"context"
+	"iter"
 	"net/url"
 	"strconv"
 )
@@ -11,6 +12,13 @@ type SearchService struct {
 	client *Client
 }
 
+// SearchResponse is a single page of search results together with the
+// total number of matches reported by the API.
+type SearchResponse struct {
+	Results []SearchResult `json:"data"`
+	Total   int            `json:"total"`
+}
+
 // Search performs a full-text search query across all content types.
 // The query parameter uses Bookstack's search syntax.
 func (s *SearchService) Search(ctx context.Context, query string, opts *ListOptions) ([]SearchResult, error) {
@@ -32,3 +40,35 @@ func (s *SearchService) Search(ctx context.Context, query string, opts *ListOpti
 	}
 	return resp.Data, nil
 }
+
+// SearchPage returns one page of search results along with the total number
+// of matches. page is 1-based; count is the number of results per page
+// (the API default is used when count is zero).
+func (s *SearchService) SearchPage(ctx context.Context, query string, page, count int) (*SearchResponse, error) {
+	var resp SearchResponse
+	err := s.client.do(ctx, "GET", searchPath(query, page, count), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SearchAll returns an iterator over all results for query, handling pagination automatically.
+func (s *SearchService) SearchAll(ctx context.Context, query string) iter.Seq2[SearchResult, error] {
+	return paginate[SearchResult](ctx, s.client, func(n, _ int) string {
+		return searchPath(query, n+1, defaultPageSize)
+	})
+}
+
+// searchPath builds the search endpoint path using page/count semantics.
+func searchPath(query string, page, count int) string {
+	v := url.Values{}
+	v.Set("query", query)
+	if page > 0 {
+		v.Set("page", strconv.Itoa(page))
+	}
+	if count > 0 {
+		v.Set("count", strconv.Itoa(count))
+	}
+	return "/api/search?" + v.Encode()
+}
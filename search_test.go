@@ -56,3 +56,69 @@ func TestSearchService_Search_WithOptions(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestSearchService_SearchPage(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("page") != "3" {
+			t.Errorf("page = %q, want 3", q.Get("page"))
+		}
+		if q.Get("count") != "20" {
+			t.Errorf("count = %q, want 20", q.Get("count"))
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"data":  []map[string]any{{"id": 1, "name": "Result", "type": "page"}},
+			"total": 1342,
+		})
+	})
+
+	resp, err := c.Search.SearchPage(context.Background(), "test", 3, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Total != 1342 {
+		t.Errorf("Total = %d, want 1342", resp.Total)
+	}
+	if len(resp.Results) != 1 {
+		t.Errorf("got %d results, want 1", len(resp.Results))
+	}
+}
+
+func TestSearchService_SearchAll(t *testing.T) {
+	callCount := 0
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		q := r.URL.Query()
+		if q.Get("query") != "deploy" {
+			t.Errorf("query = %q, want deploy", q.Get("query"))
+		}
+		switch q.Get("page") {
+		case "1":
+			json.NewEncoder(w).Encode(map[string]any{
+				"data":  []map[string]any{{"id": 1, "type": "page"}, {"id": 2, "type": "book"}},
+				"total": 3,
+			})
+		case "2":
+			json.NewEncoder(w).Encode(map[string]any{
+				"data":  []map[string]any{{"id": 3, "type": "chapter"}},
+				"total": 3,
+			})
+		default:
+			t.Errorf("unexpected page %q", q.Get("page"))
+		}
+	})
+
+	var results []SearchResult
+	for r, err := range c.Search.SearchAll(context.Background(), "deploy") {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		results = append(results, r)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if callCount != 2 {
+		t.Errorf("API called %d times, want 2", callCount)
+	}
+}
@@ -23,7 +23,12 @@ func (s *ShelvesService) List(ctx context.Context, opts *ListOptions) ([]Shelf,
 
 // ListAll returns an iterator over all shelves, handling pagination automatically.
 func (s *ShelvesService) ListAll(ctx context.Context) iter.Seq2[Shelf, error] {
-	return listAll[Shelf](ctx, s.client, "/api/shelves")
+	return listAll[Shelf](ctx, s.client, "/api/shelves", nil)
+}
+
+// ListAllWith returns an iterator over all shelves matching opts, handling pagination automatically.
+func (s *ShelvesService) ListAllWith(ctx context.Context, opts *ListOptions) iter.Seq2[Shelf, error] {
+	return listAll[Shelf](ctx, s.client, "/api/shelves", opts)
 }
 
 // Get retrieves a single shelf by ID.
@@ -35,3 +40,43 @@ func (s *ShelvesService) Get(ctx context.Context, id int) (*Shelf, error) {
 	}
 	return &shelf, nil
 }
+
+// GetBySlug retrieves a single shelf by its URL slug.
+func (s *ShelvesService) GetBySlug(ctx context.Context, slug string) (*Shelf, error) {
+	return getBySlug(ctx, s.client, "shelf:"+slug, func() (int, error) {
+		shelves, err := s.List(ctx, slugFilter(slug, 0))
+		if err != nil {
+			return 0, err
+		}
+		ids := make([]int, len(shelves))
+		for i, sh := range shelves {
+			ids[i] = sh.ID
+		}
+		return singleMatch("shelf", slug, ids)
+	}, s.Get, func(sh *Shelf) bool { return sh.Slug == slug })
+}
+
+// Create creates a new shelf.
+func (s *ShelvesService) Create(ctx context.Context, req *ShelfCreateRequest) (*Shelf, error) {
+	var shelf Shelf
+	err := s.client.do(ctx, "POST", "/api/shelves", req, &shelf)
+	if err != nil {
+		return nil, err
+	}
+	return &shelf, nil
+}
+
+// Update updates an existing shelf.
+func (s *ShelvesService) Update(ctx context.Context, id int, req *ShelfUpdateRequest) (*Shelf, error) {
+	var shelf Shelf
+	err := s.client.do(ctx, "PUT", fmt.Sprintf("/api/shelves/%d", id), req, &shelf)
+	if err != nil {
+		return nil, err
+	}
+	return &shelf, nil
+}
+
+// Delete deletes a shelf by ID. The books on it are not deleted.
+func (s *ShelvesService) Delete(ctx context.Context, id int) error {
+	return s.client.do(ctx, "DELETE", fmt.Sprintf("/api/shelves/%d", id), nil, nil)
+}
@@ -60,3 +60,52 @@ func TestShelvesService_Get_NotFound(t *testing.T) {
 		t.Errorf("expected ErrNotFound, got %v", err)
 	}
 }
+
+func TestShelvesService_Create(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/shelves" {
+			t.Errorf("%s %s, want POST /api/shelves", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if books, _ := body["books"].([]any); len(books) != 2 {
+			t.Errorf("books = %v", body["books"])
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 3, "name": "Team"})
+	})
+
+	shelf, err := c.Shelves.Create(context.Background(), &ShelfCreateRequest{Name: "Team", Books: []int{4, 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shelf.ID != 3 {
+		t.Errorf("ID = %d, want 3", shelf.ID)
+	}
+}
+
+func TestShelvesService_UpdateDelete(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/shelves/3" {
+			t.Errorf("path = %s, want /api/shelves/3", r.URL.Path)
+		}
+		switch r.Method {
+		case "PUT":
+			json.NewEncoder(w).Encode(map[string]any{"id": 3, "name": "Renamed"})
+		case "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	})
+
+	shelf, err := c.Shelves.Update(context.Background(), 3, &ShelfUpdateRequest{Name: "Renamed"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if shelf.Name != "Renamed" {
+		t.Errorf("Name = %q", shelf.Name)
+	}
+	if err := c.Shelves.Delete(context.Background(), 3); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+}
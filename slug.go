@@ -0,0 +1,115 @@
+package bookstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// slugCache remembers slug to ID lookups made by the GetBySlug methods.
+// The zero value is ready to use.
+type slugCache struct {
+	mu  sync.Mutex
+	ids map[string]int
+}
+
+func (sc *slugCache) get(key string) (int, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	id, ok := sc.ids[key]
+	return id, ok
+}
+
+func (sc *slugCache) set(key string, id int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.ids == nil {
+		sc.ids = make(map[string]int)
+	}
+	sc.ids[key] = id
+}
+
+func (sc *slugCache) delete(key string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	delete(sc.ids, key)
+}
+
+// getBySlug resolves key to an ID, using the client's slug cache when possible,
+// and fetches the item with get. A cached ID that no longer exists, or whose
+// item no longer has the slug according to match, is evicted and looked up
+// again.
+func getBySlug[T any](ctx context.Context, c *Client, key string, find func() (int, error), get func(context.Context, int) (T, error), match func(T) bool) (T, error) {
+	if id, ok := c.slugs.get(key); ok {
+		item, err := get(ctx, id)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return item, err
+		}
+		if err == nil && match(item) {
+			return item, nil
+		}
+		c.slugs.delete(key)
+	}
+
+	id, err := c.slugID(key, find)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return get(ctx, id)
+}
+
+// slugID returns the cached ID for key or calls find and caches the result.
+func (c *Client) slugID(key string, find func() (int, error)) (int, error) {
+	if id, ok := c.slugs.get(key); ok {
+		return id, nil
+	}
+	id, err := find()
+	if err != nil {
+		return 0, err
+	}
+	c.slugs.set(key, id)
+	return id, nil
+}
+
+// singleMatch returns the only ID in ids, or an error describing why the
+// slug lookup did not produce exactly one match.
+func singleMatch(kind, slug string, ids []int) (int, error) {
+	switch len(ids) {
+	case 0:
+		return 0, fmt.Errorf("%s with slug %q: %w", kind, slug, ErrNotFound)
+	case 1:
+		return ids[0], nil
+	default:
+		return 0, fmt.Errorf("%s slug %q is ambiguous (%d matches); specify the book slug", kind, slug, len(ids))
+	}
+}
+
+// slugFilter builds list options that match slug, optionally restricted to a book.
+func slugFilter(slug string, bookID int) *ListOptions {
+	opts := &ListOptions{Count: 2, Filter: map[string]string{"slug": slug}}
+	if bookID > 0 {
+		opts.Filter["book_id"] = strconv.Itoa(bookID)
+	}
+	return opts
+}
+
+// bookIDBySlug resolves a book slug to its ID. An empty slug yields 0.
+func (c *Client) bookIDBySlug(ctx context.Context, slug string) (int, error) {
+	if slug == "" {
+		return 0, nil
+	}
+	return c.slugID("book:"+slug, func() (int, error) {
+		books, err := c.Books.List(ctx, slugFilter(slug, 0))
+		if err != nil {
+			return 0, err
+		}
+		ids := make([]int, len(books))
+		for i, b := range books {
+			ids[i] = b.ID
+		}
+		return singleMatch("book", slug, ids)
+	})
+}
@@ -0,0 +1,148 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestPagesService_GetBySlug(t *testing.T) {
+	lookups := 0
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/books":
+			lookups++
+			if q.Get("filter[slug]") != "ops" {
+				t.Errorf("book filter[slug] = %q, want ops", q.Get("filter[slug]"))
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": 3}}, "total": 1})
+		case "/api/pages":
+			lookups++
+			if q.Get("filter[slug]") != "deploy" {
+				t.Errorf("page filter[slug] = %q, want deploy", q.Get("filter[slug]"))
+			}
+			if q.Get("filter[book_id]") != "3" {
+				t.Errorf("filter[book_id] = %q, want 3", q.Get("filter[book_id]"))
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": 42}}, "total": 1})
+		case "/api/pages/42":
+			json.NewEncoder(w).Encode(map[string]any{"id": 42, "slug": "deploy", "html": "<p>Deploy</p>"})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	for range 2 {
+		page, err := c.Pages.GetBySlug(context.Background(), "ops", "deploy")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if page.ID != 42 {
+			t.Errorf("ID = %d, want 42", page.ID)
+		}
+	}
+	if lookups != 2 {
+		t.Errorf("list endpoints called %d times, want 2 (second lookup cached)", lookups)
+	}
+}
+
+func TestBooksService_GetBySlug_NotFound(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"data": []any{}, "total": 0})
+	})
+
+	_, err := c.Books.GetBySlug(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestChaptersService_GetBySlug_Ambiguous(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("filter[book_id]") {
+			t.Error("unexpected book filter without book slug")
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"data":  []map[string]any{{"id": 1}, {"id": 2}},
+			"total": 2,
+		})
+	})
+
+	_, err := c.Chapters.GetBySlug(context.Background(), "", "intro")
+	if err == nil {
+		t.Fatal("expected error for ambiguous slug")
+	}
+}
+
+func TestShelvesService_GetBySlug_StaleCache(t *testing.T) {
+	current := 1
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/shelves":
+			json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": current}}, "total": 1})
+		case "/api/shelves/1":
+			if current != 1 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": 1})
+		case "/api/shelves/2":
+			json.NewEncoder(w).Encode(map[string]any{"id": 2})
+		}
+	})
+
+	ctx := context.Background()
+	if _, err := c.Shelves.GetBySlug(ctx, "team"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	current = 2
+	shelf, err := c.Shelves.GetBySlug(ctx, "team")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shelf.ID != 2 {
+		t.Errorf("ID = %d, want 2 after stale cache entry", shelf.ID)
+	}
+}
+
+func TestBooksService_GetBySlug_Renamed(t *testing.T) {
+	slugs := map[int]string{1: "handbook"}
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/books":
+			for id, slug := range slugs {
+				if slug == r.URL.Query().Get("filter[slug]") {
+					json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": id}}, "total": 1})
+					return
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": []any{}, "total": 0})
+		case "/api/books/1", "/api/books/2":
+			id := int(r.URL.Path[len(r.URL.Path)-1] - '0')
+			json.NewEncoder(w).Encode(map[string]any{"id": id, "slug": slugs[id]})
+		}
+	})
+
+	ctx := context.Background()
+	if _, err := c.Books.GetBySlug(ctx, "handbook"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Book 1 is renamed and a new book takes over its old slug.
+	slugs[1], slugs[2] = "old-handbook", "handbook"
+	book, err := c.Books.GetBySlug(ctx, "handbook")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if book.ID != 2 || book.Slug != "handbook" {
+		t.Errorf("got book %d %q, want 2 after rename", book.ID, book.Slug)
+	}
+
+	// Without a new owner of the slug, the renamed book is not returned.
+	delete(slugs, 2)
+	if _, err := c.Books.GetBySlug(ctx, "handbook"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after rename, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package bookstack
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"iter"
+	"maps"
+	"regexp"
+	"time"
+)
+
+// TemplatesService handles pages marked as templates.
+//
+// Marking a page as a template requires the "templates-manage" permission.
+// Bookstack ignores the change for users without it, which Mark and Unmark
+// report as ErrForbidden.
+type TemplatesService struct {
+	client *Client
+}
+
+// TemplateRequest describes a page to create from a template.
+type TemplateRequest struct {
+	BookID    int    // Book of the new page; ignored if ChapterID is set
+	ChapterID int    // Chapter of the new page
+	Name      string // Name of the new page; the template's name if empty
+	Tags      []Tag  // Added to the template's tags
+
+	Author string            // Value of {{author}}
+	Vars   map[string]string // Further variables; these override the built-in ones
+	Now    time.Time         // Time used for {{date}}, {{time}} and {{datetime}}; now if zero
+}
+
+// vars returns the variables for the request, except {{name}}.
+func (r *TemplateRequest) vars() map[string]string {
+	now := r.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	vars := map[string]string{
+		"date":     now.Format(time.DateOnly),
+		"time":     now.Format("15:04"),
+		"datetime": now.Format("2006-01-02 15:04"),
+	}
+	if r.Author != "" {
+		vars["author"] = r.Author
+	}
+	maps.Copy(vars, r.Vars)
+	return vars
+}
+
+// List returns pages marked as templates, with optional filtering, sorting and paging.
+func (s *TemplatesService) List(ctx context.Context, opts *ListOptions) ([]Page, error) {
+	return s.client.Pages.List(ctx, opts.withFilter("template", "1"))
+}
+
+// ListAll returns an iterator over all template pages, handling pagination automatically.
+func (s *TemplatesService) ListAll(ctx context.Context) iter.Seq2[Page, error] {
+	return s.client.Pages.ListAllWith(ctx, (*ListOptions)(nil).withFilter("template", "1"))
+}
+
+// Mark makes a page available as a template.
+func (s *TemplatesService) Mark(ctx context.Context, pageID int) (*Page, error) {
+	return s.setTemplate(ctx, pageID, true)
+}
+
+// Unmark stops a page from being offered as a template.
+func (s *TemplatesService) Unmark(ctx context.Context, pageID int) (*Page, error) {
+	return s.setTemplate(ctx, pageID, false)
+}
+
+func (s *TemplatesService) setTemplate(ctx context.Context, pageID int, template bool) (*Page, error) {
+	// Bookstack compares the field with the string "true", as sent by its
+	// own page editor form.
+	body := map[string]string{"template": fmt.Sprint(template)}
+	var page Page
+	if err := s.client.do(ctx, "PUT", fmt.Sprintf("/api/pages/%d", pageID), body, &page); err != nil {
+		return nil, err
+	}
+	if page.Template != template {
+		return nil, fmt.Errorf("%w: template status of page %d was not changed; the templates-manage permission is required", ErrForbidden, pageID)
+	}
+	return &page, nil
+}
+
+// Instantiate creates a page from the template page templateID, expanding
+// variables in its name and content. The template's Markdown is used if it
+// has any, its HTML otherwise.
+//
+// Built-in variables are {{date}}, {{time}}, {{datetime}}, {{name}} (the
+// new page's name) and {{author}}; see ExpandTemplate for the syntax.
+func (s *TemplatesService) Instantiate(ctx context.Context, templateID int, req *TemplateRequest) (*Page, error) {
+	tmpl, err := s.client.Pages.Get(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	name := req.Name
+	if name == "" {
+		name = tmpl.Name
+	}
+	vars := req.vars()
+	name = ExpandTemplate(name, vars)
+	if _, ok := vars["name"]; !ok {
+		vars["name"] = name
+	}
+
+	create := &PageCreateRequest{
+		BookID:    req.BookID,
+		ChapterID: req.ChapterID,
+		Name:      name,
+		Tags:      append(append([]Tag(nil), tmpl.Tags...), req.Tags...),
+	}
+	if tmpl.Markdown != "" {
+		create.Markdown = ExpandTemplate(tmpl.Markdown, vars)
+	} else {
+		create.HTML = ExpandTemplateHTML(tmpl.HTML, vars)
+	}
+	return s.client.Pages.Create(ctx, create)
+}
+
+var templateVar = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// ExpandTemplate replaces {{name}} placeholders in text with their values
+// in vars. Spaces inside the braces are allowed, and placeholders without
+// a value are left unchanged.
+func ExpandTemplate(text string, vars map[string]string) string {
+	return expandTemplate(text, vars, func(s string) string { return s })
+}
+
+// ExpandTemplateHTML is like ExpandTemplate, but escapes the values for
+// use in HTML.
+func ExpandTemplateHTML(text string, vars map[string]string) string {
+	return expandTemplate(text, vars, html.EscapeString)
+}
+
+func expandTemplate(text string, vars map[string]string, escape func(string) string) string {
+	return templateVar.ReplaceAllStringFunc(text, func(m string) string {
+		name := templateVar.FindStringSubmatch(m)[1]
+		if v, ok := vars[name]; ok {
+			return escape(v)
+		}
+		return m
+	})
+}
@@ -0,0 +1,108 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	vars := map[string]string{"date": "2024-05-01", "author": "Ana <ops>"}
+	tests := []struct {
+		in, want, wantHTML string
+	}{
+		{"Notes {{date}}", "Notes 2024-05-01", "Notes 2024-05-01"},
+		{"By {{ author }}", "By Ana <ops>", "By Ana &lt;ops&gt;"},
+		{"{{unknown}} {date}", "{{unknown}} {date}", "{{unknown}} {date}"},
+		{"{{date}}{{date}}", "2024-05-012024-05-01", "2024-05-012024-05-01"},
+	}
+	for _, tt := range tests {
+		if got := ExpandTemplate(tt.in, vars); got != tt.want {
+			t.Errorf("ExpandTemplate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if got := ExpandTemplateHTML(tt.in, vars); got != tt.wantHTML {
+			t.Errorf("ExpandTemplateHTML(%q) = %q, want %q", tt.in, got, tt.wantHTML)
+		}
+	}
+}
+
+func TestTemplatesService_List(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/pages" || q.Get("filter[template]") != "1" || q.Get("filter[book_id]") != "3" {
+			t.Errorf("request = %s", r.URL)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": 1, "template": true}}, "total": 1})
+	})
+	opts := &ListOptions{Filter: map[string]string{"book_id": "3"}}
+	pages, err := c.Templates.List(context.Background(), opts)
+	if err != nil || len(pages) != 1 || !pages[0].Template {
+		t.Fatalf("List = %+v, %v", pages, err)
+	}
+	if len(opts.Filter) != 1 {
+		t.Errorf("caller's filter was modified: %v", opts.Filter)
+	}
+}
+
+func TestTemplatesService_Mark(t *testing.T) {
+	applied := true
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.Method != "PUT" || r.URL.Path != "/api/pages/7" || body["template"] != "true" {
+			t.Errorf("%s %s %v", r.Method, r.URL.Path, body)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 7, "template": applied})
+	})
+	ctx := context.Background()
+	if page, err := c.Templates.Mark(ctx, 7); err != nil || !page.Template {
+		t.Fatalf("Mark = %+v, %v", page, err)
+	}
+	applied = false
+	if _, err := c.Templates.Mark(ctx, 7); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Mark without permission: err = %v, want ErrForbidden", err)
+	}
+}
+
+func TestTemplatesService_Instantiate(t *testing.T) {
+	var created PageCreateRequest
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/pages/5":
+			json.NewEncoder(w).Encode(map[string]any{
+				"id": 5, "name": "Meeting {{date}}", "template": true,
+				"html":     "<h1>{{name}}</h1><p>{{author}}, {{customer}}</p>",
+				"markdown": "# {{name}}\n\nBy {{author}} for {{customer}} at {{time}}",
+				"tags":     []map[string]any{{"name": "type", "value": "meeting"}},
+			})
+		case r.Method == "POST" && r.URL.Path == "/api/pages":
+			json.NewDecoder(r.Body).Decode(&created)
+			json.NewEncoder(w).Encode(map[string]any{"id": 9, "name": created.Name})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	page, err := c.Templates.Instantiate(context.Background(), 5, &TemplateRequest{
+		BookID: 2,
+		Author: "Ana",
+		Vars:   map[string]string{"customer": "ACME"},
+		Tags:   []Tag{{Name: "customer", Value: "acme"}},
+		Now:    time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.ID != 9 || created.Name != "Meeting 2024-05-01" || created.BookID != 2 {
+		t.Errorf("created = %+v", created)
+	}
+	if want := "# Meeting 2024-05-01\n\nBy Ana for ACME at 09:30"; created.Markdown != want || created.HTML != "" {
+		t.Errorf("markdown = %q, want %q", created.Markdown, want)
+	}
+	if len(created.Tags) != 2 {
+		t.Errorf("tags = %+v", created.Tags)
+	}
+}
@@ -1,6 +1,9 @@
 package bookstack
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Book represents a Bookstack book.
 type Book struct {
@@ -13,6 +16,7 @@ type Book struct {
 	CreatedBy   int       `json:"created_by"`
 	UpdatedBy   int       `json:"updated_by"`
 	OwnedBy     int       `json:"owned_by"`
+	Tags        []Tag     `json:"tags,omitempty"`
 }
 
 // Page represents a Bookstack page.
@@ -20,6 +24,7 @@ type Page struct {
 	ID        int       `json:"id"`
 	BookID    int       `json:"book_id"`
 	ChapterID int       `json:"chapter_id"`
+	BookSlug  string    `json:"book_slug"`
 	Name      string    `json:"name"`
 	Slug      string    `json:"slug"`
 	HTML      string    `json:"html"`
@@ -31,14 +36,16 @@ type Page struct {
 	UpdatedBy int       `json:"updated_by"`
 	Draft     bool      `json:"draft"`
 	Revision  int       `json:"revision_count"`
-	Template  bool      `json:"template"`
+	Template  bool      `json:"template"` // See TemplatesService
 	OwnedBy   int       `json:"owned_by"`
+	Tags      []Tag     `json:"tags,omitempty"`
 }
 
 // Chapter represents a Bookstack chapter.
 type Chapter struct {
 	ID          int       `json:"id"`
 	BookID      int       `json:"book_id"`
+	BookSlug    string    `json:"book_slug"`
 	Name        string    `json:"name"`
 	Slug        string    `json:"slug"`
 	Description string    `json:"description"`
@@ -48,6 +55,7 @@ type Chapter struct {
 	CreatedBy   int       `json:"created_by"`
 	UpdatedBy   int       `json:"updated_by"`
 	OwnedBy     int       `json:"owned_by"`
+	Tags        []Tag     `json:"tags,omitempty"`
 }
 
 // Shelf represents a Bookstack shelf.
@@ -61,6 +69,106 @@ type Shelf struct {
 	CreatedBy   int       `json:"created_by"`
 	UpdatedBy   int       `json:"updated_by"`
 	OwnedBy     int       `json:"owned_by"`
+	Tags        []Tag     `json:"tags,omitempty"`
+	Books       []Book    `json:"books,omitempty"` // Only populated by Get
+}
+
+// BookCreateRequest contains fields for creating a new book.
+type BookCreateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Tags        []Tag  `json:"tags,omitempty"`
+}
+
+// BookUpdateRequest contains fields for updating an existing book.
+// Fields left nil are not changed: a pointer to "" clears Description and
+// an empty non-nil Tags removes all tags.
+type BookUpdateRequest struct {
+	Name        string  `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Tags        []Tag   `json:"tags,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, sending Tags whenever it is non-nil.
+func (r BookUpdateRequest) MarshalJSON() ([]byte, error) {
+	type plain BookUpdateRequest
+	if r.Tags == nil {
+		return json.Marshal(plain(r))
+	}
+	return json.Marshal(struct {
+		plain
+		Tags []Tag `json:"tags"`
+	}{plain(r), r.Tags})
+}
+
+// ChapterCreateRequest contains fields for creating a new chapter.
+type ChapterCreateRequest struct {
+	BookID      int    `json:"book_id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Priority    int    `json:"priority,omitempty"`
+	Tags        []Tag  `json:"tags,omitempty"`
+}
+
+// ChapterUpdateRequest contains fields for updating an existing chapter.
+// Fields left nil are not changed: a pointer to "" clears Description and
+// an empty non-nil Tags removes all tags.
+type ChapterUpdateRequest struct {
+	BookID      int     `json:"book_id,omitempty"` // Moves the chapter and its pages to this book
+	Name        string  `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Priority    int     `json:"priority,omitempty"`
+	Tags        []Tag   `json:"tags,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, sending Tags whenever it is non-nil.
+func (r ChapterUpdateRequest) MarshalJSON() ([]byte, error) {
+	type plain ChapterUpdateRequest
+	if r.Tags == nil {
+		return json.Marshal(plain(r))
+	}
+	return json.Marshal(struct {
+		plain
+		Tags []Tag `json:"tags"`
+	}{plain(r), r.Tags})
+}
+
+// ShelfCreateRequest contains fields for creating a new shelf.
+type ShelfCreateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Books       []int  `json:"books,omitempty"` // Book IDs in display order
+	Tags        []Tag  `json:"tags,omitempty"`
+}
+
+// ShelfUpdateRequest contains fields for updating an existing shelf.
+// A non-nil Books replaces the shelf's books, so an empty one removes them
+// all; likewise for Tags.
+type ShelfUpdateRequest struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Books       []int  `json:"books,omitempty"`
+	Tags        []Tag  `json:"tags,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, sending Books and Tags whenever
+// they are non-nil.
+func (r ShelfUpdateRequest) MarshalJSON() ([]byte, error) {
+	type plain ShelfUpdateRequest
+	return json.Marshal(struct {
+		plain
+		Books *[]int `json:"books,omitempty"`
+		Tags  *[]Tag `json:"tags,omitempty"`
+	}{plain(r), setSlice(r.Books), setSlice(r.Tags)})
+}
+
+// setSlice returns a pointer to s, or nil if s is nil, so that omitempty
+// only leaves out unset slices.
+func setSlice[T any](s []T) *[]T {
+	if s == nil {
+		return nil
+	}
+	return &s
 }
 
 // PageCreateRequest contains fields for creating a new page.
@@ -70,13 +178,50 @@ type PageCreateRequest struct {
 	Name      string `json:"name"`
 	HTML      string `json:"html,omitempty"`
 	Markdown  string `json:"markdown,omitempty"`
+	Priority  int    `json:"priority,omitempty"`
+	Tags      []Tag  `json:"tags,omitempty"`
 }
 
 // PageUpdateRequest contains fields for updating an existing page.
+// BookID and ChapterID move the page; see PagesService.Move. Tags is sent
+// when it is non-nil, so an empty slice removes all tags.
 type PageUpdateRequest struct {
-	Name     string `json:"name,omitempty"`
-	HTML     string `json:"html,omitempty"`
-	Markdown string `json:"markdown,omitempty"`
+	BookID    int    `json:"book_id,omitempty"`    // Moves the page to the top level of this book
+	ChapterID int    `json:"chapter_id,omitempty"` // Moves the page into this chapter
+	Name      string `json:"name,omitempty"`
+	HTML      string `json:"html,omitempty"`
+	Markdown  string `json:"markdown,omitempty"`
+	Priority  int    `json:"priority,omitempty"`
+	Tags      []Tag  `json:"tags,omitempty"`
+	Summary   string `json:"summary,omitempty"` // Revision summary shown in the page history
+}
+
+// MarshalJSON implements json.Marshaler, sending Tags whenever it is non-nil.
+func (r PageUpdateRequest) MarshalJSON() ([]byte, error) {
+	type plain PageUpdateRequest
+	if r.Tags == nil {
+		return json.Marshal(plain(r))
+	}
+	return json.Marshal(struct {
+		plain
+		Tags []Tag `json:"tags"`
+	}{plain(r), r.Tags})
+}
+
+// Revision is a saved version of a page.
+type Revision struct {
+	ID        int       `json:"id"`
+	PageID    int       `json:"page_id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	BookSlug  string    `json:"book_slug"`
+	Number    int       `json:"revision_number"`
+	Summary   string    `json:"summary"`
+	Type      string    `json:"type"`     // "version" for saved revisions
+	HTML      string    `json:"html"`     // Only populated by Get
+	Markdown  string    `json:"markdown"` // Only populated by Get
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy int       `json:"created_by"` // ID of the user who saved the revision
 }
 
 // Attachment represents a Bookstack attachment.
@@ -101,6 +246,14 @@ type AttachmentCreateRequest struct {
 	Link       string `json:"link,omitempty"`
 }
 
+// AttachmentUploadRequest contains fields for uploading a file attachment.
+type AttachmentUploadRequest struct {
+	Name       string
+	UploadedTo int
+	Filename   string // File name sent with the upload; its extension is kept by Bookstack
+	Data       []byte
+}
+
 // AttachmentUpdateRequest contains fields for updating an attachment.
 type AttachmentUpdateRequest struct {
 	Name string `json:"name,omitempty"`
@@ -131,14 +284,111 @@ type CommentUpdateRequest struct {
 	HTML string `json:"html"`
 }
 
+// Tag represents a name/value tag applied to a Bookstack item.
+type Tag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Order int    `json:"order"`
+}
+
+// SearchPreview holds the highlighted HTML fragments returned with a search result.
+// Matched terms are wrapped in <strong> elements; see PreviewText and PreviewANSI.
+type SearchPreview struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
 // SearchResult represents a search result from Bookstack.
 type SearchResult struct {
-	Type      string  `json:"type"` // "page", "chapter", "book", or "shelf"
-	ID        int     `json:"id"`
-	Name      string  `json:"name"`
-	Slug      string  `json:"slug"`
-	BookID    int     `json:"book_id"`    // For pages and chapters
-	ChapterID int     `json:"chapter_id"` // For pages
-	Preview   string  `json:"preview"`
-	Score     float64 `json:"score"`
+	Type        string        `json:"type"` // "page", "chapter", "book", or "bookshelf"
+	ID          int           `json:"id"`
+	Name        string        `json:"name"`
+	Slug        string        `json:"slug"`
+	BookID      int           `json:"book_id"`    // For pages and chapters
+	ChapterID   int           `json:"chapter_id"` // For pages
+	URL         string        `json:"url"`
+	Tags        []Tag         `json:"tags"`
+	Preview     string        `json:"preview"`
+	PreviewHTML SearchPreview `json:"preview_html"`
+	Score       float64       `json:"score"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// Image represents an image in the Bookstack image gallery.
+type Image struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Path       string    `json:"path"`
+	Type       string    `json:"type"` // "gallery" or "drawio"
+	UploadedTo int       `json:"uploaded_to"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	CreatedBy  int       `json:"created_by"`
+	UpdatedBy  int       `json:"updated_by"`
+}
+
+// ImageUploadRequest contains fields for uploading an image to the gallery.
+type ImageUploadRequest struct {
+	Type       string // "gallery" (default) or "drawio"
+	UploadedTo int    // ID of the page the image belongs to
+	Name       string // Defaults to Filename
+	Filename   string
+	Data       []byte
+}
+
+// ImageUpdateRequest contains fields for updating a gallery image.
+type ImageUpdateRequest struct {
+	Name string `json:"name"`
+}
+
+// ContentPermissions holds the item-level permissions of a book, chapter,
+// page or shelf.
+type ContentPermissions struct {
+	Owner               PermissionOwner     `json:"owner"`
+	RolePermissions     []RolePermission    `json:"role_permissions"`
+	FallbackPermissions FallbackPermissions `json:"fallback_permissions"`
+}
+
+// PermissionOwner identifies the user owning an item.
+type PermissionOwner struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// RolePermission grants a role actions on an item, overriding its
+// system-wide permissions.
+type RolePermission struct {
+	RoleID int             `json:"role_id"`
+	View   bool            `json:"view"`
+	Create bool            `json:"create"`
+	Update bool            `json:"update"`
+	Delete bool            `json:"delete"`
+	Role   *PermissionRole `json:"role,omitempty"` // Only populated by Get
+}
+
+// PermissionRole identifies a role in a RolePermission.
+type PermissionRole struct {
+	ID          int    `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+// FallbackPermissions apply to roles without a RolePermission. When
+// Inheriting is true, the parent's permissions apply instead.
+type FallbackPermissions struct {
+	Inheriting bool `json:"inheriting"`
+	View       bool `json:"view"`
+	Create     bool `json:"create"`
+	Update     bool `json:"update"`
+	Delete     bool `json:"delete"`
+}
+
+// ContentPermissionsUpdateRequest contains fields for updating item-level
+// permissions. Zero or nil fields are left unchanged.
+type ContentPermissionsUpdateRequest struct {
+	OwnerID             int                  `json:"owner_id,omitempty"`
+	RolePermissions     []RolePermission     `json:"role_permissions,omitempty"`
+	FallbackPermissions *FallbackPermissions `json:"fallback_permissions,omitempty"`
 }
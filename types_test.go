@@ -71,7 +71,13 @@ func TestSearchResult_JSONUnmarshal(t *testing.T) {
 		"slug": "found-page",
 		"book_id": 3,
 		"preview": "...match...",
-		"score": 1.5
+		"score": 1.5,
+		"url": "https://docs.example.com/books/ops/page/found-page",
+		"tags": [{"name": "Category", "value": "Ops", "order": 0}],
+		"preview_html": {
+			"name": "<strong>Found</strong> Page",
+			"content": "...<strong>match</strong>..."
+		}
 	}`
 
 	var sr SearchResult
@@ -84,4 +90,40 @@ func TestSearchResult_JSONUnmarshal(t *testing.T) {
 	if sr.Score != 1.5 {
 		t.Errorf("Score = %f, want 1.5", sr.Score)
 	}
+	if sr.URL != "https://docs.example.com/books/ops/page/found-page" {
+		t.Errorf("URL = %q", sr.URL)
+	}
+	if len(sr.Tags) != 1 || sr.Tags[0].Name != "Category" || sr.Tags[0].Value != "Ops" {
+		t.Errorf("Tags = %+v", sr.Tags)
+	}
+	if sr.PreviewHTML.Name != "<strong>Found</strong> Page" {
+		t.Errorf("PreviewHTML.Name = %q", sr.PreviewHTML.Name)
+	}
+}
+
+func TestUpdateRequest_MarshalJSON(t *testing.T) {
+	empty := ""
+	tests := []struct {
+		req  any
+		want string
+	}{
+		{BookUpdateRequest{Name: "Ops"}, `{"name":"Ops"}`},
+		{BookUpdateRequest{Description: &empty, Tags: []Tag{}}, `{"description":"","tags":[]}`},
+		{ChapterUpdateRequest{Tags: []Tag{{Name: "a"}}}, `{"tags":[{"name":"a","value":"","order":0}]}`},
+		{ChapterUpdateRequest{Description: &empty}, `{"description":""}`},
+		{PageUpdateRequest{Markdown: "x"}, `{"markdown":"x"}`},
+		{&PageUpdateRequest{Tags: []Tag{}}, `{"tags":[]}`},
+		{ShelfUpdateRequest{Name: "Team"}, `{"name":"Team"}`},
+		{ShelfUpdateRequest{Books: []int{}, Tags: []Tag{}}, `{"books":[],"tags":[]}`},
+		{&ShelfUpdateRequest{Books: []int{2, 1}}, `{"books":[2,1]}`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.req)
+		if err != nil {
+			t.Fatalf("Marshal(%+v): %v", tt.req, err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("Marshal(%+v) = %s, want %s", tt.req, data, tt.want)
+		}
+	}
 }
@@ -0,0 +1,205 @@
+package bookstack
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"code.beautifulmachines.dev/jakoubek/bookstack-api/markup"
+)
+
+// URLRef is a typed reference to a Bookstack item parsed from a web URL.
+type URLRef struct {
+	Type       string // EntityBook, EntityChapter, EntityPage or EntityShelf
+	ID         int    // Set for /link/{id} permalinks and draft URLs
+	BookSlug   string // Set for books, chapters and pages
+	Slug       string // Slug of the referenced item (empty for ID-only references)
+	RevisionID int    // Set for page revision URLs
+	Anchor     string // URL fragment, e.g. "bkmrk-installation"
+}
+
+// ParseURL parses a Bookstack web URL, or a path relative to the instance,
+// into a URLRef. Recognized forms are:
+//
+//	/shelves/{shelf}
+//	/books/{book}
+//	/books/{book}/chapter/{chapter}
+//	/books/{book}/page/{page}
+//	/books/{book}/page/{page}/revisions/{id}
+//	/books/{book}/draft/{id}
+//	/link/{id}
+//
+// Trailing action segments such as /edit are ignored. If the client's BaseURL
+// contains a path prefix, it is stripped first.
+func (c *Client) ParseURL(raw string) (*URLRef, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("parsing URL: %w", err)
+	}
+
+	p := u.EscapedPath()
+	if base, err := url.Parse(c.baseURL); err == nil && base.Path != "" {
+		if rest, ok := strings.CutPrefix(p, base.EscapedPath()); ok && (rest == "" || rest[0] == '/') {
+			p = rest
+		}
+	}
+
+	var segs []string
+	for _, s := range strings.Split(p, "/") {
+		if s == "" {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(s); err == nil {
+			s = unescaped
+		}
+		segs = append(segs, s)
+	}
+
+	ref := &URLRef{Anchor: u.Fragment}
+	if len(segs) < 2 {
+		return nil, fmt.Errorf("unrecognized Bookstack URL %q", raw)
+	}
+	switch segs[0] {
+	case "link":
+		id, err := strconv.Atoi(segs[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid link ID in %q", raw)
+		}
+		ref.Type, ref.ID = EntityPage, id
+		return ref, nil
+	case "shelves":
+		ref.Type, ref.Slug = EntityShelf, segs[1]
+		return ref, nil
+	case "books":
+		ref.BookSlug = segs[1]
+	default:
+		return nil, fmt.Errorf("unrecognized Bookstack URL %q", raw)
+	}
+
+	if len(segs) < 4 {
+		ref.Type, ref.Slug = EntityBook, ref.BookSlug
+		return ref, nil
+	}
+	switch segs[2] {
+	case "chapter":
+		ref.Type, ref.Slug = EntityChapter, segs[3]
+	case "page":
+		ref.Type, ref.Slug = EntityPage, segs[3]
+		if len(segs) >= 6 && segs[4] == "revisions" {
+			id, err := strconv.Atoi(segs[5])
+			if err != nil {
+				return nil, fmt.Errorf("invalid revision ID in %q", raw)
+			}
+			ref.RevisionID = id
+		}
+	case "draft":
+		id, err := strconv.Atoi(segs[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid draft ID in %q", raw)
+		}
+		ref.Type, ref.ID = EntityPage, id
+	default:
+		ref.Type, ref.Slug = EntityBook, ref.BookSlug
+	}
+	return ref, nil
+}
+
+// Resolve fetches the item referenced by ref.
+func (r *URLRef) Resolve(ctx context.Context, c *Client) (Entity, error) {
+	if r.ID > 0 {
+		return c.getEntity(ctx, r.Type, r.ID)
+	}
+	switch r.Type {
+	case EntityBook:
+		return entityOrNil(c.Books.GetBySlug(ctx, r.Slug))
+	case EntityChapter:
+		return entityOrNil(c.Chapters.GetBySlug(ctx, r.BookSlug, r.Slug))
+	case EntityPage:
+		return entityOrNil(c.Pages.GetBySlug(ctx, r.BookSlug, r.Slug))
+	case EntityShelf:
+		return entityOrNil(c.Shelves.GetBySlug(ctx, r.Slug))
+	default:
+		return nil, fmt.Errorf("unknown entity type %q", r.Type)
+	}
+}
+
+// ResolveURL parses a Bookstack web URL and fetches the item it references.
+func (c *Client) ResolveURL(ctx context.Context, raw string) (Entity, error) {
+	ref, err := c.ParseURL(raw)
+	if err != nil {
+		return nil, err
+	}
+	return ref.Resolve(ctx, c)
+}
+
+// PageLink returns the permanent /link/{id} URL for a page.
+// Unlike slug-based URLs it needs no lookups and survives renames.
+func (c *Client) PageLink(id int) string {
+	return fmt.Sprintf("%s/link/%d", c.baseURL, id)
+}
+
+// URL returns the canonical web URL for e. For chapters and pages the
+// parent book's slug is fetched when not already present on e.
+func (c *Client) URL(ctx context.Context, e Entity) (string, error) {
+	switch v := e.(type) {
+	case *Shelf:
+		return c.baseURL + "/shelves/" + url.PathEscape(v.Slug), nil
+	case *Book:
+		return c.bookURL(v.Slug), nil
+	case *Chapter:
+		bookSlug, err := c.bookSlug(ctx, v.BookSlug, v.BookID)
+		if err != nil {
+			return "", err
+		}
+		return c.bookURL(bookSlug) + "/chapter/" + url.PathEscape(v.Slug), nil
+	case *Page:
+		bookSlug, err := c.bookSlug(ctx, v.BookSlug, v.BookID)
+		if err != nil {
+			return "", err
+		}
+		if v.Draft {
+			return fmt.Sprintf("%s/draft/%d", c.bookURL(bookSlug), v.ID), nil
+		}
+		return c.bookURL(bookSlug) + "/page/" + url.PathEscape(v.Slug), nil
+	default:
+		return "", fmt.Errorf("unsupported entity %T", e)
+	}
+}
+
+// RevisionURL returns the web URL of a specific revision of page.
+func (c *Client) RevisionURL(ctx context.Context, page *Page, revisionID int) (string, error) {
+	u, err := c.URL(ctx, &Page{ID: page.ID, BookID: page.BookID, BookSlug: page.BookSlug, Slug: page.Slug})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/revisions/%d", u, revisionID), nil
+}
+
+// SectionURL returns the web URL of a section of page, linking to the
+// element with the given anchor such as "bkmrk-setup" (see Page.Outline).
+func (c *Client) SectionURL(ctx context.Context, page *Page, anchor string) (string, error) {
+	u, err := c.URL(ctx, page)
+	if err != nil {
+		return "", err
+	}
+	return (&markup.Section{Anchor: anchor}).Link(u), nil
+}
+
+func (c *Client) bookURL(slug string) string {
+	return c.baseURL + "/books/" + url.PathEscape(slug)
+}
+
+// bookSlug returns slug if set, otherwise fetches the book with the given ID.
+func (c *Client) bookSlug(ctx context.Context, slug string, bookID int) (string, error) {
+	if slug != "" {
+		return slug, nil
+	}
+	book, err := c.Books.Get(ctx, bookID)
+	if err != nil {
+		return "", err
+	}
+	c.slugs.set("book:"+book.Slug, book.ID)
+	return book.Slug, nil
+}
@@ -0,0 +1,116 @@
+package bookstack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_ParseURL(t *testing.T) {
+	c, err := NewClient(Config{BaseURL: "https://docs.example.com/wiki", TokenID: "a", TokenSecret: "b"})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	tests := []struct {
+		in   string
+		want URLRef
+	}{
+		{"https://docs.example.com/wiki/books/ops", URLRef{Type: EntityBook, BookSlug: "ops", Slug: "ops"}},
+		{"/books/ops/page/deploy", URLRef{Type: EntityPage, BookSlug: "ops", Slug: "deploy"}},
+		{"/books/ops/page/deploy#bkmrk-setup", URLRef{Type: EntityPage, BookSlug: "ops", Slug: "deploy", Anchor: "bkmrk-setup"}},
+		{"/books/ops/page/deploy/edit", URLRef{Type: EntityPage, BookSlug: "ops", Slug: "deploy"}},
+		{"/books/ops/page/deploy/revisions/17", URLRef{Type: EntityPage, BookSlug: "ops", Slug: "deploy", RevisionID: 17}},
+		{"/books/ops/chapter/runbooks", URLRef{Type: EntityChapter, BookSlug: "ops", Slug: "runbooks"}},
+		{"/books/ops/draft/99", URLRef{Type: EntityPage, BookSlug: "ops", ID: 99}},
+		{"/shelves/engineering", URLRef{Type: EntityShelf, Slug: "engineering"}},
+		{"https://docs.example.com/wiki/link/123", URLRef{Type: EntityPage, ID: 123}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := c.ParseURL(tt.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("ParseURL(%q) = %+v, want %+v", tt.in, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ParseURL_Invalid(t *testing.T) {
+	c, _ := NewClient(Config{BaseURL: "https://docs.example.com", TokenID: "a", TokenSecret: "b"})
+	for _, in := range []string{"/", "/settings/users", "/link/abc", "/books/ops/page/x/revisions/y"} {
+		if _, err := c.ParseURL(in); err == nil {
+			t.Errorf("ParseURL(%q): expected error", in)
+		}
+	}
+}
+
+func TestClient_ResolveURL(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/pages/123" {
+			t.Errorf("path = %s, want /api/pages/123", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 123, "name": "Deploy"})
+	})
+
+	e, err := c.ResolveURL(context.Background(), c.PageLink(123))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p, ok := e.(*Page); !ok || p.ID != 123 {
+		t.Errorf("got %#v, want page 123", e)
+	}
+}
+
+func TestClient_URL(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/books/3" {
+			t.Errorf("path = %s, want /api/books/3", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 3, "slug": "ops"})
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		e    Entity
+		want string
+	}{
+		{&Shelf{Slug: "eng"}, "/shelves/eng"},
+		{&Book{Slug: "ops"}, "/books/ops"},
+		{&Chapter{BookSlug: "ops", Slug: "runbooks"}, "/books/ops/chapter/runbooks"},
+		{&Page{BookID: 3, Slug: "deploy"}, "/books/ops/page/deploy"},
+		{&Page{ID: 9, BookSlug: "ops", Draft: true}, "/books/ops/draft/9"},
+	}
+	for _, tt := range tests {
+		got, err := c.URL(ctx, tt.e)
+		if err != nil {
+			t.Fatalf("URL(%#v): %v", tt.e, err)
+		}
+		if got != c.baseURL+tt.want {
+			t.Errorf("URL(%#v) = %q, want %q", tt.e, got, c.baseURL+tt.want)
+		}
+	}
+
+	rev, err := c.RevisionURL(ctx, &Page{BookSlug: "ops", Slug: "deploy"}, 5)
+	if err != nil {
+		t.Fatalf("RevisionURL: %v", err)
+	}
+	if rev != c.baseURL+"/books/ops/page/deploy/revisions/5" {
+		t.Errorf("RevisionURL = %q", rev)
+	}
+
+	sec, err := c.SectionURL(ctx, &Page{BookSlug: "ops", Slug: "deploy"}, "bkmrk-setup")
+	if err != nil {
+		t.Fatalf("SectionURL: %v", err)
+	}
+	if sec != c.baseURL+"/books/ops/page/deploy#bkmrk-setup" {
+		t.Errorf("SectionURL = %q", sec)
+	}
+	if ref, err := c.ParseURL(sec); err != nil || ref.Anchor != "bkmrk-setup" {
+		t.Errorf("ParseURL(SectionURL) = %+v, %v", ref, err)
+	}
+}